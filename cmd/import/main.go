@@ -0,0 +1,194 @@
+package main
+
+import (
+	"Anastasia/songs/internal/importer"
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/repository"
+	"Anastasia/songs/internal/reqctx"
+	"Anastasia/songs/internal/services"
+	"context"
+	"flag"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
+)
+
+// Импорт каталога песен из CSV/TSV или из тегов аудиофайлов:
+//
+//	go run ./cmd/import -file catalog.csv -mapping mapping.json -dry-run
+//	go run ./cmd/import -dir /music/archive -v
+//	go run ./cmd/import -playlist favourites.m3u8
+//
+// Файл соответствия — JSON вида {"group": "Artist", "song": "Title", "releaseDate": "Released"};
+// без него столбцы должны называться так же, как поля песни. Из аудиофайлов берутся исполнитель,
+// название, дата и текст (ID3v2 USLT или Vorbis comment LYRICS). Записи плейлистов сопоставляются
+// с песнями библиотеки, для несопоставленных создаются песни-заготовки.
+// Песни записываются тем же путём, что и POST /songs:batch, данные из внешних источников
+// запрашивает фоновый обработчик API
+func main() {
+	file := flag.String("file", "", "CSV or TSV file to import")
+	playlist := flag.String("playlist", "", "M3U/M3U8, XSPF or iTunes Library XML file to import (instead of -file)")
+	dir := flag.String("dir", "", "Directory with MP3/FLAC/OGG files to import by their tags (instead of -file)")
+	mappingFile := flag.String("mapping", "", "JSON file mapping song fields to column names")
+	tsv := flag.Bool("tsv", false, "Tab-separated input (default: by file extension)")
+	dryRun := flag.Bool("dry-run", false, "Only validate rows and report problems, do not write anything")
+	atomic := flag.Bool("atomic", false, "Import all rows in one transaction or none of them")
+	enrich := flag.Bool("enrich", true, "Request details from the external services after import")
+	user := flag.String("user", "import", "Author recorded in the audit log and revisions")
+	verbose := flag.Bool("v", false, "Report every record, not only problems")
+	flag.Parse()
+
+	if countSet(*file, *dir, *playlist) != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		logrus.WithError(err).Debug("No .env file loaded")
+	}
+	logrus.SetFormatter(&logrus.JSONFormatter{})
+	logrus.SetLevel(logrus.WarnLevel)
+
+	opts := services.CreateOptions{SkipEnrichment: !*enrich}
+	// Импорт выполняет оператор с доступом к БД, поэтому ему разрешены все операции
+	ctx := reqctx.WithRole(reqctx.WithUser(context.Background(), *user), reqctx.RoleAdmin)
+
+	if *playlist != "" {
+		importPlaylist(ctx, *playlist, opts, *dryRun, *verbose)
+		return
+	}
+
+	var rows []importer.Row
+	var skipped []importer.Entry
+	var err error
+	if *dir != "" {
+		rows, skipped, err = importer.ScanAudio(*dir)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to scan audio files")
+		}
+	} else {
+		rows, err = readTable(*file, *mappingFile, *tsv)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to read import file")
+		}
+	}
+
+	if *dryRun {
+		entries := importer.Validate(rows, services.ValidateSong)
+		importer.MarkDuplicates(rows, entries)
+		entries = append(entries, skipped...)
+		if importer.WriteReport(os.Stdout, entries, *verbose) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	srv, closeDB := newSongService()
+	defer closeDB()
+
+	songs := make([]models.Songs, len(rows))
+	for i, row := range rows {
+		songs[i] = row.Song
+	}
+
+	result, err := srv.CreateSongs(ctx, songs, services.BatchOptions{
+		CreateOptions: opts,
+		Atomic:        *atomic,
+	})
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to import songs")
+	}
+
+	entries := append(importer.Outcomes(rows, result), skipped...)
+	if importer.WriteReport(os.Stdout, entries, *verbose) > 0 {
+		os.Exit(1)
+	}
+}
+
+// Импорт плейлиста: записи сопоставляются с библиотекой, для новых создаются песни-заготовки
+func importPlaylist(ctx context.Context, file string, opts services.CreateOptions, dryRun, verbose bool) {
+	format := importer.PlaylistFormat(file)
+	if format == "" {
+		logrus.WithField("file", file).Fatal("Unknown playlist format")
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to open playlist")
+	}
+	defer f.Close()
+
+	entries, err := importer.ReadPlaylist(f, format)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to read playlist")
+	}
+
+	var report []importer.Entry
+	if dryRun {
+		report = importer.Validate(importer.PlaylistRows(entries), services.ValidateSong)
+		for i := range report {
+			if report[i].Status == models.BatchInvalid {
+				report[i].Status = models.PlaylistUnresolved
+			}
+		}
+	} else {
+		srv, closeDB := newSongService()
+		defer closeDB()
+
+		result, err := srv.ImportPlaylist(ctx, entries, opts)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to import playlist")
+		}
+		report = importer.PlaylistOutcomes(result)
+	}
+
+	if importer.WriteReport(os.Stdout, report, verbose) > 0 {
+		os.Exit(1)
+	}
+}
+
+// Сервис песен поверх БД из переменных окружения DB_*
+func newSongService() (*services.SongService, func()) {
+	db, err := repository.NewStorage(repository.ConfigFromEnv())
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to connect to DB")
+	}
+	srv := services.NewSongService(repository.NewRepo(db), services.NewChainEnricher(services.MergePolicyFromEnv()))
+	return srv, db.Close
+}
+
+func countSet(values ...string) int {
+	n := 0
+	for _, v := range values {
+		if v != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// Чтение записей из CSV/TSV с соответствием столбцов из файла mappingFile
+func readTable(file, mappingFile string, tsv bool) ([]importer.Row, error) {
+	mapping := importer.DefaultMapping()
+	if mappingFile != "" {
+		var err error
+		mapping, err = importer.LoadMapping(mappingFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	comma := importer.Delimiter(file)
+	if tsv {
+		comma = '\t'
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return importer.ReadRows(f, comma, mapping)
+}
@@ -2,11 +2,15 @@ package main
 
 import (
 	"Anastasia/songs/internal/api"
+	"Anastasia/songs/internal/config"
+	"Anastasia/songs/internal/enrichment"
 	"Anastasia/songs/internal/repository"
 	"Anastasia/songs/internal/services"
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	_ "Anastasia/songs/docs"
 
@@ -22,14 +26,7 @@ import (
 
 func main() {
 
-	db, err := repository.NewStorage(repository.Config{
-		Host:     os.Getenv("DB_HOST"),
-		Port:     os.Getenv("DB_PORT"),
-		User:     os.Getenv("DB_USER"),
-		Password: os.Getenv("DB_PASSWORD"),
-		DBName:   os.Getenv("DB_NAME"),
-		SSLMode:  os.Getenv("DB_SSLMODE"),
-	})
+	db, err := repository.NewStorage(repository.ConfigFromEnv())
 	if err != nil {
 		logrus.WithError(err).Fatalf("Failed to connect to DB")
 	}
@@ -37,7 +34,31 @@ func main() {
 	defer db.Close()
 
 	repo := repository.NewRepo(db)
-	srv := services.NewService(repo)
+	cacheTTL := config.Duration("ENRICHMENT_CACHE_TTL", 24*time.Hour)
+
+	var providers []services.Provider
+	for _, cfg := range enrichment.ProvidersFromEnv() {
+		provider := services.NewInfoProvider(cfg.Name, enrichment.New(cfg))
+		providers = append(providers, services.NewCachedProvider(provider, repo, cacheTTL))
+	}
+	enricher := services.NewChainEnricher(services.MergePolicyFromEnv(), providers...)
+
+	srv := services.NewService(repo, enricher)
+
+	worker := services.NewEnrichmentWorker(repo, enricher, services.WorkerConfigFromEnv())
+	go worker.Run(context.Background())
+
+	// Сигнатуры текстов песен, добавленных до появления поиска похожих текстов
+	go func() {
+		n, err := srv.Similarity.BackfillSignatures(context.Background())
+		if err != nil {
+			logrus.WithError(err).Error("Failed to compute lyrics signatures")
+			return
+		}
+		if n > 0 {
+			logrus.WithField("count", n).Info("Computed missing lyrics signatures")
+		}
+	}()
 
 	api := api.New(srv)
 
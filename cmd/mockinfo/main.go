@@ -0,0 +1,53 @@
+package main
+
+import (
+	"Anastasia/songs/internal/mockinfo"
+	"flag"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Заглушка внешнего сервиса с информацией о песнях для локальной разработки:
+//
+//	go run ./cmd/mockinfo -addr :8081 -latency 200ms -error-rate 0.1
+func main() {
+	addr := flag.String("addr", ":8081", "Address to listen on")
+	fixturesDir := flag.String("fixtures", "", "Directory with *.json fixtures (built-in fixtures by default)")
+	latency := flag.Duration("latency", 0, "Delay before every response")
+	jitter := flag.Duration("jitter", 0, "Random extra delay up to this value")
+	errorRate := flag.Float64("error-rate", 0, "Share of requests (0..1) answered with -error-status")
+	errorStatus := flag.Int("error-status", http.StatusInternalServerError, "Status code of injected errors")
+	failFirst := flag.Int("fail-first", 0, "Number of first requests answered with -error-status")
+	flag.Parse()
+
+	logrus.SetFormatter(&logrus.JSONFormatter{})
+
+	opts := mockinfo.Options{
+		Latency:     *latency,
+		Jitter:      *jitter,
+		ErrorRate:   *errorRate,
+		ErrorStatus: *errorStatus,
+		FailFirst:   *failFirst,
+	}
+	if *fixturesDir != "" {
+		fixtures, err := mockinfo.LoadFixtures(os.DirFS(*fixturesDir), ".")
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to load fixtures")
+		}
+		opts.Fixtures = fixtures
+	}
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           mockinfo.New(opts),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	logrus.WithField("addr", *addr).Info("Mock info service is running...")
+	if err := srv.ListenAndServe(); err != nil {
+		logrus.WithError(err).Fatal("Failed starting the mock info service")
+	}
+}
@@ -15,9 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/songs": {
+        "/audit": {
             "get": {
-                "description": "Get a list of all songs with optional filters",
+                "description": "Get a list of mutating operations with optional filters. Requires the admin role",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,38 +25,57 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "songs"
+                    "audit"
                 ],
-                "summary": "Get all songs",
+                "summary": "Get audit log",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Group filter",
-                        "name": "group",
+                        "description": "Caller role",
+                        "name": "X-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Actor filter",
+                        "name": "actor",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Song filter",
-                        "name": "song",
+                        "description": "Action filter (create, update, delete, revert)",
+                        "name": "action",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Release date filter",
-                        "name": "releaseDate",
+                        "description": "Entity filter",
+                        "name": "entity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entity ID filter",
+                        "name": "entityId",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Text filter",
-                        "name": "text",
+                        "description": "Request ID filter",
+                        "name": "requestId",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Link filter",
-                        "name": "link",
+                        "description": "Start of the period (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the period (RFC 3339)",
+                        "name": "to",
                         "in": "query"
                     },
                     {
@@ -78,10 +97,22 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Songs"
+                                "$ref": "#/definitions/models.AuditEntry"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -89,9 +120,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/enrichment/runs": {
             "post": {
-                "description": "Create a new song",
+                "description": "Request fresh details from the external services for every song matching the filters.\nFields supplied by users are kept, fields supplied by providers are refreshed. Songs that are already\nqueued are skipped. Requires the admin role",
                 "consumes": [
                     "application/json"
                 ],
@@ -99,31 +132,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "songs"
+                    "enrichment"
                 ],
-                "summary": "Create a new song",
+                "summary": "Start re-enrichment",
                 "parameters": [
                     {
-                        "description": "Song object",
-                        "name": "song",
+                        "type": "string",
+                        "description": "Caller role",
+                        "name": "X-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Song filters",
+                        "name": "run",
                         "in": "body",
                         "required": true,
                         "schema": {
-                                        "type": "object",
-                            "properties": {
-                            "group": {
-                                "type": "string"
-                            },
-                            "song": {
-                                "type": "string"
-                            }
-                        }
+                            "$ref": "#/definitions/models.EnrichmentRunRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.EnrichmentRun"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -131,6 +166,12 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -140,9 +181,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/songs/{id}": {
+        "/enrichment/runs/{id}": {
             "get": {
-                "description": "Get a lyrics of the song by its ID with optional verse number",
+                "description": "Get the progress of a re-enrichment run and the outcome for each song. Requires the admin role",
                 "consumes": [
                     "application/json"
                 ],
@@ -150,21 +191,81 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "songs"
+                    "enrichment"
                 ],
-                "summary": "Get a song by ID",
+                "summary": "Get re-enrichment results",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Caller role",
+                        "name": "X-Role",
+                        "in": "header",
+                        "required": true
+                    },
                     {
                         "type": "integer",
-                        "description": "Song ID",
+                        "description": "Run ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.EnrichmentRun"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{group}/lyrics/stats": {
+            "get": {
+                "description": "Get the lyrics metrics of all songs of the group combined. Repetition is counted within each song",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Get lyrics statistics of a group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group name",
+                        "name": "group",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "Verse number",
-                        "name": "verse",
+                        "description": "Number of most frequent words (default 20, max 100)",
+                        "name": "top",
                         "in": "query"
                     }
                 ],
@@ -172,7 +273,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.GroupLyricsStats"
                         }
                     },
                     "400": {
@@ -181,6 +282,12 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -188,9 +295,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a song by its ID",
+            }
+        },
+        "/songs": {
+            "get": {
+                "description": "Get a list of all songs with optional filters",
                 "consumes": [
                     "application/json"
                 ],
@@ -200,24 +309,65 @@ const docTemplate = `{
                 "tags": [
                     "songs"
                 ],
-                "summary": "Delete a song by ID",
+                "summary": "Get all songs",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group filter",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Song filter",
+                        "name": "song",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Release date filter",
+                        "name": "releaseDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Text filter",
+                        "name": "text",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Link filter",
+                        "name": "link",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Enrichment status filter (pending, enriched, failed, manual)",
+                        "name": "enrichmentStatus",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Song ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "pageSize",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Songs"
+                            }
                         }
                     },
                     "500": {
@@ -228,8 +378,8 @@ const docTemplate = `{
                     }
                 }
             },
-            "patch": {
-                "description": "Update a song by its ID",
+            "post": {
+                "description": "Create a new song. Release date, lyrics and link are requested from the external service in the background,\nuntil then the song has enrichmentStatus \"pending\". With enrich=false (or the X-Enrichment: skip header)\nthe caller-supplied data is stored as is and the song gets enrichmentStatus \"manual\";\nthis requires the editor or admin role. If the lyrics are given and nearly match the lyrics of existing\nsongs, those songs are listed in possibleDuplicates. A song with the same normalized name and group\n(or, with DUPLICATE_SONG_SIMILARITY set, a similar name in the same group) is not added: the response is\n409 with the id of the existing song, unless allowDuplicate=true",
                 "consumes": [
                     "application/json"
                 ],
@@ -239,15 +389,8 @@ const docTemplate = `{
                 "tags": [
                     "songs"
                 ],
-                "summary": "Update a song by ID",
+                "summary": "Create a new song",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Song ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "description": "Song object",
                         "name": "song",
@@ -256,11 +399,32 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.Songs"
                         }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Request details from the external services (default true)",
+                        "name": "enrich",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Add the song even if it already exists",
+                        "name": "allowDuplicate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "skip to disable enrichment",
+                        "name": "X-Enrichment",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatedSong"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -268,6 +432,18 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.DuplicateSong"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -276,28 +452,2539 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.Songs": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "integer"
-                },
-                "group": {
-                    "type": "string"
-                },
-                "song": {
-                    "type": "string"
-                },
-                "releaseDate": {
-                    "type": "string"
-                },
-                "text": {
-                    "type": "string"
-                },
-                "link": {
+        },
+        "/songs/export": {
+            "get": {
+                "description": "Stream all songs matching the listing filters as a downloadable file.\nRows are read from a database cursor, so the export is not limited by page size",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Export songs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Export format: csv (default), ndjson or json",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Group filter",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Song filter",
+                        "name": "song",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Release date filter",
+                        "name": "releaseDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Text filter",
+                        "name": "text",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Link filter",
+                        "name": "link",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Enrichment status filter (pending, enriched, failed, manual)",
+                        "name": "enrichmentStatus",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/import": {
+            "post": {
+                "description": "Seed the library from a playlist or library export: M3U/M3U8 with EXTINF, XSPF or\niTunes/Music Library XML. Every entry is matched to an existing song by group and name;\nunmatched entries get a stub song whose details are requested from the external services.\nEntries without group or name are reported as unresolved",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Import a playlist",
+                "parameters": [
+                    {
+                        "description": "Playlist file",
+                        "name": "playlist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "m3u, xspf or itunes (default: by Content-Type)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Request details from the external services (default true)",
+                        "name": "enrich",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "skip to disable enrichment",
+                        "name": "X-Enrichment",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PlaylistImport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/preview": {
+            "post": {
+                "description": "Validate the song and request its details from the external services exactly as creation does,\nwithout saving anything. Returns the would-be song, the source of each enriched field and warnings",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Preview a new song",
+                "parameters": [
+                    {
+                        "description": "Song object",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Songs"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Request details from the external services (default true)",
+                        "name": "enrich",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "skip to disable enrichment",
+                        "name": "X-Enrichment",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Enrichment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}": {
+            "get": {
+                "description": "Get a lyrics of the song by its ID with optional verse number.\nThe format is chosen by the Accept header: a JSON string (default), plain text,\nan HTML fragment with verses as paragraphs or Markdown",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain",
+                    "text/html",
+                    "text/markdown"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Get a song by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Verse number",
+                        "name": "verse",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a song by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Delete a song by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update a song by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Update a song by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Song object",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Songs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Songs"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/chords": {
+            "get": {
+                "description": "Get the lyrics with chords as JSON with chord positions (in characters of the line),\nas plain text with chords over the lyrics or as ChordPro, chosen by the Accept header\n(application/json, text/plain, application/x-chordpro) or the format parameter.\ntranspose shifts the key by semitones, capo recalculates chord shapes for a capo on that fret\n(the sounding key does not change). Slash chords are transposed including the bass note;\nsharps or flats follow the key unless accidentals is given",
+                "produces": [
+                    "application/json",
+                    "text/plain",
+                    "application/x-chordpro"
+                ],
+                "tags": [
+                    "chords"
+                ],
+                "summary": "Get lyrics with chords",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Semitones to transpose by, e.g. +2 or -3",
+                        "name": "transpose",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Capo fret for the chord shapes",
+                        "name": "capo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sharp or flat",
+                        "name": "accidentals",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json, text or chordpro (overrides Accept, other values give 400)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChordSheet"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Store the lyrics with chords in ChordPro format: chords in square brackets inside the lines,\ndirectives like {title}, {key}, {capo}, {start_of_chorus}/{end_of_chorus} and {comment}.\nProblems are reported by line number. The plain song text is replaced with the lyrics\nwithout chords. Stored synced lyrics are kept while their lyrics match the new text;\nediting the plain text later drops the chords (recorded in the audit log)",
+                "consumes": [
+                    "application/x-chordpro"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chords"
+                ],
+                "summary": "Upload lyrics with chords",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Lyrics with chords in ChordPro format",
+                        "name": "chordpro",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChordSheet"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete the ChordPro lyrics of the song; the plain text is kept",
+                "tags": [
+                    "chords"
+                ],
+                "summary": "Delete lyrics with chords",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/lyrics": {
+            "get": {
+                "description": "Get the song lyrics split into sections (verse, chorus, pre-chorus, bridge, intro, outro) and lines.\nSection types come from markers like [Chorus] or Куплет 2: in the text; unmarked blocks repeating\na marked section get its type, unmarked blocks repeated several times are choruses.\nWith lines, section or q only an excerpt with numbered lines is returned (models.LyricsExcerpt);\nlines are numbered from 1 across all sections.\nBesides JSON the lyrics are rendered as plain text, an HTML fragment or Markdown\nwith section headings, chosen by the Accept header",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/plain",
+                    "text/html",
+                    "text/markdown"
+                ],
+                "tags": [
+                    "lyrics"
+                ],
+                "summary": "Get structured lyrics",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Line range, e.g. 5-12, 5- or 7",
+                        "name": "lines",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Section type with optional number, e.g. chorus or verse:2",
+                        "name": "section",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Text to search for (case and punctuation insensitive)",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Which match to show when searching (default 1)",
+                        "name": "hit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Lines before and after the match (default 2)",
+                        "name": "window",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Lyrics"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/lyrics/analysis": {
+            "get": {
+                "description": "Get the rhyme scheme of each section (e.g. ABAB) with its name when all quatrains share it\n(couplet, alternate, enclosed, monorhyme, ballad) and syllable counts per line.\nRussian and English lines are analyzed with rule-based phonetics, without a stress dictionary",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lyrics"
+                ],
+                "summary": "Analyze lyrics rhymes and syllables",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LyricsAnalysis"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/lyrics/bilingual": {
+            "get": {
+                "description": "Get the lyrics with the translation interleaved line by line. The language is taken from\nthe lang parameter (comma-separated list in order of preference) or the Accept-Language header;\nen-US matches an en translation and vice versa. Besides JSON the lyrics are rendered as plain text,\nan HTML fragment or Markdown with the translation under each line, chosen by the Accept header",
+                "produces": [
+                    "application/json",
+                    "text/plain",
+                    "text/html",
+                    "text/markdown"
+                ],
+                "tags": [
+                    "translations"
+                ],
+                "summary": "Get bilingual lyrics",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Translation language(s), e.g. en or en,de",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Preferred languages",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BilingualLyrics"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/lyrics/stats": {
+            "get": {
+                "description": "Get word count, unique words, lexical diversity (unique/total words), repetition ratio\n(share of lines repeating an earlier line), average line length and the most frequent words\nexcluding Russian and English stopwords. Results are cached and recomputed when the lyrics change",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Get lyrics statistics of a song",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of most frequent words (default 20, max 100)",
+                        "name": "top",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SongLyricsStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/lyrics/synced": {
+            "get": {
+                "description": "Get time-synced lyrics as LRC, SubRip or WebVTT, chosen by the Accept header\n(text/x-lrc, application/x-subrip, text/vtt, application/json) or the format parameter.\nWord-level timestamps of enhanced LRC are kept in LRC and WebVTT",
+                "produces": [
+                    "text/x-lrc",
+                    "application/x-subrip",
+                    "text/vtt",
+                    "application/json"
+                ],
+                "tags": [
+                    "lyrics"
+                ],
+                "summary": "Get synced lyrics",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "lrc, srt, vtt or json (overrides Accept, other values give 400)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SyncedLyrics"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Store time-synced lyrics in LRC format (enhanced word timestamps are supported).\nThe LRC is validated, problems are reported by line number. The plain song text is replaced\nwith the text derived from the LRC. Stored chords are kept while their lyrics match the new text;\nediting the plain text later drops the synced lyrics (recorded in the audit log)",
+                "consumes": [
+                    "text/x-lrc"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lyrics"
+                ],
+                "summary": "Upload synced lyrics",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Lyrics in LRC format",
+                        "name": "lrc",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SyncedLyrics"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete the time-synced lyrics of the song; the plain text is kept",
+                "tags": [
+                    "lyrics"
+                ],
+                "summary": "Delete synced lyrics",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/merge": {
+            "post": {
+                "description": "Merge the duplicate song into this (canonical) song. Empty release date, lyrics and link of the song are\nfilled from the duplicate, translations to languages the song lacks are moved, synced lyrics and chords\nare moved when the lyrics match. Revisions of the duplicate are appended to the song history (mergedFrom\nand mergedRevision keep their origin) and filled fields keep their source. The duplicate is deleted; GET\nrequests to its id are redirected to the song. Admins only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Merge a duplicate song",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Canonical song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Duplicate song",
+                        "name": "merge",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.mergeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SongMerge"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/provenance": {
+            "get": {
+                "description": "Get the source of the current value of each song field: \"user\", \"revert\" or the name of the enrichment provider",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Get song provenance",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.FieldSource"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revisions": {
+            "get": {
+                "description": "Get the change history of the song: who changed which fields and when",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "Get song revisions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Revision"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revisions/diff": {
+            "get": {
+                "description": "Get a line-level diff of the song lyrics between two revisions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "Diff lyrics between revisions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Base revision",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Target revision",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LyricsDiff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revisions/{rev}/revert": {
+            "post": {
+                "description": "Restore the song to the state saved in the revision. The revert is recorded as a new revision",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "Revert a song to a revision",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision number",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Revision"
+                        }
+                    },
+                    "204": {
+                        "description": "Song already matches the revision"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/similar-lyrics": {
+            "get": {
+                "description": "Find near-duplicate uploads and covers with slightly different lyrics. Similarity is the Jaccard\nsimilarity of three-word shingles of the normalized lyrics, estimated with MinHash signatures\ncomputed on write. Candidates are found with LSH, so pairs below 0.5 may be missed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Find songs with similar lyrics",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum similarity from 0 to 1 (default 0.5)",
+                        "name": "min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of songs (default 10, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SimilarSong"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/translations": {
+            "get": {
+                "description": "Get the languages the song lyrics are translated to. Texts are omitted;\noutdated is true when the original lyrics changed after the translation was saved",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "translations"
+                ],
+                "summary": "List lyrics translations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Translation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/translations/{lang}": {
+            "get": {
+                "description": "Get the song lyrics translated to the language",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "translations"
+                ],
+                "summary": "Get a lyrics translation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language code, e.g. en or en-GB",
+                        "name": "lang",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Translation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Store the translation of the song lyrics. The translation must follow the structure of the original:\nthe same sections (blocks separated by blank lines, section markers may be translated) with the same\nnumber of lines in each, so that lines can be shown side by side. The text is limited\nto 20000 characters like the song lyrics",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "translations"
+                ],
+                "summary": "Add or update a lyrics translation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language code, e.g. en or en-GB",
+                        "name": "lang",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Translated lyrics",
+                        "name": "translation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.translationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Translation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete the translation of the song lyrics to the language",
+                "tags": [
+                    "translations"
+                ],
+                "summary": "Delete a lyrics translation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language code",
+                        "name": "lang",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs:batch": {
+            "post": {
+                "description": "Create many songs in one request. The body is a JSON array of songs or an NDJSON stream\n(Content-Type: application/x-ndjson, one song per line). Every item is validated and checked\nfor duplicates like a single creation; items are saved in chunks, each in its own transaction.\nWith atomic=true either all items are saved in one transaction or none of them (422 is returned).\nThe number of items (BATCH_MAX_ITEMS) and the body size (BATCH_MAX_BYTES) are limited, 413 is returned otherwise",
+                "consumes": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Create songs in bulk",
+                "parameters": [
+                    {
+                        "description": "Songs",
+                        "name": "songs",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Songs"
+                            }
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "All-or-nothing mode",
+                        "name": "atomic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Request details from the external services (default true)",
+                        "name": "enrich",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "skip to disable enrichment",
+                        "name": "X-Enrichment",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.mergeRequest": {
+            "type": "object",
+            "properties": {
+                "duplicateId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.translationRequest": {
+            "type": "object",
+            "properties": {
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor": {
+                    "type": "string"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "type": "object"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "entity": {
+                    "type": "string"
+                },
+                "entityId": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "requestId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BatchItemResult": {
+            "type": "object",
+            "properties": {
+                "duplicateOf": {
+                    "description": "Индекс предыдущего элемента пакета с той же песней",
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "existingId": {
+                    "type": "integer"
+                },
+                "fields": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "similarity": {
+                    "description": "Сходство названия с уже существующей песней (1 — совпадение нормализованных названий)",
+                    "type": "number"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BatchResult": {
+            "type": "object",
+            "properties": {
+                "atomic": {
+                    "type": "boolean"
+                },
+                "committed": {
+                    "type": "boolean"
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "duplicates": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "invalid": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BatchItemResult"
+                    }
+                }
+            }
+        },
+        "models.BilingualLine": {
+            "type": "object",
+            "properties": {
+                "number": {
+                    "type": "integer"
+                },
+                "original": {
+                    "type": "string"
+                },
+                "translation": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BilingualLyrics": {
+            "type": "object",
+            "properties": {
+                "language": {
+                    "type": "string"
+                },
+                "outdated": {
+                    "type": "boolean"
+                },
+                "sections": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BilingualSection"
+                    }
+                }
+            }
+        },
+        "models.BilingualSection": {
+            "type": "object",
+            "properties": {
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BilingualLine"
+                    }
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ChordLine": {
+            "type": "object",
+            "properties": {
+                "chords": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ChordPosition"
+                    }
+                },
+                "comment": {
+                    "description": "Комментарий ({comment: ...}) вместо строки текста",
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ChordPosition": {
+            "type": "object",
+            "properties": {
+                "chord": {
+                    "type": "string"
+                },
+                "position": {
+                    "description": "Позиция в строке в символах (не байтах), начиная с 0",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ChordSection": {
+            "type": "object",
+            "properties": {
+                "label": {
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ChordLine"
+                    }
+                },
+                "repeat": {
+                    "description": "Ссылка на припев ({chorus}): припев повторяется без аккордов",
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ChordSheet": {
+            "type": "object",
+            "properties": {
+                "capo": {
+                    "description": "Лад каподастра; аккорды записаны аппликатурами относительно него",
+                    "type": "integer"
+                },
+                "key": {
+                    "description": "Тональность звучания после транспонирования",
+                    "type": "string"
+                },
+                "metadata": {
+                    "description": "Директивы метаданных: title, artist, album, tempo и т.д.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "sections": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ChordSection"
+                    }
+                }
+            }
+        },
+        "models.CreatedSong": {
+            "type": "object",
+            "properties": {
+                "enrichmentStatus": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "link": {
+                    "type": "string"
+                },
+                "possibleDuplicates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SimilarSong"
+                    }
+                },
+                "releaseDate": {
+                    "type": "string"
+                },
+                "song": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DiffLine": {
+            "type": "object",
+            "properties": {
+                "newLine": {
+                    "type": "integer"
+                },
+                "oldLine": {
+                    "type": "integer"
+                },
+                "op": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DuplicateSong": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "existingId": {
+                    "type": "integer"
+                },
+                "similarity": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.Enrichment": {
+            "type": "object",
+            "properties": {
+                "song": {
+                    "$ref": "#/definitions/models.Songs"
+                },
+                "sources": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.EnrichmentRun": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "type": "string"
+                },
+                "done": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "filters": {
+                    "$ref": "#/definitions/models.Songs"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.EnrichmentRunItem"
+                    }
+                },
+                "pending": {
+                    "type": "integer"
+                },
+                "refresh": {
+                    "type": "boolean"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.EnrichmentRunItem": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "changedFields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "error": {
+                    "type": "string"
+                },
+                "songId": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.EnrichmentRunRequest": {
+            "type": "object",
+            "properties": {
+                "filters": {
+                    "$ref": "#/definitions/models.Songs"
+                },
+                "refresh": {
+                    "description": "По умолчанию данные запрашиваются в обход кэша",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.FieldChange": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "new": {
+                    "type": "string"
+                },
+                "old": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FieldSource": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GroupLyricsStats": {
+            "type": "object",
+            "properties": {
+                "averageLineChars": {
+                    "type": "number"
+                },
+                "averageLineWords": {
+                    "description": "Средняя длина строки в словах и в символах",
+                    "type": "number"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "lexicalDiversity": {
+                    "description": "Отношение числа уникальных слов к числу слов (type-token ratio)",
+                    "type": "number"
+                },
+                "lines": {
+                    "type": "integer"
+                },
+                "repetitionRatio": {
+                    "description": "Доля строк, повторяющих более раннюю строку той же песни",
+                    "type": "number"
+                },
+                "songs": {
+                    "type": "integer"
+                },
+                "topWords": {
+                    "description": "Самые частые слова без стоп-слов русского и английского языков",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WordCount"
+                    }
+                },
+                "uniqueLines": {
+                    "type": "integer"
+                },
+                "uniqueWords": {
+                    "type": "integer"
+                },
+                "words": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LineAnalysis": {
+            "type": "object",
+            "properties": {
+                "language": {
+                    "description": "ru, en или пусто, если букв нет",
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "rhyme": {
+                    "description": "Буква рифмы в схеме части: строки с одинаковой буквой рифмуются",
+                    "type": "string"
+                },
+                "syllables": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Lyrics": {
+            "type": "object",
+            "properties": {
+                "sections": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LyricsSection"
+                    }
+                },
+                "version": {
+                    "description": "Версия разбора; текст, разобранный старой версией, разбирается заново",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LyricsAnalysis": {
+            "type": "object",
+            "properties": {
+                "sections": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SectionAnalysis"
+                    }
+                }
+            }
+        },
+        "models.LyricsDiff": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "integer"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DiffLine"
+                    }
+                },
+                "songId": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LyricsSection": {
+            "type": "object",
+            "properties": {
+                "label": {
+                    "description": "Метка из текста, например \"[Chorus]\"; пусто, если тип определён по повторам",
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "number": {
+                    "description": "Номер части среди частей того же типа (куплет 1, куплет 2), начиная с 1",
+                    "type": "integer"
+                },
+                "repeat": {
+                    "description": "Часть отмечена в тексте только меткой и повторяет предыдущую часть того же типа",
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PlaylistEntry": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "position": {
+                    "description": "Номер записи в плейлисте, начиная с 1",
+                    "type": "integer"
+                },
+                "releaseDate": {
+                    "description": "Год или дата выхода, если они есть в плейлисте",
+                    "type": "string"
+                },
+                "song": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PlaylistImport": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PlaylistEntry"
+                    }
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "matched": {
+                    "type": "integer"
+                },
+                "unresolved": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Revision": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldChange"
+                    }
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "mergedFrom": {
+                    "description": "Ревизия перенесена из истории песни-дубликата при объединении: ID дубликата и исходный номер ревизии",
+                    "type": "integer"
+                },
+                "mergedRevision": {
+                    "type": "integer"
+                },
+                "revertedFrom": {
+                    "type": "integer"
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "snapshot": {
+                    "$ref": "#/definitions/models.Songs"
+                },
+                "songId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SectionAnalysis": {
+            "type": "object",
+            "properties": {
+                "averageSyllables": {
+                    "type": "number"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LineAnalysis"
+                    }
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "pattern": {
+                    "description": "Название схемы, если все четверостишия части рифмуются одинаково: couplet (AABB),\nalternate (ABAB), enclosed (ABBA), monorhyme (AAAA) или ballad (ABCB)",
+                    "type": "string"
+                },
+                "scheme": {
+                    "description": "Схема рифмовки, например ABAB",
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SimilarSong": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "similarity": {
+                    "description": "Оценка сходства Жаккара текстов по шинглам из трёх слов, от 0 до 1",
+                    "type": "number"
+                },
+                "song": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SongLyricsStats": {
+            "type": "object",
+            "properties": {
+                "averageLineChars": {
+                    "type": "number"
+                },
+                "averageLineWords": {
+                    "description": "Средняя длина строки в словах и в символах",
+                    "type": "number"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lexicalDiversity": {
+                    "description": "Отношение числа уникальных слов к числу слов (type-token ratio)",
+                    "type": "number"
+                },
+                "lines": {
+                    "type": "integer"
+                },
+                "repetitionRatio": {
+                    "description": "Доля строк, повторяющих более раннюю строку той же песни",
+                    "type": "number"
+                },
+                "song": {
+                    "type": "string"
+                },
+                "topWords": {
+                    "description": "Самые частые слова без стоп-слов русского и английского языков",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WordCount"
+                    }
+                },
+                "uniqueLines": {
+                    "type": "integer"
+                },
+                "uniqueWords": {
+                    "type": "integer"
+                },
+                "words": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SongMerge": {
+            "type": "object",
+            "properties": {
+                "filledFields": {
+                    "description": "Пустые поля основной песни, заполненные значениями дубликата",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "mergedId": {
+                    "type": "integer"
+                },
+                "movedRevisions": {
+                    "description": "Количество ревизий дубликата, перенесённых в историю основной песни",
+                    "type": "integer"
+                },
+                "movedTranslations": {
+                    "description": "Языки переводов, перенесённых с дубликата",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "song": {
+                    "$ref": "#/definitions/models.Songs"
+                }
+            }
+        },
+        "models.Songs": {
+            "type": "object",
+            "properties": {
+                "enrichmentStatus": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "link": {
+                    "type": "string"
+                },
+                "releaseDate": {
+                    "type": "string"
+                },
+                "song": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SyncedLine": {
+            "type": "object",
+            "properties": {
+                "text": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "integer"
+                },
+                "words": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SyncedWord"
+                    }
+                }
+            }
+        },
+        "models.SyncedLyrics": {
+            "type": "object",
+            "properties": {
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SyncedLine"
+                    }
+                },
+                "metadata": {
+                    "description": "Теги LRC: ar, ti, al, length и т.д.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.SyncedWord": {
+            "type": "object",
+            "properties": {
+                "text": {
+                    "type": "string"
+                },
+                "time": {
+                    "description": "Время начала в миллисекундах от начала песни",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Translation": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string"
+                },
+                "language": {
+                    "description": "Код языка, например \"en\" или \"en-gb\"",
+                    "type": "string"
+                },
+                "outdated": {
+                    "description": "Текст оригинала изменился после сохранения перевода",
+                    "type": "boolean"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WordCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "word": {
                     "type": "string"
                 }
             }
@@ -307,12 +2994,12 @@ const docTemplate = `{
 
 // SwaggerInfo holds exported Swagger Info so clients can modify it
 var SwaggerInfo = &swag.Spec{
-	Version:          "",
-	Host:             "",
+	Version:          "1.0",
+	Host:             "localhost:8080",
 	BasePath:         "",
 	Schemes:          []string{},
-	Title:            "",
-	Description:      "",
+	Title:            "Swagger Music API",
+	Description:      "API for online songs library.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"Anastasia/songs/internal/models"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// @Summary		Get audit log
+// @Description	Get a list of mutating operations with optional filters. Requires the admin role
+// @Tags			audit
+// @Accept			json
+// @Produce		json
+// @Param			X-Role		header		string	true	"Caller role"
+// @Param			actor		query		string	false	"Actor filter"
+// @Param			action		query		string	false	"Action filter (create, update, delete, revert)"
+// @Param			entity		query		string	false	"Entity filter"
+// @Param			entityId	query		int		false	"Entity ID filter"
+// @Param			requestId	query		string	false	"Request ID filter"
+// @Param			from		query		string	false	"Start of the period (RFC 3339)"
+// @Param			to			query		string	false	"End of the period (RFC 3339)"
+// @Param			page		query		int		false	"Page number"
+// @Param			pageSize	query		int		false	"Page size"
+// @Success		200			{array}		models.AuditEntry
+// @Failure		400			{object}	string
+// @Failure		403			{object}	string
+// @Failure		500			{object}	string
+// @Router			/audit [get]
+func (api *API) auditHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.AuditFilter{
+		Actor:     query.Get("actor"),
+		Action:    query.Get("action"),
+		Entity:    query.Get("entity"),
+		RequestID: query.Get("requestId"),
+	}
+
+	var err error
+	if s := query.Get("entityId"); s != "" {
+		filter.EntityID, err = strconv.Atoi(s)
+		if err != nil {
+			logrus.WithError(err).Error("Invalid entity ID")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if s := query.Get("from"); s != "" {
+		filter.From, err = time.Parse(time.RFC3339, s)
+		if err != nil {
+			logrus.WithError(err).Error("Invalid period start")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if s := query.Get("to"); s != "" {
+		filter.To, err = time.Parse(time.RFC3339, s)
+		if err != nil {
+			logrus.WithError(err).Error("Invalid period end")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(query.Get("pageSize"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"filter":   filter,
+		"page":     page,
+		"pageSize": pageSize,
+	}).Info("Fetching audit log")
+
+	entries, err := api.srv.Audit.Audit(r.Context(), filter, page, pageSize)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch audit log")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(entries)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode audit log to JSON")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/services"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+const ndjsonContentType = "application/x-ndjson"
+
+// Превышено допустимое количество песен в пакете
+var errBatchTooLarge = errors.New("too many songs in batch")
+
+// @Summary		Create songs in bulk
+// @Description	Create many songs in one request. The body is a JSON array of songs or an NDJSON stream
+// @Description	(Content-Type: application/x-ndjson, one song per line). Every item is validated and checked
+// @Description	for duplicates like a single creation; items are saved in chunks, each in its own transaction.
+// @Description	With atomic=true either all items are saved in one transaction or none of them (422 is returned).
+// @Description	The number of items (BATCH_MAX_ITEMS) and the body size (BATCH_MAX_BYTES) are limited, 413 is returned otherwise
+// @Tags			songs
+// @Accept			json
+// @Accept			application/x-ndjson
+// @Produce		json
+// @Param			songs			body		[]models.Songs	true	"Songs"
+// @Param			atomic			query		bool			false	"All-or-nothing mode"
+// @Param			enrich			query		bool			false	"Request details from the external services (default true)"
+// @Param			X-Enrichment	header		string			false	"skip to disable enrichment"
+// @Success		200				{object}	models.BatchResult
+// @Failure		400				{object}	string
+// @Failure		403				{object}	string
+// @Failure		413				{object}	string
+// @Failure		422				{object}	models.BatchResult
+// @Failure		500				{object}	string
+// @Router			/songs:batch [post]
+func (api *API) batchCreateSongsHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var songs []models.Songs
+	var err error
+	body := http.MaxBytesReader(w, r.Body, api.maxBatchBytes)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == ndjsonContentType {
+		songs, err = decodeNDJSON(body, api.maxBatchItems)
+	} else {
+		songs, err = decodeJSONArray(body, api.maxBatchItems)
+	}
+	if err != nil {
+		logrus.WithError(err).Error("Failed to decode songs batch")
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.Is(err, errBatchTooLarge) || errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	opts := services.BatchOptions{CreateOptions: createOptions(r)}
+	if atomic := r.URL.Query().Get("atomic"); atomic != "" {
+		opts.Atomic, err = strconv.ParseBool(atomic)
+		if err != nil {
+			http.Error(w, "Invalid atomic flag", http.StatusBadRequest)
+			return
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{"count": len(songs), "atomic": opts.Atomic}).Info("Creating songs batch")
+
+	result, err := api.srv.CreateSongs(r.Context(), songs, opts)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to create songs batch")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if opts.Atomic && !result.Committed && len(songs) > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode batch result to JSON")
+	}
+}
+
+// Чтение JSON-массива песен по одному элементу, не загружая тело целиком
+func decodeJSONArray(r io.Reader, limit int) ([]models.Songs, error) {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return nil, errors.New("expected a JSON array of songs")
+	}
+
+	var songs []models.Songs
+	for dec.More() {
+		if len(songs) == limit {
+			return nil, fmt.Errorf("%w: limit is %d", errBatchTooLarge, limit)
+		}
+		var song models.Songs
+		if err := dec.Decode(&song); err != nil {
+			return nil, fmt.Errorf("item %d: %w", len(songs), err)
+		}
+		songs = append(songs, song)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return songs, nil
+}
+
+// Чтение NDJSON-потока песен; пустые строки пропускаются
+func decodeNDJSON(r io.Reader, limit int) ([]models.Songs, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var songs []models.Songs
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		if len(songs) == limit {
+			return nil, fmt.Errorf("%w: limit is %d", errBatchTooLarge, limit)
+		}
+		var song models.Songs
+		if err := json.Unmarshal(data, &song); err != nil {
+			// При ошибке чтения сканер отдаёт оборванную последнюю строку
+			if readErr := scanner.Err(); readErr != nil {
+				return nil, readErr
+			}
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		songs = append(songs, song)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return songs, nil
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBatchCreateSongsLimits(t *testing.T) {
+	song := `{"group":"Muse","song":"Uprising"}`
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"invalid json", "application/json", `{"group":"Muse"}`, http.StatusBadRequest},
+		{"too many items", "application/json", "[" + strings.Repeat(song+",", 2) + song + "]", http.StatusRequestEntityTooLarge},
+		{"too many lines", ndjsonContentType, strings.Repeat(song+"\n", 3), http.StatusRequestEntityTooLarge},
+		{"body too large", "application/json", `[{"group":"Muse","song":"` + strings.Repeat("a", 200) + `"}]`, http.StatusRequestEntityTooLarge},
+		{"ndjson body too large", ndjsonContentType, `{"group":"Muse","song":"` + strings.Repeat("a", 200) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &API{maxBatchItems: 2, maxBatchBytes: 128}
+			r := httptest.NewRequest(http.MethodPost, "/songs:batch", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+			api.batchCreateSongsHandler(rec, r)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"Anastasia/songs/internal/lyrics"
+	"Anastasia/songs/internal/models"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// Максимальный размер загружаемого ChordPro
+const maxChordProBytes = 1 << 20
+
+// Форматы текста с аккордами: тип содержимого по параметру format
+var chordFormats = map[string]string{
+	"text":     "text/plain",
+	"chordpro": "application/x-chordpro",
+	"json":     "application/json",
+}
+
+// Типы содержимого текста с аккордами в порядке предпочтения, включая синонимы
+var chordContentTypes = []string{
+	"application/json",
+	"text/plain",
+	"application/x-chordpro", "text/x-chordpro",
+}
+
+// @Summary		Get lyrics with chords
+// @Description	Get the lyrics with chords as JSON with chord positions (in characters of the line),
+// @Description	as plain text with chords over the lyrics or as ChordPro, chosen by the Accept header
+// @Description	(application/json, text/plain, application/x-chordpro) or the format parameter.
+// @Description	transpose shifts the key by semitones, capo recalculates chord shapes for a capo on that fret
+// @Description	(the sounding key does not change). Slash chords are transposed including the bass note;
+// @Description	sharps or flats follow the key unless accidentals is given
+// @Tags			chords
+// @Produce		json
+// @Produce		plain
+// @Produce		application/x-chordpro
+// @Param			id			path		int		true	"Song ID"
+// @Param			transpose	query		int		false	"Semitones to transpose by, e.g. +2 or -3"
+// @Param			capo		query		int		false	"Capo fret for the chord shapes"
+// @Param			accidentals	query		string	false	"sharp or flat"
+// @Param			format		query		string	false	"json, text or chordpro (overrides Accept, other values give 400)"
+// @Success		200			{object}	models.ChordSheet
+// @Failure		400			{object}	string
+// @Failure		404			{object}	string
+// @Failure		406			{object}	string
+// @Failure		500			{object}	string
+// @Router			/songs/{id}/chords [get]
+func (api *API) chordsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Invalid song ID")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	contentType, ok := formatContentType(w, r, chordFormats, chordContentTypes...)
+	if !ok {
+		return
+	}
+
+	opts, err := chordOptions(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{"id": id, "contentType": contentType, "transpose": opts.Transpose}).Info("Fetching chords")
+
+	sheet, err := api.srv.Lyrics.Chords(r.Context(), id, opts)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch chords")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	switch contentType {
+	case "text/plain":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, err = io.WriteString(w, lyrics.FormatChords(sheet))
+	case "application/x-chordpro", "text/x-chordpro":
+		w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+		_, err = io.WriteString(w, lyrics.FormatChordPro(sheet))
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(sheet)
+	}
+	if err != nil {
+		logrus.WithError(err).Error("Failed to write chords")
+	}
+}
+
+// Параметры transpose, capo и accidentals. Знак «+» в transpose=+2 без кодирования
+// приходит пробелом, поэтому значения обрезаются
+func chordOptions(query url.Values) (models.ChordOptions, error) {
+	opts := models.ChordOptions{Accidentals: strings.ToLower(query.Get("accidentals"))}
+	problems := map[string]string{}
+
+	if value := strings.TrimSpace(query.Get("transpose")); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			problems["transpose"] = "must be an integer number of semitones, e.g. +2 or -3"
+		}
+		opts.Transpose = n
+	}
+	if value := strings.TrimSpace(query.Get("capo")); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			problems["capo"] = "must be a fret number"
+		}
+		opts.Capo = &n
+	}
+
+	if len(problems) > 0 {
+		return models.ChordOptions{}, &models.ValidationError{Fields: problems}
+	}
+	return opts, nil
+}
+
+// @Summary		Upload lyrics with chords
+// @Description	Store the lyrics with chords in ChordPro format: chords in square brackets inside the lines,
+// @Description	directives like {title}, {key}, {capo}, {start_of_chorus}/{end_of_chorus} and {comment}.
+// @Description	Problems are reported by line number. The plain song text is replaced with the lyrics
+// @Description	without chords. Stored synced lyrics are kept while their lyrics match the new text;
+// @Description	editing the plain text later drops the chords (recorded in the audit log)
+// @Tags			chords
+// @Accept			application/x-chordpro
+// @Produce		json
+// @Param			id			path		int		true	"Song ID"
+// @Param			chordpro	body		string	true	"Lyrics with chords in ChordPro format"
+// @Success		200			{object}	models.ChordSheet
+// @Failure		400			{object}	string
+// @Failure		404			{object}	string
+// @Failure		413			{object}	string
+// @Failure		500			{object}	string
+// @Router			/songs/{id}/chords [put]
+func (api *API) saveChordsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Invalid song ID")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	defer r.Body.Close()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxChordProBytes))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to read chords")
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	logrus.WithField("id", id).Info("Saving chords")
+
+	sheet, err := api.srv.Lyrics.SaveChords(r.Context(), id, string(body))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to save chords")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(sheet)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode chords to JSON")
+	}
+}
+
+// @Summary		Delete lyrics with chords
+// @Description	Delete the ChordPro lyrics of the song; the plain text is kept
+// @Tags			chords
+// @Param			id	path	int	true	"Song ID"
+// @Success		204	"No Content"
+// @Failure		400	{object}	string
+// @Failure		404	{object}	string
+// @Failure		500	{object}	string
+// @Router			/songs/{id}/chords [delete]
+func (api *API) deleteChordsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Invalid song ID")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logrus.WithField("id", id).Info("Deleting chords")
+
+	err = api.srv.Lyrics.DeleteChords(r.Context(), id)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to delete chords")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"Anastasia/songs/internal/models"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// @Summary		Start re-enrichment
+// @Description	Request fresh details from the external services for every song matching the filters.
+// @Description	Fields supplied by users are kept, fields supplied by providers are refreshed. Songs that are already
+// @Description	queued are skipped. Requires the admin role
+// @Tags			enrichment
+// @Accept			json
+// @Produce		json
+// @Param			X-Role	header		string						true	"Caller role"
+// @Param			run		body		models.EnrichmentRunRequest	true	"Song filters"
+// @Success		202		{object}	models.EnrichmentRun
+// @Failure		400		{object}	string
+// @Failure		403		{object}	string
+// @Failure		500		{object}	string
+// @Router			/enrichment/runs [post]
+func (api *API) startEnrichmentRunHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.EnrichmentRunRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to decode enrichment run request")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	logrus.WithField("request", req).Info("Starting enrichment run")
+
+	run, err := api.srv.StartEnrichmentRun(r.Context(), req)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to start enrichment run")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	err = json.NewEncoder(w).Encode(run)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode enrichment run to JSON")
+	}
+}
+
+// @Summary		Get re-enrichment results
+// @Description	Get the progress of a re-enrichment run and the outcome for each song. Requires the admin role
+// @Tags			enrichment
+// @Accept			json
+// @Produce		json
+// @Param			X-Role	header		string	true	"Caller role"
+// @Param			id		path		int		true	"Run ID"
+// @Success		200		{object}	models.EnrichmentRun
+// @Failure		400		{object}	string
+// @Failure		403		{object}	string
+// @Failure		404		{object}	string
+// @Failure		500		{object}	string
+// @Router			/enrichment/runs/{id} [get]
+func (api *API) enrichmentRunHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Invalid run ID")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logrus.WithField("id", id).Info("Fetching enrichment run")
+
+	run, err := api.srv.EnrichmentRun(r.Context(), id)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch enrichment run")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(run)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode enrichment run to JSON")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
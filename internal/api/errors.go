@@ -0,0 +1,30 @@
+package api
+
+import (
+	"Anastasia/songs/internal/enrichment"
+	"Anastasia/songs/internal/models"
+	"errors"
+	"net/http"
+)
+
+// Подбор HTTP-статуса по ошибке сервиса
+func errorStatus(err error) int {
+	var validationErr *models.ValidationError
+	var duplicateErr *models.DuplicateError
+	switch {
+	case errors.As(err, &validationErr):
+		return http.StatusBadRequest
+	case errors.As(err, &duplicateErr):
+		return http.StatusConflict
+	case errors.Is(err, models.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, enrichment.ErrCircuitOpen):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, enrichment.ErrUpstream):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"Anastasia/songs/internal/models"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Количество строк, после которого ответ сбрасывается клиенту
+const exportFlushEvery = 100
+
+// Запись песен в одном из форматов выгрузки
+type songExporter interface {
+	begin() error
+	write(song models.Songs) error
+	end() error
+}
+
+// Форматы выгрузки: тип содержимого, расширение файла и конструктор
+var exportFormats = map[string]struct {
+	contentType string
+	extension   string
+	new         func(w io.Writer) songExporter
+}{
+	"csv":    {"text/csv; charset=utf-8", "csv", newCSVExporter},
+	"ndjson": {"application/x-ndjson", "ndjson", newNDJSONExporter},
+	"json":   {"application/json", "json", newJSONExporter},
+}
+
+// @Summary		Export songs
+// @Description	Stream all songs matching the listing filters as a downloadable file.
+// @Description	Rows are read from a database cursor, so the export is not limited by page size
+// @Tags			songs
+// @Produce		text/csv
+// @Produce		application/x-ndjson
+// @Produce		json
+// @Param			format		query		string	false	"Export format: csv (default), ndjson or json"
+// @Param			group		query		string	false	"Group filter"
+// @Param			song		query		string	false	"Song filter"
+// @Param			releaseDate	query		string	false	"Release date filter"
+// @Param			text		query		string	false	"Text filter"
+// @Param			link		query		string	false	"Link filter"
+// @Param			enrichmentStatus	query	string	false	"Enrichment status filter (pending, enriched, failed, manual)"
+// @Success		200			{file}		file
+// @Failure		400			{object}	string
+// @Failure		500			{object}	string
+// @Router			/songs/export [get]
+func (api *API) exportSongsHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("format")
+	if name == "" {
+		name = "csv"
+	}
+	format, ok := exportFormats[name]
+	if !ok {
+		http.Error(w, "Unsupported export format", http.StatusBadRequest)
+		return
+	}
+
+	filters := songFilters(r)
+	logrus.WithFields(logrus.Fields{"filters": filters, "format": name}).Info("Exporting songs")
+
+	filename := fmt.Sprintf("songs-%s.%s", time.Now().UTC().Format("20060102-150405"), format.extension)
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	exporter := format.new(w)
+	flusher, _ := w.(http.Flusher)
+	started := false
+	count := 0
+
+	err := api.srv.ExportSongs(r.Context(), filters, func(song models.Songs) error {
+		if !started {
+			started = true
+			if err := exporter.begin(); err != nil {
+				return err
+			}
+		}
+		if err := exporter.write(song); err != nil {
+			return err
+		}
+		count++
+		if flusher != nil && count%exportFlushEvery == 0 {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err == nil && !started {
+		err = exporter.begin()
+	}
+	if err == nil {
+		err = exporter.end()
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("count", count).Error("Failed to export songs")
+		if !started {
+			w.Header().Del("Content-Disposition")
+			http.Error(w, err.Error(), errorStatus(err))
+			return
+		}
+		// Часть файла уже отправлена: обрываем соединение, чтобы клиент не принял выгрузку за полную
+		panic(http.ErrAbortHandler)
+	}
+
+	logrus.WithField("count", count).Info("Exported songs successfully")
+}
+
+// Выгрузка в CSV с заголовком
+type csvExporter struct {
+	w *csv.Writer
+}
+
+func newCSVExporter(w io.Writer) songExporter {
+	return &csvExporter{w: csv.NewWriter(w)}
+}
+
+func (e *csvExporter) begin() error {
+	return e.w.Write([]string{"id", "group", "song", "releaseDate", "text", "link", "enrichmentStatus"})
+}
+
+func (e *csvExporter) write(song models.Songs) error {
+	err := e.w.Write([]string{
+		strconv.Itoa(song.ID),
+		csvCell(song.Group),
+		csvCell(song.Song),
+		csvCell(song.ReleaseDate),
+		csvCell(song.Text),
+		csvCell(song.Link),
+		csvCell(song.EnrichmentStatus),
+	})
+	if err != nil {
+		return err
+	}
+	return e.w.Error()
+}
+
+// Экранирование ячейки, которую табличный редактор принял бы за формулу
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func (e *csvExporter) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// Выгрузка в NDJSON: одна песня на строку
+type ndjsonExporter struct {
+	enc *json.Encoder
+}
+
+func newNDJSONExporter(w io.Writer) songExporter {
+	return &ndjsonExporter{enc: json.NewEncoder(w)}
+}
+
+func (e *ndjsonExporter) begin() error { return nil }
+
+func (e *ndjsonExporter) write(song models.Songs) error {
+	return e.enc.Encode(song)
+}
+
+func (e *ndjsonExporter) end() error { return nil }
+
+// Выгрузка в виде JSON-массива, который пишется по одному элементу
+type jsonExporter struct {
+	w     io.Writer
+	enc   *json.Encoder
+	first bool
+}
+
+func newJSONExporter(w io.Writer) songExporter {
+	return &jsonExporter{w: w, enc: json.NewEncoder(w), first: true}
+}
+
+func (e *jsonExporter) begin() error {
+	_, err := io.WriteString(e.w, "[\n")
+	return err
+}
+
+func (e *jsonExporter) write(song models.Songs) error {
+	if !e.first {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.first = false
+	return e.enc.Encode(song)
+}
+
+func (e *jsonExporter) end() error {
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
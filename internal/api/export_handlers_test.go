@@ -0,0 +1,49 @@
+package api
+
+import (
+	"Anastasia/songs/internal/models"
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestCSVExporterEscapesFormulas(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"plain", "Muse", "Muse"},
+		{"empty", "", ""},
+		{"formula", "=HYPERLINK(\"http://evil\")", "'=HYPERLINK(\"http://evil\")"},
+		{"plus", "+1+1", "'+1+1"},
+		{"minus", "-2+3", "'-2+3"},
+		{"at", "@SUM(A1)", "'@SUM(A1)"},
+		{"tab", "\t=1", "'\t=1"},
+		{"carriage return", "\r=1", "'\r=1"},
+		{"inner sign", "AC=DC", "AC=DC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			e := newCSVExporter(&b)
+			if err := e.write(models.Songs{ID: 1, Group: tt.value, Song: tt.value}); err != nil {
+				t.Fatal(err)
+			}
+			if err := e.end(); err != nil {
+				t.Fatal(err)
+			}
+
+			record, err := csv.NewReader(strings.NewReader(b.String())).Read()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if record[0] != "1" {
+				t.Errorf("id = %q, want %q", record[0], "1")
+			}
+			if record[1] != tt.want || record[2] != tt.want {
+				t.Errorf("cells = %q, %q, want %q", record[1], record[2], tt.want)
+			}
+		})
+	}
+}
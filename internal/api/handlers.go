@@ -1,13 +1,12 @@
 package api
 
 import (
+	lyricsparser "Anastasia/songs/internal/lyrics"
 	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/services"
 	"encoding/json"
-	"fmt"
-	"io"
+	"errors"
 	"net/http"
-	"net/url"
-	"os"
 	"strconv"
 	"strings"
 
@@ -25,19 +24,14 @@ import (
 // @Param			releaseDate	query		string	false	"Release date filter"
 // @Param			text		query		string	false	"Text filter"
 // @Param			link		query		string	false	"Link filter"
+// @Param			enrichmentStatus	query	string	false	"Enrichment status filter (pending, enriched, failed, manual)"
 // @Param			page		query		int		false	"Page number"
 // @Param			pageSize	query		int		false	"Page size"
 // @Success		200			{array}		models.Songs
 // @Failure		500			{object}	string
 // @Router			/songs [get]
 func (api *API) songsHandler(w http.ResponseWriter, r *http.Request) {
-	filters := models.Songs{
-		Group:       r.URL.Query().Get("group"),
-		Song:        r.URL.Query().Get("song"),
-		ReleaseDate: r.URL.Query().Get("releaseDate"),
-		Text:        r.URL.Query().Get("text"),
-		Link:        r.URL.Query().Get("link"),
-	}
+	filters := songFilters(r)
 
 	pageStr := r.URL.Query().Get("page")
 	page, err := strconv.Atoi(pageStr)
@@ -57,7 +51,7 @@ func (api *API) songsHandler(w http.ResponseWriter, r *http.Request) {
 		"pageSize": pageSize,
 	}).Info("Fetching songs")
 
-	songs, err := api.srv.Songs.Songs(filters, page, pageSize)
+	songs, err := api.srv.Songs.Songs(r.Context(), filters, page, pageSize)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to fetch songs")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -72,14 +66,21 @@ func (api *API) songsHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary		Get a song by ID
-// @Description	Get a lyrics of the song by its ID with optional verse number
+// @Description	Get a lyrics of the song by its ID with optional verse number.
+// @Description	The format is chosen by the Accept header: a JSON string (default), plain text,
+// @Description	an HTML fragment with verses as paragraphs or Markdown
 // @Tags			songs
 // @Accept			json
 // @Produce		json
+// @Produce		plain
+// @Produce		html
+// @Produce		text/markdown
 // @Param			id		path		int	true	"Song ID"
 // @Param			verse	query		int	false	"Verse number"
 // @Success		200		{string}	string
 // @Failure		400		{object}	string
+// @Failure		404		{object}	string
+// @Failure		406		{object}	string
 // @Failure		500		{object}	string
 // @Router			/songs/{id} [get]
 func (api *API) songByIDHandler(w http.ResponseWriter, r *http.Request) {
@@ -96,26 +97,30 @@ func (api *API) songByIDHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	contentType, ok := lyricsContentType(w, r)
+	if !ok {
+		return
+	}
+
 	logrus.WithField("id", id).Info("Fetching song by ID")
 
-	lyrics, err := api.srv.SongByID(id)
+	lyrics, err := api.srv.SongByID(r.Context(), id)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to fetch song by ID")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
-	lyricsPaginated := strings.Split(lyrics, "\n\n")
+	// Куплеты разделены одной или несколькими пустыми строками, переводы строк могут быть \r\n
+	lyricsPaginated := lyricsparser.Blocks(lyrics)
 
 	// Если значение verseNum некорректно, метод будет выводить полный текст песни
-	if verseNum == 0 || verseNum > len(lyricsPaginated) {
-		err = json.NewEncoder(w).Encode(lyrics)
-	} else {
-		err = json.NewEncoder(w).Encode(lyricsPaginated[verseNum-1])
+	if verseNum > 0 && verseNum <= len(lyricsPaginated) {
+		lyrics = lyricsPaginated[verseNum-1]
 	}
+	err = writeLyrics(w, contentType, lyrics, textDocument(lyrics))
 	if err != nil {
-		logrus.WithError(err).Error("Failed to encode lyrics to JSON")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logrus.WithError(err).Error("Failed to write lyrics")
 	}
 }
 
@@ -127,6 +132,7 @@ func (api *API) songByIDHandler(w http.ResponseWriter, r *http.Request) {
 // @Param			id	path	int	true	"Song ID"
 // @Success		204	"No Content"
 // @Failure		400	{object}	string
+// @Failure		404	{object}	string
 // @Failure		500	{object}	string
 // @Router			/songs/{id} [delete]
 func (api *API) deleteSongHandler(w http.ResponseWriter, r *http.Request) {
@@ -140,10 +146,10 @@ func (api *API) deleteSongHandler(w http.ResponseWriter, r *http.Request) {
 
 	logrus.WithField("id", id).Info("Deleting song")
 
-	err = api.srv.DeleteSong(id)
+	err = api.srv.DeleteSong(r.Context(), id)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to delete song")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -158,6 +164,7 @@ func (api *API) deleteSongHandler(w http.ResponseWriter, r *http.Request) {
 // @Param			song	body		models.Songs	true	"Song object"
 // @Success		200		{object}	models.Songs
 // @Failure		400		{object}	string
+// @Failure		404		{object}	string
 // @Failure		500		{object}	string
 // @Router			/songs/{id} [patch]
 func (api *API) updateSongHandler(w http.ResponseWriter, r *http.Request) {
@@ -181,22 +188,33 @@ func (api *API) updateSongHandler(w http.ResponseWriter, r *http.Request) {
 	song.ID = id
 	logrus.WithField("song", song).Info("Updating song")
 
-	err = api.srv.UpdateSong(song)
+	err = api.srv.UpdateSong(r.Context(), song)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to update song")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err))
 	}
 }
 
 // @Summary		Create a new song
-// @Description	Create a new song
+// @Description	Create a new song. Release date, lyrics and link are requested from the external service in the background,
+// @Description	until then the song has enrichmentStatus "pending". With enrich=false (or the X-Enrichment: skip header)
+// @Description	the caller-supplied data is stored as is and the song gets enrichmentStatus "manual";
+// @Description	this requires the editor or admin role. If the lyrics are given and nearly match the lyrics of existing
+// @Description	songs, those songs are listed in possibleDuplicates. A song with the same normalized name and group
+// @Description	(or, with DUPLICATE_SONG_SIMILARITY set, a similar name in the same group) is not added: the response is
+// @Description	409 with the id of the existing song, unless allowDuplicate=true
 // @Tags			songs
 // @Accept			json
 // @Produce		json
-// @Param			song	body		models.Songs	true	"Song object"
-// @Success		201		{object}	models.Songs
-// @Failure		400		{object}	string
-// @Failure		500		{object}	string
+// @Param			song			body		models.Songs	true	"Song object"
+// @Param			enrich			query		bool			false	"Request details from the external services (default true)"
+// @Param			allowDuplicate	query		bool			false	"Add the song even if it already exists"
+// @Param			X-Enrichment	header		string			false	"skip to disable enrichment"
+// @Success		201				{object}	models.CreatedSong
+// @Failure		400				{object}	string
+// @Failure		403				{object}	string
+// @Failure		409				{object}	models.DuplicateSong
+// @Failure		500				{object}	string
 // @Router			/songs [post]
 func (api *API) createSongHandler(w http.ResponseWriter, r *http.Request) {
 	var song models.Songs
@@ -209,51 +227,115 @@ func (api *API) createSongHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
-	encodedGroup := url.QueryEscape(song.Group)
-	encodedSong := url.QueryEscape(song.Song)
+	logrus.WithField("song", song).Info("Creating song")
 
-	apiURL := os.Getenv("EXTERNAL_API_URL") + fmt.Sprintf("?group=%s&song=%s", encodedGroup, encodedSong)
-	logrus.WithField("apiURL", apiURL).Info("Requesting data from external API")
+	opts := createOptions(r)
+	opts.AllowDuplicate, _ = strconv.ParseBool(r.URL.Query().Get("allowDuplicate"))
 
-	resp, err := http.Get(apiURL)
+	song, err = api.srv.CreateSong(r.Context(), song, opts)
+	var duplicateErr *models.DuplicateError
+	if errors.As(err, &duplicateErr) {
+		logrus.WithField("existingId", duplicateErr.ExistingID).Info("Song already exists")
+		writeDuplicate(w, duplicateErr)
+		return
+	}
 	if err != nil {
-		logrus.WithError(err).Error("Failed to get data from external API")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logrus.WithError(err).Error("Failed to create song")
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
-	defer resp.Body.Close()
 
-	logrus.WithField("status", resp.StatusCode).Info("Received response from external API")
+	created := models.CreatedSong{Songs: song}
+	if song.Text != "" {
+		// Песня уже добавлена: ошибка поиска дубликатов не должна приводить к ответу с ошибкой
+		created.PossibleDuplicates, err = api.srv.Similarity.PossibleDuplicates(r.Context(), song.ID)
+		if err != nil {
+			logrus.WithError(err).Warn("Failed to look up possible duplicates")
+		}
+	}
 
-	if resp.StatusCode != http.StatusOK {
-		logrus.WithField("status", resp.StatusCode).Error("External API returned non-OK status")
-		http.Error(w, "External API returned non-OK status", http.StatusInternalServerError)
-		return
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(created)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode song to JSON")
 	}
+}
+
+// @Summary		Preview a new song
+// @Description	Validate the song and request its details from the external services exactly as creation does,
+// @Description	without saving anything. Returns the would-be song, the source of each enriched field and warnings
+// @Tags			songs
+// @Accept			json
+// @Produce		json
+// @Param			song			body		models.Songs	true	"Song object"
+// @Param			enrich			query		bool			false	"Request details from the external services (default true)"
+// @Param			X-Enrichment	header		string			false	"skip to disable enrichment"
+// @Success		200				{object}	models.Enrichment
+// @Failure		400				{object}	string
+// @Failure		403				{object}	string
+// @Failure		500				{object}	string
+// @Router			/songs/preview [post]
+func (api *API) previewSongHandler(w http.ResponseWriter, r *http.Request) {
+	var song models.Songs
 
-	body, err := io.ReadAll(resp.Body)
+	err := json.NewDecoder(r.Body).Decode(&song)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to read response body")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logrus.WithError(err).Error("Failed to decode song data")
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	defer r.Body.Close()
 
-	logrus.WithField("responseBody", string(body)).Info("Response body from external API")
+	logrus.WithField("song", song).Info("Previewing song")
 
-	if err := json.Unmarshal(body, &song); err != nil {
-		logrus.WithError(err).Error("Failed to unmarshal song data")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	preview, err := api.srv.PreviewSong(r.Context(), song, createOptions(r))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to preview song")
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
-	logrus.WithField("song", song).Info("Creating song")
-
-	err = api.srv.CreateSong(song)
+	err = json.NewEncoder(w).Encode(preview)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to create song")
+		logrus.WithError(err).Error("Failed to encode song preview to JSON")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
 	}
+}
 
-	w.WriteHeader(http.StatusCreated)
+// Ответ 409 на добавление существующей песни: ID этой песни в теле и в заголовке Location
+func writeDuplicate(w http.ResponseWriter, dup *models.DuplicateError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/songs/"+strconv.Itoa(dup.ExistingID))
+	w.WriteHeader(http.StatusConflict)
+	err := json.NewEncoder(w).Encode(models.DuplicateSong{
+		Error:      dup.Error(),
+		ExistingID: dup.ExistingID,
+		Similarity: dup.Similarity,
+	})
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode duplicate song to JSON")
+	}
+}
+
+// Фильтры списка песен из параметров запроса
+func songFilters(r *http.Request) models.Songs {
+	return models.Songs{
+		Group:       r.URL.Query().Get("group"),
+		Song:        r.URL.Query().Get("song"),
+		ReleaseDate: r.URL.Query().Get("releaseDate"),
+		Text:        r.URL.Query().Get("text"),
+		Link:        r.URL.Query().Get("link"),
+
+		EnrichmentStatus: r.URL.Query().Get("enrichmentStatus"),
+	}
+}
+
+// Параметры создания песни из запроса: ?enrich=false или заголовок X-Enrichment: skip
+// отключают запрос данных из внешних источников
+func createOptions(r *http.Request) services.CreateOptions {
+	skip := strings.EqualFold(r.Header.Get(enrichmentHeader), "skip")
+	if enrich, err := strconv.ParseBool(r.URL.Query().Get("enrich")); err == nil && !enrich {
+		skip = true
+	}
+	return services.CreateOptions{SkipEnrichment: skip}
 }
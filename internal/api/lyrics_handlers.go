@@ -0,0 +1,182 @@
+package api
+
+import (
+	"Anastasia/songs/internal/models"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// Количество строк до и после найденной строки по умолчанию
+const defaultLyricsWindow = 2
+
+// @Summary		Get structured lyrics
+// @Description	Get the song lyrics split into sections (verse, chorus, pre-chorus, bridge, intro, outro) and lines.
+// @Description	Section types come from markers like [Chorus] or Куплет 2: in the text; unmarked blocks repeating
+// @Description	a marked section get its type, unmarked blocks repeated several times are choruses.
+// @Description	With lines, section or q only an excerpt with numbered lines is returned (models.LyricsExcerpt);
+// @Description	lines are numbered from 1 across all sections.
+// @Description	Besides JSON the lyrics are rendered as plain text, an HTML fragment or Markdown
+// @Description	with section headings, chosen by the Accept header
+// @Tags			lyrics
+// @Accept			json
+// @Produce		json
+// @Produce		plain
+// @Produce		html
+// @Produce		text/markdown
+// @Param			id		path		int		true	"Song ID"
+// @Param			lines	query		string	false	"Line range, e.g. 5-12, 5- or 7"
+// @Param			section	query		string	false	"Section type with optional number, e.g. chorus or verse:2"
+// @Param			q		query		string	false	"Text to search for (case and punctuation insensitive)"
+// @Param			hit		query		int		false	"Which match to show when searching (default 1)"
+// @Param			window	query		int		false	"Lines before and after the match (default 2)"
+// @Success		200		{object}	models.Lyrics
+// @Failure		400		{object}	string
+// @Failure		404		{object}	string
+// @Failure		406		{object}	string
+// @Failure		500		{object}	string
+// @Router			/songs/{id}/lyrics [get]
+func (api *API) lyricsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Invalid song ID")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	contentType, ok := lyricsContentType(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	if query.Get("lines") == "" && query.Get("section") == "" && query.Get("q") == "" {
+		logrus.WithField("id", id).Info("Fetching structured lyrics")
+
+		lyrics, err := api.srv.Lyrics.Lyrics(r.Context(), id)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to fetch structured lyrics")
+			http.Error(w, err.Error(), errorStatus(err))
+			return
+		}
+
+		err = writeLyrics(w, contentType, lyrics, structuredDocument(lyrics))
+		if err != nil {
+			logrus.WithError(err).Error("Failed to write lyrics")
+		}
+		return
+	}
+
+	sel, err := lyricsSelection(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{"id": id, "selection": sel}).Info("Fetching lyrics excerpt")
+
+	excerpt, err := api.srv.Lyrics.LyricsExcerpt(r.Context(), id, sel)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch lyrics excerpt")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	err = writeLyrics(w, contentType, excerpt, excerptDocument(excerpt))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to write lyrics excerpt")
+	}
+}
+
+// @Summary		Analyze lyrics rhymes and syllables
+// @Description	Get the rhyme scheme of each section (e.g. ABAB) with its name when all quatrains share it
+// @Description	(couplet, alternate, enclosed, monorhyme, ballad) and syllable counts per line.
+// @Description	Russian and English lines are analyzed with rule-based phonetics, without a stress dictionary
+// @Tags			lyrics
+// @Produce		json
+// @Param			id	path		int	true	"Song ID"
+// @Success		200	{object}	models.LyricsAnalysis
+// @Failure		400	{object}	string
+// @Failure		404	{object}	string
+// @Failure		500	{object}	string
+// @Router			/songs/{id}/lyrics/analysis [get]
+func (api *API) lyricsAnalysisHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Invalid song ID")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logrus.WithField("id", id).Info("Analyzing lyrics")
+
+	analysis, err := api.srv.Lyrics.Analysis(r.Context(), id)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to analyze lyrics")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(analysis)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode lyrics analysis to JSON")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Выбор фрагмента из параметров lines, section, q, hit и window
+func lyricsSelection(query url.Values) (models.LyricsSelection, error) {
+	sel := models.LyricsSelection{Window: defaultLyricsWindow}
+	problems := map[string]string{}
+
+	if lines := query.Get("lines"); lines != "" {
+		from, to, found := strings.Cut(lines, "-")
+		var err error
+		sel.From, err = strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			problems["lines"] = "must be a range like 5-12"
+		}
+		switch {
+		case !found:
+			sel.To = sel.From
+		case strings.TrimSpace(to) != "":
+			sel.To, err = strconv.Atoi(strings.TrimSpace(to))
+			if err != nil {
+				problems["lines"] = "must be a range like 5-12"
+			}
+		}
+	}
+
+	if section := query.Get("section"); section != "" {
+		typ, number, found := strings.Cut(section, ":")
+		sel.Section = strings.ToLower(strings.TrimSpace(typ))
+		if found {
+			n, err := strconv.Atoi(number)
+			if err != nil || n < 1 {
+				problems["section"] = "number must be a positive integer, e.g. verse:2"
+			}
+			sel.SectionNumber = n
+		}
+	}
+
+	sel.Query = query.Get("q")
+	for param, target := range map[string]*int{"hit": &sel.Hit, "window": &sel.Window} {
+		if value := query.Get(param); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				problems[param] = "must be an integer"
+			}
+			*target = n
+		}
+	}
+
+	if len(problems) > 0 {
+		return models.LyricsSelection{}, &models.ValidationError{Fields: problems}
+	}
+	return sel, nil
+}
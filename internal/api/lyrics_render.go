@@ -0,0 +1,250 @@
+package api
+
+import (
+	"Anastasia/songs/internal/lyrics"
+	"Anastasia/songs/internal/models"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Типы содержимого текста песни в порядке предпочтения; без заголовка Accept выдаётся JSON
+var lyricsContentTypes = []string{"application/json", "text/plain", "text/html", "text/markdown"}
+
+var (
+	// Символы разметки Markdown, которые экранируются в любом месте строки
+	markdownInlineRe = regexp.MustCompile("([\\\\`*_\\[\\]<>|~])")
+	// Начало строки, которое Markdown принял бы за заголовок, цитату, список или нумерованный список
+	markdownBlockRe = regexp.MustCompile(`^(\s*)([#>+\-=]|\d+[.)])`)
+)
+
+// Выбор типа содержимого текста песни по заголовку Accept. Если подходящего типа нет,
+// отвечает 406 и возвращает false
+func lyricsContentType(w http.ResponseWriter, r *http.Request) (string, bool) {
+	w.Header().Add("Vary", "Accept")
+	contentType, ok := negotiate(r, lyricsContentTypes...)
+	if !ok {
+		http.Error(w, "Supported types: "+strings.Join(lyricsContentTypes, ", "), http.StatusNotAcceptable)
+	}
+	return contentType, ok
+}
+
+// Текст песни для вывода в text/plain, text/html и text/markdown
+type lyricsDocument struct {
+	// Язык перевода в двуязычном тексте
+	Language string
+	Sections []lyricsBlock
+}
+
+// Часть текста; у блоков обычного текста нет заголовка
+type lyricsBlock struct {
+	Title string
+	Type  string
+	Lines []lyricsLine
+}
+
+type lyricsLine struct {
+	// Номер строки в тексте; 0 — не указывается
+	Number      int
+	Text        string
+	Translation string
+	// Строка найдена поиском
+	Match bool
+}
+
+// Запись текста в выбранном формате; value — значение для JSON
+func writeLyrics(w http.ResponseWriter, contentType string, value interface{}, doc lyricsDocument) error {
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	var err error
+	switch contentType {
+	case "text/plain":
+		_, err = io.WriteString(w, doc.text())
+	case "text/html":
+		_, err = io.WriteString(w, doc.html())
+	case "text/markdown":
+		_, err = io.WriteString(w, doc.markdown())
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(value)
+	}
+	return err
+}
+
+// Документ из обычного текста: куплеты без заголовков
+func textDocument(text string) lyricsDocument {
+	var doc lyricsDocument
+	for _, block := range lyrics.Blocks(text) {
+		var b lyricsBlock
+		for _, line := range strings.Split(block, "\n") {
+			b.Lines = append(b.Lines, lyricsLine{Text: line})
+		}
+		doc.Sections = append(doc.Sections, b)
+	}
+	return doc
+}
+
+// Документ из структурированного текста: части с заголовками
+func structuredDocument(l models.Lyrics) lyricsDocument {
+	var doc lyricsDocument
+	for _, s := range l.Sections {
+		b := lyricsBlock{Title: blockTitle(s.Type, s.Number, s.Label), Type: s.Type}
+		for _, line := range s.Lines {
+			b.Lines = append(b.Lines, lyricsLine{Text: line})
+		}
+		doc.Sections = append(doc.Sections, b)
+	}
+	return doc
+}
+
+// Документ из фрагмента: подряд идущие строки одной части под общим заголовком
+func excerptDocument(e models.LyricsExcerpt) lyricsDocument {
+	matches := map[int]bool{}
+	for _, n := range e.Matches {
+		matches[n] = true
+	}
+
+	var doc lyricsDocument
+	for i, line := range e.Lines {
+		previous := i > 0 && e.Lines[i-1].Number == line.Number-1 &&
+			e.Lines[i-1].Section == line.Section && e.Lines[i-1].SectionNumber == line.SectionNumber
+		if !previous {
+			doc.Sections = append(doc.Sections, lyricsBlock{
+				Title: blockTitle(line.Section, line.SectionNumber, ""),
+				Type:  line.Section,
+			})
+		}
+		b := &doc.Sections[len(doc.Sections)-1]
+		b.Lines = append(b.Lines, lyricsLine{Number: line.Number, Text: line.Text, Match: matches[line.Number]})
+	}
+	return doc
+}
+
+// Документ из двуязычного текста: перевод под каждой строкой оригинала
+func bilingualDocument(bl models.BilingualLyrics) lyricsDocument {
+	doc := lyricsDocument{Language: bl.Language}
+	for _, s := range bl.Sections {
+		b := lyricsBlock{Title: blockTitle(s.Type, s.Number, ""), Type: s.Type}
+		for _, line := range s.Lines {
+			b.Lines = append(b.Lines, lyricsLine{Number: line.Number, Text: line.Original, Translation: line.Translation})
+		}
+		doc.Sections = append(doc.Sections, b)
+	}
+	return doc
+}
+
+// Заголовок части: метка из текста без скобок или тип с номером ("Verse 2")
+func blockTitle(typ string, number int, label string) string {
+	if label = strings.TrimSpace(strings.Trim(strings.TrimSpace(label), "[]():")); label != "" {
+		return label
+	}
+	if typ == "" {
+		return ""
+	}
+	title := []rune(typ)
+	title[0] = unicode.ToUpper(title[0])
+	if number > 0 {
+		return fmt.Sprintf("%s %d", string(title), number)
+	}
+	return string(title)
+}
+
+// Обычный текст: заголовки частей в квадратных скобках, части через пустую строку,
+// перевод — с отступом под строкой оригинала
+func (d lyricsDocument) text() string {
+	var b strings.Builder
+	for i, s := range d.Sections {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		if s.Title != "" {
+			fmt.Fprintf(&b, "[%s]\n", s.Title)
+		}
+		for _, line := range s.Lines {
+			b.WriteString(line.Text + "\n")
+			if line.Translation != "" {
+				b.WriteString("    " + line.Translation + "\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// Фрагмент HTML для встраивания: часть — <section>, строки разделены <br>.
+// Весь текст экранируется
+func (d lyricsDocument) html() string {
+	var b strings.Builder
+	b.WriteString(`<article class="lyrics">` + "\n")
+	for _, s := range d.Sections {
+		b.WriteString("<section")
+		if s.Type != "" {
+			fmt.Fprintf(&b, ` class="%s"`, html.EscapeString(s.Type))
+		}
+		b.WriteString(">\n")
+		if s.Title != "" {
+			fmt.Fprintf(&b, "<h3>%s</h3>\n", html.EscapeString(s.Title))
+		}
+		b.WriteString("<p>\n")
+		for i, line := range s.Lines {
+			if i > 0 {
+				b.WriteString("<br>\n")
+			}
+			b.WriteString("<span")
+			if line.Number > 0 {
+				fmt.Fprintf(&b, ` data-line="%d"`, line.Number)
+			}
+			if line.Match {
+				b.WriteString(` class="match"`)
+			}
+			fmt.Fprintf(&b, ">%s</span>", html.EscapeString(line.Text))
+			if line.Translation != "" {
+				fmt.Fprintf(&b, "<br>\n"+`<span class="translation" lang="%s">%s</span>`,
+					html.EscapeString(d.Language), html.EscapeString(line.Translation))
+			}
+		}
+		b.WriteString("\n</p>\n</section>\n")
+	}
+	b.WriteString("</article>\n")
+	return b.String()
+}
+
+// Markdown: заголовки частей полужирным, переводы строк — обратной косой чертой в конце строки,
+// перевод курсивом. Разметка в тексте экранируется
+func (d lyricsDocument) markdown() string {
+	var b strings.Builder
+	for i, s := range d.Sections {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		if s.Title != "" {
+			fmt.Fprintf(&b, "**%s**\n\n", markdownEscape(s.Title))
+		}
+		var lines []string
+		for _, line := range s.Lines {
+			text := markdownEscape(line.Text)
+			if line.Match {
+				text = "**" + text + "**"
+			}
+			lines = append(lines, text)
+			if line.Translation != "" {
+				lines = append(lines, "_"+markdownEscape(line.Translation)+"_")
+			}
+		}
+		b.WriteString(strings.Join(lines, "\\\n") + "\n")
+	}
+	return b.String()
+}
+
+func markdownEscape(s string) string {
+	s = markdownInlineRe.ReplaceAllString(strings.TrimSpace(s), `\$1`)
+	if m := markdownBlockRe.FindStringSubmatchIndex(s); m != nil {
+		// Маркер в начале строки: экранируется последний символ (# → \#, 1. → 1\.)
+		end := m[5] - 1
+		s = s[:end] + `\` + s[end:]
+	}
+	return s
+}
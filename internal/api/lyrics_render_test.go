@@ -0,0 +1,133 @@
+package api
+
+import (
+	"Anastasia/songs/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLyricsContentType(t *testing.T) {
+	tests := []struct {
+		accept      string
+		contentType string
+		status      int
+	}{
+		{"", "application/json", http.StatusOK},
+		{"text/markdown, text/*;q=0.5", "text/markdown", http.StatusOK},
+		{"text/*;q=0.5, text/html", "text/html", http.StatusOK},
+		{"image/*, application/json;q=0", "", http.StatusNotAcceptable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/songs/1/lyrics", nil)
+			r.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+
+			contentType, ok := lyricsContentType(rec, r)
+			if contentType != tt.contentType || ok != (tt.status == http.StatusOK) {
+				t.Errorf("lyricsContentType = %q, %v, want %q", contentType, ok, tt.contentType)
+			}
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.status == http.StatusNotAcceptable && !strings.Contains(rec.Body.String(), "text/markdown") {
+				t.Errorf("body = %q, want the supported types", rec.Body)
+			}
+			if vary := rec.Header().Get("Vary"); vary != "Accept" {
+				t.Errorf("Vary = %q, want Accept", vary)
+			}
+		})
+	}
+}
+
+// Документ, в котором заголовок, строки, перевод и язык содержат разметку
+func hostileDocument() lyricsDocument {
+	return bilingualDocument(models.BilingualLyrics{
+		Language: `en" onmouseover="alert(1)`,
+		Sections: []models.BilingualSection{{
+			Type:   `chorus"><script>`,
+			Number: 1,
+			Lines: []models.BilingualLine{
+				{Number: 1, Original: "<script>alert('x')</script>", Translation: "<b>bold</b> & co"},
+				{Number: 2, Original: "# not a heading"},
+			},
+		}},
+	})
+}
+
+func TestWriteLyrics(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        string
+	}{
+		{
+			"text/html",
+			`<article class="lyrics">` + "\n" +
+				`<section class="chorus&#34;&gt;&lt;script&gt;">` + "\n" +
+				"<h3>Chorus&#34;&gt;&lt;script&gt; 1</h3>\n" +
+				"<p>\n" +
+				`<span data-line="1">&lt;script&gt;alert(&#39;x&#39;)&lt;/script&gt;</span><br>` + "\n" +
+				`<span class="translation" lang="en&#34; onmouseover=&#34;alert(1)">&lt;b&gt;bold&lt;/b&gt; &amp; co</span><br>` + "\n" +
+				`<span data-line="2"># not a heading</span>` + "\n" +
+				"</p>\n</section>\n</article>\n",
+		},
+		{
+			"text/markdown",
+			"**Chorus\"\\>\\<script\\> 1**\n\n" +
+				"\\<script\\>alert('x')\\</script\\>\\\n" +
+				"_\\<b\\>bold\\</b\\> & co_\\\n" +
+				"\\# not a heading\n",
+		},
+		{
+			"text/plain",
+			"[Chorus\"><script> 1]\n" +
+				"<script>alert('x')</script>\n" +
+				"    <b>bold</b> & co\n" +
+				"# not a heading\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			if err := writeLyrics(rec, tt.contentType, nil, hostileDocument()); err != nil {
+				t.Fatal(err)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.contentType+"; charset=utf-8" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			if rec.Body.String() != tt.want {
+				t.Errorf("body = %q, want %q", rec.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownEscape(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"plain words", "plain words"},
+		{"  trimmed  ", "trimmed"},
+		{"*bold* _em_ `code` ~strike~", `\*bold\* \_em\_ \` + "`" + `code\` + "`" + ` \~strike\~`},
+		{"[link](http://x) | pipe", `\[link\](http://x) \| pipe`},
+		{`back\slash <tag>`, `back\\slash \<tag\>`},
+		{"# heading", `\# heading`},
+		{"## heading", `\## heading`},
+		{"> quote", `\> quote`},
+		{"- item", `\- item`},
+		{"+ item", `\+ item`},
+		{"=== underline", `\=== underline`},
+		{"1984. year", `1984\. year`},
+		{"2) item", `2\) item`},
+		{"a - b. 1. c", "a - b. 1. c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := markdownEscape(tt.text); got != tt.want {
+				t.Errorf("markdownEscape(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"Anastasia/songs/internal/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// Тело запроса на объединение песен
+type mergeRequest struct {
+	DuplicateID int `json:"duplicateId"`
+}
+
+// @Summary		Merge a duplicate song
+// @Description	Merge the duplicate song into this (canonical) song. Empty release date, lyrics and link of the song are
+// @Description	filled from the duplicate, translations to languages the song lacks are moved, synced lyrics and chords
+// @Description	are moved when the lyrics match. Revisions of the duplicate are appended to the song history (mergedFrom
+// @Description	and mergedRevision keep their origin) and filled fields keep their source. The duplicate is deleted; GET
+// @Description	requests to its id are redirected to the song. Admins only
+// @Tags			songs
+// @Accept			json
+// @Produce		json
+// @Param			id		path		int				true	"Canonical song ID"
+// @Param			merge	body		mergeRequest	true	"Duplicate song"
+// @Success		200		{object}	models.SongMerge
+// @Failure		400		{object}	string
+// @Failure		403		{object}	string
+// @Failure		404		{object}	string
+// @Failure		500		{object}	string
+// @Router			/songs/{id}/merge [post]
+func (api *API) mergeSongHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Invalid song ID")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req mergeRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to decode merge request")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	logrus.WithFields(logrus.Fields{"id": id, "duplicateId": req.DuplicateID}).Info("Merging songs")
+
+	merge, err := api.srv.MergeSong(r.Context(), id, req.DuplicateID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to merge songs")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(merge)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode song merge to JSON")
+	}
+}
+
+// Перенаправляет GET-запросы к песне, объединённой с другой песней, на основную песню.
+// Перенаправление ищется, только когда обработчик отвечает 404, поэтому запросы
+// к существующим песням не требуют лишнего обращения к БД
+func (api *API) songRedirectMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if route := mux.CurrentRoute(r); route != nil {
+			// Номера ревизий в запросе сравнения относятся к нумерации дубликата, у основной песни они другие
+			template, _ := route.GetPathTemplate()
+			if !strings.HasPrefix(template, "/songs/{id}") || template == "/songs/{id}/revisions/diff" {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		next.ServeHTTP(&redirectWriter{ResponseWriter: w, api: api, r: r, id: id}, r)
+	})
+}
+
+// Заменяет ответ 404 перенаправлением, если песня объединена с другой песней
+type redirectWriter struct {
+	http.ResponseWriter
+	api *API
+	r   *http.Request
+	id  int
+	// Ответ обработчика заменён перенаправлением, его тело отбрасывается
+	redirected bool
+}
+
+func (rw *redirectWriter) WriteHeader(status int) {
+	if status == http.StatusNotFound {
+		songID, err := rw.api.srv.Merges.Redirect(rw.r.Context(), rw.id)
+		switch {
+		case err == nil:
+			rw.redirect(songID)
+			return
+		case !errors.Is(err, models.ErrNotFound):
+			logrus.WithError(err).Warn("Failed to look up song redirect")
+		}
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *redirectWriter) Write(b []byte) (int, error) {
+	if rw.redirected {
+		return len(b), nil
+	}
+	return rw.ResponseWriter.Write(b)
+}
+
+// Постоянное перенаправление на тот же путь с ID основной песни
+func (rw *redirectWriter) redirect(songID int) {
+	location := "/songs/" + strconv.Itoa(songID) + strings.TrimPrefix(rw.r.URL.Path, "/songs/"+mux.Vars(rw.r)["id"])
+	if rw.r.URL.RawQuery != "" {
+		location += "?" + rw.r.URL.RawQuery
+	}
+
+	h := rw.Header()
+	h.Del("Content-Type")
+	h.Del("X-Content-Type-Options")
+	h.Set("Location", location)
+	rw.ResponseWriter.WriteHeader(http.StatusMovedPermanently)
+	rw.redirected = true
+}
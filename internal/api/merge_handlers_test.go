@@ -0,0 +1,94 @@
+package api
+
+import (
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/services"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// Подменный сервис объединений: знает только перенаправления; остальные методы не используются
+type fakeMerges struct {
+	services.Merges
+	redirects map[int]int
+	lookups   int
+}
+
+func (f *fakeMerges) Redirect(ctx context.Context, oldID int) (int, error) {
+	f.lookups++
+	if oldID == 500 {
+		return 0, errors.New("db is down")
+	}
+	if songID, ok := f.redirects[oldID]; ok {
+		return songID, nil
+	}
+	return 0, models.ErrNotFound
+}
+
+func TestSongRedirectMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		status   int
+		location string
+		body     string
+		lookup   bool
+	}{
+		{"existing song", http.MethodGet, "/songs/1", http.StatusOK, "", "song 1", false},
+		{"merged song", http.MethodGet, "/songs/2", http.StatusMovedPermanently, "/songs/1", "", true},
+		{"merged song subpath and query", http.MethodGet, "/songs/2/lyrics?format=html", http.StatusMovedPermanently, "/songs/1/lyrics?format=html", "", true},
+		{"head request", http.MethodHead, "/songs/2", http.StatusMovedPermanently, "/songs/1", "", true},
+		{"unknown song", http.MethodGet, "/songs/3", http.StatusNotFound, "", "not found\n", true},
+		{"lookup fails", http.MethodGet, "/songs/500", http.StatusNotFound, "", "not found\n", true},
+		{"revisions diff", http.MethodGet, "/songs/2/revisions/diff?from=1&to=2", http.StatusNotFound, "", "not found\n", false},
+		{"not a get request", http.MethodPut, "/songs/2/lyrics", http.StatusNotFound, "", "not found\n", false},
+		{"not a song route", http.MethodGet, "/groups/2", http.StatusNotFound, "", "not found\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merges := &fakeMerges{redirects: map[int]int{2: 1}}
+			api := &API{srv: &services.Service{Merges: merges}}
+
+			// Обработчики знают только песню 1
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				if mux.Vars(r)["id"] != "1" {
+					http.Error(w, "not found", http.StatusNotFound)
+					return
+				}
+				w.Write([]byte("song 1"))
+			}
+			router := mux.NewRouter()
+			router.Use(api.songRedirectMiddleware)
+			router.HandleFunc("/songs/{id}", handler).Methods(http.MethodGet, http.MethodHead)
+			router.HandleFunc("/songs/{id}/lyrics", handler).Methods(http.MethodGet, http.MethodPut)
+			router.HandleFunc("/songs/{id}/revisions/diff", handler).Methods(http.MethodGet)
+			router.HandleFunc("/groups/{id}", handler).Methods(http.MethodGet)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if got := rec.Header().Get("Location"); got != tt.location {
+				t.Errorf("Location = %q, want %q", got, tt.location)
+			}
+			// Тело ответа 404 отбрасывается вместе с его заголовками
+			if rec.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", rec.Body, tt.body)
+			}
+			if tt.status == http.StatusMovedPermanently && (rec.Header().Get("Content-Type") != "" || rec.Header().Get("X-Content-Type-Options") != "") {
+				t.Errorf("redirect keeps the headers of the 404 response: %v", rec.Header())
+			}
+			if (merges.lookups > 0) != tt.lookup {
+				t.Errorf("redirect lookups = %d, want lookup %v", merges.lookups, tt.lookup)
+			}
+		})
+	}
+}
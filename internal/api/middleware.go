@@ -0,0 +1,134 @@
+package api
+
+import (
+	"Anastasia/songs/internal/reqctx"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// Заголовки, в которых шлюз передаёт имя и роль аутентифицированного пользователя
+const (
+	userHeader      = "X-User"
+	roleHeader      = "X-Role"
+	requestIDHeader = "X-Request-ID"
+)
+
+// Наибольшая длина имени пользователя и идентификатора запроса (размеры столбцов журнала аудита)
+const (
+	maxUserLength      = 255
+	maxRequestIDLength = 64
+)
+
+// Допустимый идентификатор запроса, переданный клиентом
+var requestIDRe = regexp.MustCompile(`^[A-Za-z0-9._:-]+$`)
+
+// Заголовок, которым клиент может отключить запрос данных из внешних источников
+const enrichmentHeader = "X-Enrichment"
+
+// Сохраняет имя и роль пользователя из заголовков запроса в контексте. Заголовкам верят,
+// только если запрос пришёл с адреса шлюза из trusted; запросы с других адресов анонимны
+func identityMiddleware(trusted []*net.IPNet) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, role := r.Header.Get(userHeader), r.Header.Get(roleHeader)
+			if (user != "" || role != "") && !fromTrustedProxy(r, trusted) {
+				logrus.WithField("remoteAddr", r.RemoteAddr).Debug("Ignoring identity headers from untrusted address")
+				user, role = "", ""
+			}
+			if utf8.RuneCountInString(user) > maxUserLength {
+				http.Error(w, fmt.Sprintf("%s must be at most %d characters", userHeader, maxUserLength), http.StatusBadRequest)
+				return
+			}
+
+			ctx := reqctx.WithUser(r.Context(), user)
+			ctx = reqctx.WithRole(ctx, role)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Присваивает запросу идентификатор, если клиент его не передал или передал некорректный,
+// и возвращает его в ответе
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if len(id) > maxRequestIDLength || !requestIDRe.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(reqctx.WithRequestID(r.Context(), id)))
+	})
+}
+
+// Пришёл ли запрос с одного из адресов trusted
+func fromTrustedProxy(r *http.Request, trusted []*net.IPNet) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	for _, network := range trusted {
+		if ip != nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Разбор списка подсетей через запятую (127.0.0.1/32,10.0.0.0/8); отдельный адрес означает одну машину
+func parseNetworks(list string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			if ip := net.ParseIP(item); ip != nil && ip.To4() != nil {
+				item += "/32"
+			} else {
+				item += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(item)
+		if err != nil {
+			logrus.WithError(err).WithField("network", item).Warn("Invalid trusted proxy network, skipping")
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// Пропускает к обработчику только пользователей с указанной ролью
+func requireRole(role string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if reqctx.Role(r.Context()) != role {
+			logrus.WithFields(logrus.Fields{
+				"user": reqctx.User(r.Context()),
+				"role": reqctx.Role(r.Context()),
+			}).Warn("Access denied")
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		logrus.WithError(err).Error("Failed to generate request ID")
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"Anastasia/songs/internal/reqctx"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		keep bool
+	}{
+		{"client id", "3f2a-01:req.7", true},
+		{"missing", "", false},
+		{"too long", strings.Repeat("a", maxRequestIDLength+1), false},
+		{"invalid characters", "id with spaces", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := requestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = reqctx.RequestID(r.Context())
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/songs", nil)
+			r.Header.Set(requestIDHeader, tt.id)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+
+			if tt.keep && got != tt.id {
+				t.Errorf("request id = %q, want %q", got, tt.id)
+			}
+			if !tt.keep && (got == tt.id || len(got) != 32) {
+				t.Errorf("request id = %q, want a generated one", got)
+			}
+			if rec.Header().Get(requestIDHeader) != got {
+				t.Errorf("response header = %q, want %q", rec.Header().Get(requestIDHeader), got)
+			}
+		})
+	}
+}
+
+func TestIdentityMiddleware(t *testing.T) {
+	trusted := parseNetworks("127.0.0.1, 10.0.0.0/8, ::1, bad")
+	tests := []struct {
+		name       string
+		remoteAddr string
+		user       string
+		wantUser   string
+		wantRole   string
+		wantStatus int
+	}{
+		{"trusted proxy", "10.1.2.3:5000", "alice", "alice", reqctx.RoleAdmin, http.StatusOK},
+		{"trusted ipv6", "[::1]:5000", "alice", "alice", reqctx.RoleAdmin, http.StatusOK},
+		{"untrusted address", "203.0.113.5:5000", "alice", reqctx.Anonymous, "", http.StatusOK},
+		{"user too long", "127.0.0.1:5000", strings.Repeat("a", maxUserLength+1), "", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var user, role string
+			h := identityMiddleware(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				user, role = reqctx.User(r.Context()), reqctx.Role(r.Context())
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/audit", nil)
+			r.RemoteAddr = tt.remoteAddr
+			r.Header.Set(userHeader, tt.user)
+			r.Header.Set(roleHeader, reqctx.RoleAdmin)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if user != tt.wantUser || role != tt.wantRole {
+				t.Errorf("user, role = %q, %q, want %q, %q", user, role, tt.wantUser, tt.wantRole)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Выбор типа содержимого ответа по заголовку Accept. offers перечислены в порядке предпочтения
+// сервера; без заголовка Accept выбирается первый. Возвращает false, если клиент не принимает
+// ни один из предложенных типов
+func negotiate(r *http.Request, offers ...string) (string, bool) {
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return offers[0], true
+	}
+
+	type mediaRange struct {
+		typ, subtype string
+		q            float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		typ, subtype, _ := strings.Cut(mediaType, "/")
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{typ: typ, subtype: subtype, q: q})
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		typ, subtype, _ := strings.Cut(offer, "/")
+		// Качество определяется самым точным подходящим диапазоном: type/subtype, type/*, */*
+		q, specificity := 0.0, -1
+		for _, mr := range ranges {
+			var s int
+			switch {
+			case mr.typ == typ && mr.subtype == subtype:
+				s = 2
+			case mr.typ == typ && mr.subtype == "*":
+				s = 1
+			case mr.typ == "*" && mr.subtype == "*":
+				s = 0
+			default:
+				continue
+			}
+			if s > specificity {
+				q, specificity = mr.q, s
+			}
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best, best != ""
+}
+
+// Выбор типа содержимого по параметру format (formats — тип содержимого по названию формата),
+// а без него — по заголовку Accept среди offers. На неизвестный формат отвечает 400, если клиент
+// не принимает ни один из типов — 406; в обоих случаях возвращает false
+func formatContentType(w http.ResponseWriter, r *http.Request, formats map[string]string, offers ...string) (string, bool) {
+	w.Header().Add("Vary", "Accept")
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		contentType, ok := negotiate(r, offers...)
+		if !ok {
+			http.Error(w, "Supported types: "+strings.Join(offers, ", "), http.StatusNotAcceptable)
+		}
+		return contentType, ok
+	}
+
+	contentType, ok := formats[format]
+	if !ok {
+		names := make([]string, 0, len(formats))
+		for name := range formats {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		http.Error(w, "Supported formats: "+strings.Join(names, ", "), http.StatusBadRequest)
+	}
+	return contentType, ok
+}
+
+// Языки из заголовка Accept-Language в порядке убывания веса; "*" и языки с q=0 пропускаются
+func acceptLanguages(r *http.Request) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag: tag, q: q})
+		}
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	languages := make([]string, len(tags))
+	for i, t := range tags {
+		languages[i] = t.tag
+	}
+	return languages
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{"application/json", "text/plain", "text/html", "text/markdown"}
+	tests := []struct {
+		name   string
+		accept string
+		want   string
+	}{
+		{"no header picks the first offer", "", "application/json"},
+		{"exact type", "text/html", "text/html"},
+		{"highest q wins", "text/plain;q=0.5, text/markdown;q=0.8, application/json;q=0.1", "text/markdown"},
+		{"equal q keeps server preference", "text/markdown, text/plain", "text/plain"},
+		{"type wildcard", "text/*", "text/plain"},
+		{"any type", "*/*", "application/json"},
+		{"exact type is more specific than a wildcard", "text/*;q=0.9, text/plain;q=0.1", "text/html"},
+		{"type wildcard is more specific than any type", "*/*;q=0.9, text/*;q=0.2, text/markdown", "text/markdown"},
+		{"q=0 excludes a type", "application/json;q=0, */*", "text/plain"},
+		{"q=0 on a wildcard excludes its types", "text/*;q=0, */*;q=0.5", "application/json"},
+		{"q=0 overrides a wildcard", "*/*, application/json;q=0, text/plain;q=0", "text/html"},
+		{"parameters are ignored", "text/html; charset=utf-8; level=1", "text/html"},
+		{"malformed ranges are skipped", "text/;q=1, text/markdown;q=abc, text/html;q=0.5", "text/html"},
+		{"nothing acceptable", "image/png, application/*;q=0", ""},
+		{"everything excluded", "*/*;q=0", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			got, ok := negotiate(r, offers...)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("negotiate(%q) = %q, %v, want %q", tt.accept, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		formats     map[string]string
+		offers      []string
+		url         string
+		accept      string
+		contentType string
+		status      int
+	}{
+		{"format parameter", syncedFormats, syncedContentTypes, "/?format=srt", "text/vtt", "application/x-subrip", http.StatusOK},
+		{"accept header", syncedFormats, syncedContentTypes, "/", "text/vtt, */*;q=0.1", "text/vtt", http.StatusOK},
+		{"no accept header", syncedFormats, syncedContentTypes, "/", "", "text/x-lrc", http.StatusOK},
+		{"unknown format", syncedFormats, syncedContentTypes, "/?format=xyz", "", "", http.StatusBadRequest},
+		{"unknown format with acceptable type", syncedFormats, syncedContentTypes, "/?format=xyz", "text/vtt", "", http.StatusBadRequest},
+		{"not acceptable", syncedFormats, syncedContentTypes, "/", "image/png", "", http.StatusNotAcceptable},
+		{"chords format parameter", chordFormats, chordContentTypes, "/?format=chordpro", "", "application/x-chordpro", http.StatusOK},
+		{"chords synonym", chordFormats, chordContentTypes, "/", "text/x-chordpro", "text/x-chordpro", http.StatusOK},
+		{"unknown chords format", chordFormats, chordContentTypes, "/?format=foo", "", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			contentType, ok := formatContentType(rec, r, tt.formats, tt.offers...)
+			if ok != (tt.status == http.StatusOK) || contentType != tt.contentType {
+				t.Errorf("formatContentType = %q, %v, want %q", contentType, ok, tt.contentType)
+			}
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if vary := rec.Header().Get("Vary"); vary != "Accept" {
+				t.Errorf("Vary = %q, want Accept", vary)
+			}
+		})
+	}
+}
+
+func TestAcceptLanguages(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"", []string{}},
+		{"ru", []string{"ru"}},
+		{"en-US,en;q=0.9,ru;q=0.8", []string{"en-US", "en", "ru"}},
+		{"de;q=0.5, fr, it;q=0.7", []string{"fr", "it", "de"}},
+		{"fr;q=0.5, de;q=0.5, en", []string{"en", "fr", "de"}},
+		{"en, ru;q=0, *;q=0.1", []string{"en"}},
+		{"*", []string{}},
+		{"en;q=high, ru", []string{"ru"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept-Language", tt.header)
+			if got := acceptLanguages(r); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("acceptLanguages(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"Anastasia/songs/internal/importer"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Форматы плейлистов по типу содержимого запроса
+var playlistContentTypes = map[string]string{
+	"audio/x-mpegurl":               importer.FormatM3U,
+	"audio/mpegurl":                 importer.FormatM3U,
+	"application/vnd.apple.mpegurl": importer.FormatM3U,
+	"application/xspf+xml":          importer.FormatXSPF,
+	"application/x-plist":           importer.FormatITunes,
+}
+
+// @Summary		Import a playlist
+// @Description	Seed the library from a playlist or library export: M3U/M3U8 with EXTINF, XSPF or
+// @Description	iTunes/Music Library XML. Every entry is matched to an existing song by group and name;
+// @Description	unmatched entries get a stub song whose details are requested from the external services.
+// @Description	Entries without group or name are reported as unresolved
+// @Tags			songs
+// @Accept			plain
+// @Produce		json
+// @Param			playlist		body		string	true	"Playlist file"
+// @Param			format			query		string	false	"m3u, xspf or itunes (default: by Content-Type)"
+// @Param			enrich			query		bool	false	"Request details from the external services (default true)"
+// @Param			X-Enrichment	header		string	false	"skip to disable enrichment"
+// @Success		200				{object}	models.PlaylistImport
+// @Failure		400				{object}	string
+// @Failure		403				{object}	string
+// @Failure		413				{object}	string
+// @Failure		500				{object}	string
+// @Router			/songs/import [post]
+func (api *API) importPlaylistHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		format = playlistContentTypes[mediaType]
+	}
+	if format == "" {
+		http.Error(w, "Unknown playlist format, use the format parameter", http.StatusBadRequest)
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, api.maxImportBytes)
+	entries, err := importer.ReadPlaylist(body, format)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to read playlist")
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if len(entries) > api.maxBatchItems {
+		http.Error(w, errBatchTooLarge.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{"format": format, "entries": len(entries)}).Info("Importing playlist")
+
+	result, err := api.srv.ImportPlaylist(r.Context(), entries, createOptions(r))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to import playlist")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode playlist import result to JSON")
+	}
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// @Summary		Get song provenance
+// @Description	Get the source of the current value of each song field: "user", "revert" or the name of the enrichment provider
+// @Tags			songs
+// @Accept			json
+// @Produce		json
+// @Param			id	path		int	true	"Song ID"
+// @Success		200	{array}		models.FieldSource
+// @Failure		400	{object}	string
+// @Failure		404	{object}	string
+// @Failure		500	{object}	string
+// @Router			/songs/{id}/provenance [get]
+func (api *API) provenanceHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Invalid song ID")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logrus.WithField("id", id).Info("Fetching song provenance")
+
+	sources, err := api.srv.Provenance.Provenance(r.Context(), id)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch song provenance")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(sources)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode provenance to JSON")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// @Summary		Get song revisions
+// @Description	Get the change history of the song: who changed which fields and when
+// @Tags			revisions
+// @Accept			json
+// @Produce		json
+// @Param			id	path		int	true	"Song ID"
+// @Success		200	{array}		models.Revision
+// @Failure		400	{object}	string
+// @Failure		404	{object}	string
+// @Failure		500	{object}	string
+// @Router			/songs/{id}/revisions [get]
+func (api *API) revisionsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Invalid song ID")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logrus.WithField("id", id).Info("Fetching song revisions")
+
+	revisions, err := api.srv.Revisions.Revisions(r.Context(), id)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch song revisions")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(revisions)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode revisions to JSON")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// @Summary		Diff lyrics between revisions
+// @Description	Get a line-level diff of the song lyrics between two revisions
+// @Tags			revisions
+// @Accept			json
+// @Produce		json
+// @Param			id		path		int	true	"Song ID"
+// @Param			from	query		int	true	"Base revision"
+// @Param			to		query		int	true	"Target revision"
+// @Success		200		{object}	models.LyricsDiff
+// @Failure		400		{object}	string
+// @Failure		404		{object}	string
+// @Failure		500		{object}	string
+// @Router			/songs/{id}/revisions/diff [get]
+func (api *API) revisionsDiffHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Invalid song ID")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	from, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil {
+		logrus.WithError(err).Error("Invalid base revision")
+		http.Error(w, "invalid 'from' revision", http.StatusBadRequest)
+		return
+	}
+
+	to, err := strconv.Atoi(r.URL.Query().Get("to"))
+	if err != nil {
+		logrus.WithError(err).Error("Invalid target revision")
+		http.Error(w, "invalid 'to' revision", http.StatusBadRequest)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"id":   id,
+		"from": from,
+		"to":   to,
+	}).Info("Diffing song revisions")
+
+	diff, err := api.srv.LyricsDiff(r.Context(), id, from, to)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to diff song revisions")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(diff)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode diff to JSON")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// @Summary		Revert a song to a revision
+// @Description	Restore the song to the state saved in the revision. The revert is recorded as a new revision
+// @Tags			revisions
+// @Accept			json
+// @Produce		json
+// @Param			id	path		int	true	"Song ID"
+// @Param			rev	path		int	true	"Revision number"
+// @Success		200	{object}	models.Revision
+// @Success		204	"Song already matches the revision"
+// @Failure		400	{object}	string
+// @Failure		404	{object}	string
+// @Failure		500	{object}	string
+// @Router			/songs/{id}/revisions/{rev}/revert [post]
+func (api *API) revertSongHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Invalid song ID")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rev, err := strconv.Atoi(mux.Vars(r)["rev"])
+	if err != nil {
+		logrus.WithError(err).Error("Invalid revision number")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"id":       id,
+		"revision": rev,
+	}).Info("Reverting song")
+
+	revision, err := api.srv.RevertSong(r.Context(), id, rev)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to revert song")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	// Текущее состояние уже совпадает с ревизией — новая ревизия не создана
+	if revision.ID == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(revision)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode revision to JSON")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -1,7 +1,11 @@
 package api
 
 import (
+	"Anastasia/songs/internal/config"
+	"Anastasia/songs/internal/reqctx"
 	"Anastasia/songs/internal/services"
+	"expvar"
+	"net"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -11,12 +15,26 @@ import (
 type API struct {
 	srv    *services.Service
 	router *mux.Router
+
+	// Максимальное количество песен в одном пакетном запросе
+	maxBatchItems int
+	// Максимальный размер тела пакетного запроса
+	maxBatchBytes int64
+	// Максимальный размер импортируемого файла
+	maxImportBytes int64
+	// Адреса шлюза, которому разрешено передавать имя и роль пользователя в заголовках
+	trustedProxies []*net.IPNet
 }
 
 func New(srv *services.Service) *API {
 	api := &API{
 		srv:    srv,
 		router: mux.NewRouter(),
+
+		maxBatchItems:  config.Int("BATCH_MAX_ITEMS", 10000),
+		maxBatchBytes:  int64(config.Int("BATCH_MAX_BYTES", 32<<20)),
+		maxImportBytes: int64(config.Int("IMPORT_MAX_BYTES", 32<<20)),
+		trustedProxies: parseNetworks(config.String("TRUSTED_PROXIES", "127.0.0.1,::1")),
 	}
 
 	api.endpoints()
@@ -28,10 +46,40 @@ func (api *API) Router() *mux.Router {
 }
 
 func (api *API) endpoints() {
+	api.router.Use(requestIDMiddleware, identityMiddleware(api.trustedProxies), api.songRedirectMiddleware)
 	api.router.HandleFunc("/songs", api.songsHandler).Methods(http.MethodGet, http.MethodOptions)
+	api.router.HandleFunc("/songs/export", api.exportSongsHandler).Methods(http.MethodGet, http.MethodOptions)
 	api.router.HandleFunc("/songs/{id}", api.songByIDHandler).Methods(http.MethodGet, http.MethodOptions)
 	api.router.HandleFunc("/songs/{id}", api.deleteSongHandler).Methods(http.MethodDelete, http.MethodOptions)
 	api.router.HandleFunc("/songs/{id}", api.updateSongHandler).Methods(http.MethodPatch, http.MethodOptions)
 	api.router.HandleFunc("/songs", api.createSongHandler).Methods(http.MethodPost, http.MethodOptions)
+	api.router.HandleFunc("/songs:batch", api.batchCreateSongsHandler).Methods(http.MethodPost, http.MethodOptions)
+	api.router.HandleFunc("/songs/import", api.importPlaylistHandler).Methods(http.MethodPost, http.MethodOptions)
+	api.router.HandleFunc("/songs/preview", api.previewSongHandler).Methods(http.MethodPost, http.MethodOptions)
+	api.router.HandleFunc("/songs/{id}/merge", requireRole(reqctx.RoleAdmin, api.mergeSongHandler)).Methods(http.MethodPost, http.MethodOptions)
+	api.router.HandleFunc("/songs/{id}/revisions", api.revisionsHandler).Methods(http.MethodGet, http.MethodOptions)
+	api.router.HandleFunc("/songs/{id}/revisions/diff", api.revisionsDiffHandler).Methods(http.MethodGet, http.MethodOptions)
+	api.router.HandleFunc("/songs/{id}/revisions/{rev}/revert", api.revertSongHandler).Methods(http.MethodPost, http.MethodOptions)
+	api.router.HandleFunc("/songs/{id}/lyrics", api.lyricsHandler).Methods(http.MethodGet, http.MethodOptions)
+	api.router.HandleFunc("/songs/{id}/lyrics/synced", api.syncedLyricsHandler).Methods(http.MethodGet, http.MethodOptions)
+	api.router.HandleFunc("/songs/{id}/lyrics/synced", api.saveSyncedLyricsHandler).Methods(http.MethodPut, http.MethodOptions)
+	api.router.HandleFunc("/songs/{id}/lyrics/synced", api.deleteSyncedLyricsHandler).Methods(http.MethodDelete, http.MethodOptions)
+	api.router.HandleFunc("/songs/{id}/similar-lyrics", api.similarLyricsHandler).Methods(http.MethodGet, http.MethodOptions)
+	api.router.HandleFunc("/songs/{id}/lyrics/analysis", api.lyricsAnalysisHandler).Methods(http.MethodGet, http.MethodOptions)
+	api.router.HandleFunc("/songs/{id}/lyrics/stats", api.songLyricsStatsHandler).Methods(http.MethodGet, http.MethodOptions)
+	api.router.HandleFunc("/groups/{group}/lyrics/stats", api.groupLyricsStatsHandler).Methods(http.MethodGet, http.MethodOptions)
+	api.router.HandleFunc("/songs/{id}/chords", api.chordsHandler).Methods(http.MethodGet, http.MethodOptions)
+	api.router.HandleFunc("/songs/{id}/chords", api.saveChordsHandler).Methods(http.MethodPut, http.MethodOptions)
+	api.router.HandleFunc("/songs/{id}/chords", api.deleteChordsHandler).Methods(http.MethodDelete, http.MethodOptions)
+	api.router.HandleFunc("/songs/{id}/lyrics/bilingual", api.bilingualLyricsHandler).Methods(http.MethodGet, http.MethodOptions)
+	api.router.HandleFunc("/songs/{id}/translations", api.translationsHandler).Methods(http.MethodGet, http.MethodOptions)
+	api.router.HandleFunc("/songs/{id}/translations/{lang}", api.translationHandler).Methods(http.MethodGet, http.MethodOptions)
+	api.router.HandleFunc("/songs/{id}/translations/{lang}", api.saveTranslationHandler).Methods(http.MethodPut, http.MethodOptions)
+	api.router.HandleFunc("/songs/{id}/translations/{lang}", api.deleteTranslationHandler).Methods(http.MethodDelete, http.MethodOptions)
+	api.router.HandleFunc("/songs/{id}/provenance", api.provenanceHandler).Methods(http.MethodGet, http.MethodOptions)
+	api.router.HandleFunc("/audit", requireRole(reqctx.RoleAdmin, api.auditHandler)).Methods(http.MethodGet, http.MethodOptions)
+	api.router.HandleFunc("/enrichment/runs", requireRole(reqctx.RoleAdmin, api.startEnrichmentRunHandler)).Methods(http.MethodPost, http.MethodOptions)
+	api.router.HandleFunc("/enrichment/runs/{id}", requireRole(reqctx.RoleAdmin, api.enrichmentRunHandler)).Methods(http.MethodGet, http.MethodOptions)
+	api.router.HandleFunc("/debug/vars", requireRole(reqctx.RoleAdmin, expvar.Handler().ServeHTTP)).Methods(http.MethodGet)
 	api.router.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 }
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// Параметры поиска похожих текстов по умолчанию
+const (
+	defaultMinSimilarity = 0.5
+	defaultSimilarLimit  = 10
+	maxSimilarLimit      = 100
+)
+
+// @Summary		Find songs with similar lyrics
+// @Description	Find near-duplicate uploads and covers with slightly different lyrics. Similarity is the Jaccard
+// @Description	similarity of three-word shingles of the normalized lyrics, estimated with MinHash signatures
+// @Description	computed on write. Candidates are found with LSH, so pairs below 0.5 may be missed
+// @Tags			songs
+// @Produce		json
+// @Param			id		path		int		true	"Song ID"
+// @Param			min		query		number	false	"Minimum similarity from 0 to 1 (default 0.5)"
+// @Param			limit	query		int		false	"Maximum number of songs (default 10, max 100)"
+// @Success		200		{array}		models.SimilarSong
+// @Failure		400		{object}	string
+// @Failure		404		{object}	string
+// @Failure		500		{object}	string
+// @Router			/songs/{id}/similar-lyrics [get]
+func (api *API) similarLyricsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Invalid song ID")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	minSimilarity := defaultMinSimilarity
+	if value := r.URL.Query().Get("min"); value != "" {
+		minSimilarity, err = strconv.ParseFloat(value, 64)
+		if err != nil || minSimilarity < 0 || minSimilarity > 1 {
+			http.Error(w, "min must be a number from 0 to 1", http.StatusBadRequest)
+			return
+		}
+	}
+	limit := defaultSimilarLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxSimilarLimit {
+			http.Error(w, "limit must be an integer from 1 to "+strconv.Itoa(maxSimilarLimit), http.StatusBadRequest)
+			return
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{"id": id, "min": minSimilarity}).Info("Fetching songs with similar lyrics")
+
+	similar, err := api.srv.Similarity.SimilarLyrics(r.Context(), id, minSimilarity, limit)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch songs with similar lyrics")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(similar)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode similar songs to JSON")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// Количество самых частых слов по умолчанию и наибольшее
+const (
+	defaultTopWords = 20
+	maxTopWords     = 100
+)
+
+// @Summary		Get lyrics statistics of a song
+// @Description	Get word count, unique words, lexical diversity (unique/total words), repetition ratio
+// @Description	(share of lines repeating an earlier line), average line length and the most frequent words
+// @Description	excluding Russian and English stopwords. Results are cached and recomputed when the lyrics change
+// @Tags			stats
+// @Produce		json
+// @Param			id	path		int	true	"Song ID"
+// @Param			top	query		int	false	"Number of most frequent words (default 20, max 100)"
+// @Success		200	{object}	models.SongLyricsStats
+// @Failure		400	{object}	string
+// @Failure		404	{object}	string
+// @Failure		500	{object}	string
+// @Router			/songs/{id}/lyrics/stats [get]
+func (api *API) songLyricsStatsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Invalid song ID")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	top, ok := topWords(w, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	logrus.WithField("id", id).Info("Fetching song lyrics stats")
+
+	stats, err := api.srv.Stats.SongStats(r.Context(), id, top)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch song lyrics stats")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(stats)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode lyrics stats to JSON")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// @Summary		Get lyrics statistics of a group
+// @Description	Get the lyrics metrics of all songs of the group combined. Repetition is counted within each song
+// @Tags			stats
+// @Produce		json
+// @Param			group	path		string	true	"Group name"
+// @Param			top		query		int		false	"Number of most frequent words (default 20, max 100)"
+// @Success		200		{object}	models.GroupLyricsStats
+// @Failure		400		{object}	string
+// @Failure		404		{object}	string
+// @Failure		500		{object}	string
+// @Router			/groups/{group}/lyrics/stats [get]
+func (api *API) groupLyricsStatsHandler(w http.ResponseWriter, r *http.Request) {
+	group := mux.Vars(r)["group"]
+	top, ok := topWords(w, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	logrus.WithField("group", group).Info("Fetching group lyrics stats")
+
+	stats, err := api.srv.Stats.GroupStats(r.Context(), group, top)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch group lyrics stats")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(stats)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode lyrics stats to JSON")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Параметр top; при некорректном значении отвечает 400 и возвращает false
+func topWords(w http.ResponseWriter, query url.Values) (int, bool) {
+	value := query.Get("top")
+	if value == "" {
+		return defaultTopWords, true
+	}
+	top, err := strconv.Atoi(value)
+	if err != nil || top < 0 || top > maxTopWords {
+		http.Error(w, "top must be an integer from 0 to "+strconv.Itoa(maxTopWords), http.StatusBadRequest)
+		return 0, false
+	}
+	return top, true
+}
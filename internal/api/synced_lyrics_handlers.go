@@ -0,0 +1,164 @@
+package api
+
+import (
+	"Anastasia/songs/internal/lyrics"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// Максимальный размер загружаемого LRC
+const maxLRCBytes = 1 << 20
+
+// Форматы синхронизированного текста: тип содержимого по параметру format
+var syncedFormats = map[string]string{
+	"lrc":  "text/x-lrc",
+	"srt":  "application/x-subrip",
+	"vtt":  "text/vtt",
+	"json": "application/json",
+}
+
+// Типы содержимого синхронизированного текста в порядке предпочтения, включая синонимы
+var syncedContentTypes = []string{
+	"text/x-lrc", "application/x-lrc",
+	"application/x-subrip", "text/srt",
+	"text/vtt",
+	"application/json",
+}
+
+// @Summary		Get synced lyrics
+// @Description	Get time-synced lyrics as LRC, SubRip or WebVTT, chosen by the Accept header
+// @Description	(text/x-lrc, application/x-subrip, text/vtt, application/json) or the format parameter.
+// @Description	Word-level timestamps of enhanced LRC are kept in LRC and WebVTT
+// @Tags			lyrics
+// @Produce		text/x-lrc
+// @Produce		application/x-subrip
+// @Produce		text/vtt
+// @Produce		json
+// @Param			id		path		int		true	"Song ID"
+// @Param			format	query		string	false	"lrc, srt, vtt or json (overrides Accept, other values give 400)"
+// @Success		200		{object}	models.SyncedLyrics
+// @Failure		400		{object}	string
+// @Failure		404		{object}	string
+// @Failure		406		{object}	string
+// @Failure		500		{object}	string
+// @Router			/songs/{id}/lyrics/synced [get]
+func (api *API) syncedLyricsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Invalid song ID")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	contentType, ok := formatContentType(w, r, syncedFormats, syncedContentTypes...)
+	if !ok {
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{"id": id, "contentType": contentType}).Info("Fetching synced lyrics")
+
+	synced, err := api.srv.Lyrics.SyncedLyrics(r.Context(), id)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch synced lyrics")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	switch contentType {
+	case "text/x-lrc", "application/x-lrc":
+		_, err = io.WriteString(w, lyrics.FormatLRC(synced))
+	case "application/x-subrip", "text/srt":
+		_, err = io.WriteString(w, lyrics.FormatSRT(synced))
+	case "text/vtt":
+		_, err = io.WriteString(w, lyrics.FormatVTT(synced))
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(synced)
+	}
+	if err != nil {
+		logrus.WithError(err).Error("Failed to write synced lyrics")
+	}
+}
+
+// @Summary		Upload synced lyrics
+// @Description	Store time-synced lyrics in LRC format (enhanced word timestamps are supported).
+// @Description	The LRC is validated, problems are reported by line number. The plain song text is replaced
+// @Description	with the text derived from the LRC. Stored chords are kept while their lyrics match the new text;
+// @Description	editing the plain text later drops the synced lyrics (recorded in the audit log)
+// @Tags			lyrics
+// @Accept			text/x-lrc
+// @Produce		json
+// @Param			id	path		int		true	"Song ID"
+// @Param			lrc	body		string	true	"Lyrics in LRC format"
+// @Success		200	{object}	models.SyncedLyrics
+// @Failure		400	{object}	string
+// @Failure		404	{object}	string
+// @Failure		413	{object}	string
+// @Failure		500	{object}	string
+// @Router			/songs/{id}/lyrics/synced [put]
+func (api *API) saveSyncedLyricsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Invalid song ID")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	defer r.Body.Close()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxLRCBytes))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to read synced lyrics")
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	logrus.WithField("id", id).Info("Saving synced lyrics")
+
+	synced, err := api.srv.Lyrics.SaveSyncedLyrics(r.Context(), id, string(body))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to save synced lyrics")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(synced)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode synced lyrics to JSON")
+	}
+}
+
+// @Summary		Delete synced lyrics
+// @Description	Delete the time-synced lyrics of the song; the plain text is kept
+// @Tags			lyrics
+// @Param			id	path	int	true	"Song ID"
+// @Success		204	"No Content"
+// @Failure		400	{object}	string
+// @Failure		404	{object}	string
+// @Failure		500	{object}	string
+// @Router			/songs/{id}/lyrics/synced [delete]
+func (api *API) deleteSyncedLyricsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Invalid song ID")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logrus.WithField("id", id).Info("Deleting synced lyrics")
+
+	err = api.srv.Lyrics.DeleteSyncedLyrics(r.Context(), id)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to delete synced lyrics")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,236 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// Максимальный размер тела запроса с переводом
+const maxTranslationBytes = 1 << 20
+
+// @Summary		List lyrics translations
+// @Description	Get the languages the song lyrics are translated to. Texts are omitted;
+// @Description	outdated is true when the original lyrics changed after the translation was saved
+// @Tags			translations
+// @Produce		json
+// @Param			id	path		int	true	"Song ID"
+// @Success		200	{array}		models.Translation
+// @Failure		400	{object}	string
+// @Failure		404	{object}	string
+// @Failure		500	{object}	string
+// @Router			/songs/{id}/translations [get]
+func (api *API) translationsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Invalid song ID")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logrus.WithField("id", id).Info("Fetching song translations")
+
+	translations, err := api.srv.Translations.Translations(r.Context(), id)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch song translations")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(translations)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode translations to JSON")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// @Summary		Get a lyrics translation
+// @Description	Get the song lyrics translated to the language
+// @Tags			translations
+// @Produce		json
+// @Param			id		path		int		true	"Song ID"
+// @Param			lang	path		string	true	"Language code, e.g. en or en-GB"
+// @Success		200		{object}	models.Translation
+// @Failure		400		{object}	string
+// @Failure		404		{object}	string
+// @Failure		500		{object}	string
+// @Router			/songs/{id}/translations/{lang} [get]
+func (api *API) translationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Invalid song ID")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	lang := mux.Vars(r)["lang"]
+
+	logrus.WithFields(logrus.Fields{"id": id, "lang": lang}).Info("Fetching song translation")
+
+	tr, err := api.srv.Translations.Translation(r.Context(), id, lang)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch song translation")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Language", tr.Language)
+	err = json.NewEncoder(w).Encode(tr)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode translation to JSON")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Тело запроса на сохранение перевода
+type translationRequest struct {
+	Text string `json:"text"`
+}
+
+// @Summary		Add or update a lyrics translation
+// @Description	Store the translation of the song lyrics. The translation must follow the structure of the original:
+// @Description	the same sections (blocks separated by blank lines, section markers may be translated) with the same
+// @Description	number of lines in each, so that lines can be shown side by side. The text is limited
+// @Description	to 20000 characters like the song lyrics
+// @Tags			translations
+// @Accept			json
+// @Produce		json
+// @Param			id			path		int					true	"Song ID"
+// @Param			lang		path		string				true	"Language code, e.g. en or en-GB"
+// @Param			translation	body		translationRequest	true	"Translated lyrics"
+// @Success		200			{object}	models.Translation
+// @Failure		400			{object}	string
+// @Failure		404			{object}	string
+// @Failure		413			{object}	string
+// @Failure		500			{object}	string
+// @Router			/songs/{id}/translations/{lang} [put]
+func (api *API) saveTranslationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Invalid song ID")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	lang := mux.Vars(r)["lang"]
+
+	var req translationRequest
+	err = json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTranslationBytes)).Decode(&req)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to decode translation")
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	defer r.Body.Close()
+
+	logrus.WithFields(logrus.Fields{"id": id, "lang": lang}).Info("Saving song translation")
+
+	tr, err := api.srv.Translations.SaveTranslation(r.Context(), id, lang, req.Text)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to save song translation")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(tr)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode translation to JSON")
+	}
+}
+
+// @Summary		Delete a lyrics translation
+// @Description	Delete the translation of the song lyrics to the language
+// @Tags			translations
+// @Param			id		path	int		true	"Song ID"
+// @Param			lang	path	string	true	"Language code"
+// @Success		204		"No Content"
+// @Failure		400		{object}	string
+// @Failure		404		{object}	string
+// @Failure		500		{object}	string
+// @Router			/songs/{id}/translations/{lang} [delete]
+func (api *API) deleteTranslationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Invalid song ID")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	lang := mux.Vars(r)["lang"]
+
+	logrus.WithFields(logrus.Fields{"id": id, "lang": lang}).Info("Deleting song translation")
+
+	err = api.srv.Translations.DeleteTranslation(r.Context(), id, lang)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to delete song translation")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary		Get bilingual lyrics
+// @Description	Get the lyrics with the translation interleaved line by line. The language is taken from
+// @Description	the lang parameter (comma-separated list in order of preference) or the Accept-Language header;
+// @Description	en-US matches an en translation and vice versa. Besides JSON the lyrics are rendered as plain text,
+// @Description	an HTML fragment or Markdown with the translation under each line, chosen by the Accept header
+// @Tags			translations
+// @Produce		json
+// @Produce		plain
+// @Produce		html
+// @Produce		text/markdown
+// @Param			id				path		int		true	"Song ID"
+// @Param			lang			query		string	false	"Translation language(s), e.g. en or en,de"
+// @Param			Accept-Language	header		string	false	"Preferred languages"
+// @Success		200				{object}	models.BilingualLyrics
+// @Failure		400				{object}	string
+// @Failure		404				{object}	string
+// @Failure		406				{object}	string
+// @Failure		500				{object}	string
+// @Router			/songs/{id}/lyrics/bilingual [get]
+func (api *API) bilingualLyricsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		logrus.WithError(err).Error("Invalid song ID")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	contentType, ok := lyricsContentType(w, r)
+	if !ok {
+		return
+	}
+
+	languages := acceptLanguages(r)
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		languages = strings.Split(lang, ",")
+	}
+	if len(languages) == 0 {
+		http.Error(w, "Specify the translation language with lang or Accept-Language", http.StatusBadRequest)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{"id": id, "languages": languages}).Info("Fetching bilingual lyrics")
+
+	bilingual, err := api.srv.Translations.Bilingual(r.Context(), id, languages)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch bilingual lyrics")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Add("Vary", "Accept-Language")
+	w.Header().Set("Content-Language", bilingual.Language)
+	err = writeLyrics(w, contentType, bilingual, bilingualDocument(bilingual))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to write bilingual lyrics")
+	}
+}
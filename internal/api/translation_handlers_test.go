@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestSaveTranslationLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"invalid json", `{"text":`, http.StatusBadRequest},
+		{"body too large", `{"text":"` + strings.Repeat("a", maxTranslationBytes) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/songs/1/translations/en", strings.NewReader(tt.body))
+			r = mux.SetURLVars(r, map[string]string{"id": "1", "lang": "en"})
+			rec := httptest.NewRecorder()
+			(&API{}).saveTranslationHandler(rec, r)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+}
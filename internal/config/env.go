@@ -0,0 +1,46 @@
+// Пакет config содержит помощники для чтения настроек из переменных окружения
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Строковое значение переменной или def, если переменная не задана
+func String(key, def string) string {
+	if s := os.Getenv(key); s != "" {
+		return s
+	}
+	return def
+}
+
+// Целочисленное значение переменной или def, если переменная не задана или некорректна
+func Int(key string, def int) int {
+	s := os.Getenv(key)
+	if s == "" {
+		return def
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		logrus.WithError(err).WithField("key", key).Warn("Invalid number, using default")
+		return def
+	}
+	return n
+}
+
+// Длительность (в формате time.ParseDuration) или def, если переменная не задана или некорректна
+func Duration(key string, def time.Duration) time.Duration {
+	s := os.Getenv(key)
+	if s == "" {
+		return def
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		logrus.WithError(err).WithField("key", key).Warn("Invalid duration, using default")
+		return def
+	}
+	return d
+}
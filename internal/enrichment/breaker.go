@@ -0,0 +1,79 @@
+package enrichment
+
+import (
+	"sync"
+	"time"
+)
+
+// Состояния предохранителя
+const (
+	stateClosed   = "closed"
+	stateOpen     = "open"
+	stateHalfOpen = "half-open"
+)
+
+// Предохранитель: после threshold неудач подряд отклоняет запросы на время cooldown,
+// затем пропускает одну пробную попытку
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     string
+	failures  int
+	openedAt  time.Time
+	now       func() time.Time
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     stateClosed,
+		now:       time.Now,
+	}
+}
+
+// Разрешает или отклоняет очередную попытку
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == stateClosed {
+		return true
+	}
+
+	// В полуоткрытом состоянии пробная попытка уже выполняется. Если она так и не завершилась
+	// (например, запрос отменили), по истечении cooldown разрешается следующая
+	if b.now().Sub(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.state = stateHalfOpen
+	b.openedAt = b.now()
+	return true
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.state = stateClosed
+}
+
+func (b *breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == stateHalfOpen || (b.threshold > 0 && b.failures >= b.threshold) {
+		b.state = stateOpen
+		b.openedAt = b.now()
+	}
+}
+
+func (b *breaker) currentState() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
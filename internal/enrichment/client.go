@@ -0,0 +1,189 @@
+// Пакет enrichment реализует отказоустойчивый клиент внешнего сервиса с информацией о песнях:
+// таймауты, повторы с экспоненциальной задержкой, предохранитель, ограничение размера ответа и метрики
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// Внешний сервис недоступен или ответил ошибкой
+	ErrUpstream = errors.New("external API request failed")
+	// Предохранитель разомкнут, запрос не отправлялся
+	ErrCircuitOpen = fmt.Errorf("%w: circuit breaker is open", ErrUpstream)
+	// Ответ превышает допустимый размер
+	ErrTooLarge = fmt.Errorf("%w: response body too large", ErrUpstream)
+)
+
+// Ответ внешнего сервиса с неуспешным HTTP-статусом
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("external API returned status %d", e.Code)
+}
+
+func (e *StatusError) Unwrap() error {
+	return ErrUpstream
+}
+
+type Client struct {
+	cfg     Config
+	http    *http.Client
+	breaker *breaker
+	metrics *expvar.Map
+}
+
+// Создаёт новый клиент внешнего сервиса
+func New(cfg Config) *Client {
+	c := &Client{
+		cfg:     cfg,
+		http:    &http.Client{},
+		breaker: newBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+		metrics: clientMetrics(cfg.Name),
+	}
+	c.metrics.Set("breaker_state", breakerStateFunc(c.breaker.currentState))
+	return c
+}
+
+// Запрос информации о песне. Возвращает тело успешного ответа
+func (c *Client) Lookup(ctx context.Context, group, song string) ([]byte, error) {
+	apiURL := c.cfg.URL + fmt.Sprintf("?group=%s&song=%s", url.QueryEscape(group), url.QueryEscape(song))
+	c.metrics.Add("requests", 1)
+
+	var err error
+	for attempt := 0; attempt <= c.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			c.metrics.Add("retries", 1)
+			if err := sleep(ctx, c.backoff(attempt)); err != nil {
+				return nil, err
+			}
+		}
+
+		if !c.breaker.allow() {
+			c.metrics.Add("breaker_rejections", 1)
+			logrus.WithField("apiURL", apiURL).Warn("External API circuit breaker is open")
+			return nil, ErrCircuitOpen
+		}
+
+		var body []byte
+		body, err = c.attempt(ctx, apiURL)
+		if err == nil {
+			c.breaker.success()
+			c.metrics.Add("successes", 1)
+			return body, nil
+		}
+
+		if ctx.Err() != nil {
+			// Запрос отменён вызывающей стороной
+			break
+		}
+
+		if !Retryable(err) {
+			// Ошибки клиента не говорят о неисправности внешнего сервиса
+			c.breaker.success()
+			break
+		}
+		c.breaker.failure()
+
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"apiURL":  apiURL,
+			"attempt": attempt + 1,
+		}).Warn("External API request failed")
+	}
+
+	c.metrics.Add("failures", 1)
+	return nil, err
+}
+
+func (c *Client) attempt(ctx context.Context, apiURL string) ([]byte, error) {
+	if c.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.WithField("apiURL", apiURL).Info("Requesting data from external API")
+
+	start := time.Now()
+	resp, err := c.http.Do(req)
+	c.metrics.AddFloat("latency_ms_total", float64(time.Since(start).Milliseconds()))
+	c.metrics.Add("attempts", 1)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUpstream, err)
+	}
+	defer resp.Body.Close()
+
+	logrus.WithField("status", resp.StatusCode).Info("Received response from external API")
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{Code: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, c.cfg.MaxBodyBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUpstream, err)
+	}
+	if int64(len(body)) > c.cfg.MaxBodyBytes {
+		return nil, ErrTooLarge
+	}
+
+	return body, nil
+}
+
+// Экспоненциальная задержка со случайным разбросом
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.cfg.BackoffBase << (attempt - 1)
+	if d <= 0 || d > c.cfg.BackoffMax {
+		d = c.cfg.BackoffMax
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// Повторяются только таймауты, сетевые ошибки и ответы 5xx
+func Retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code >= http.StatusInternalServerError
+	}
+	if errors.Is(err, ErrTooLarge) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	return errors.Is(err, ErrUpstream)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
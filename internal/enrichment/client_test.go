@@ -0,0 +1,184 @@
+package enrichment
+
+import (
+	"Anastasia/songs/internal/mockinfo"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Клиент заглушки без задержек между повторами; имя уникально для метрик каждого теста
+func testClient(t *testing.T, url string, change func(*Config)) *Client {
+	cfg := DefaultConfig()
+	cfg.Name = t.Name()
+	cfg.URL = url + "/info"
+	cfg.BackoffBase = time.Millisecond
+	cfg.BackoffMax = time.Millisecond
+	if change != nil {
+		change(&cfg)
+	}
+	return New(cfg)
+}
+
+func TestLookup(t *testing.T) {
+	server := mockinfo.NewTestServer(mockinfo.Options{})
+	defer server.Close()
+	c := testClient(t, server.URL, nil)
+
+	body, err := c.Lookup(context.Background(), "  русская НАРОДНАЯ", "Калинка")
+	if err != nil {
+		t.Fatalf("Lookup() error: %v", err)
+	}
+	var detail mockinfo.SongDetail
+	if err := json.Unmarshal(body, &detail); err != nil {
+		t.Fatalf("invalid response %s: %v", body, err)
+	}
+	if detail.ReleaseDate != "1860" || detail.Link == "" {
+		t.Errorf("detail = %+v", detail)
+	}
+}
+
+func TestLookupErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      mockinfo.Options
+		cfg       func(*Config)
+		song      string
+		wantErr   error
+		wantCode  int
+		retryable bool
+	}{
+		{
+			name: "retried 5xx succeeds",
+			opts: mockinfo.Options{FailFirst: 2},
+			cfg:  func(cfg *Config) { cfg.MaxRetries = 2 },
+		},
+		{
+			name:      "5xx after all retries",
+			opts:      mockinfo.Options{FailFirst: 3, ErrorStatus: http.StatusServiceUnavailable},
+			cfg:       func(cfg *Config) { cfg.MaxRetries = 2 },
+			wantCode:  http.StatusServiceUnavailable,
+			retryable: true,
+		},
+		{
+			name:     "4xx is not retried",
+			opts:     mockinfo.Options{FailFirst: 1, ErrorStatus: http.StatusTooManyRequests},
+			cfg:      func(cfg *Config) { cfg.MaxRetries = 2 },
+			wantCode: http.StatusTooManyRequests,
+		},
+		{
+			name:     "unknown song",
+			song:     "Нет такой песни",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "timeout",
+			opts: mockinfo.Options{Latency: 500 * time.Millisecond},
+			cfg: func(cfg *Config) {
+				cfg.Timeout = 20 * time.Millisecond
+				cfg.MaxRetries = 1
+			},
+			wantErr:   ErrUpstream,
+			retryable: true,
+		},
+		{
+			name:    "response too large",
+			cfg:     func(cfg *Config) { cfg.MaxBodyBytes = 16 },
+			wantErr: ErrTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockinfo.NewTestServer(tt.opts)
+			defer server.Close()
+			c := testClient(t, server.URL, tt.cfg)
+			song := tt.song
+			if song == "" {
+				song = "Калинка"
+			}
+
+			_, err := c.Lookup(context.Background(), "Русская народная", song)
+			var statusErr *StatusError
+			switch {
+			case tt.wantCode != 0:
+				if !errors.As(err, &statusErr) || statusErr.Code != tt.wantCode {
+					t.Fatalf("err = %v, want status %d", err, tt.wantCode)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil && Retryable(err) != tt.retryable {
+				t.Errorf("Retryable(%v) = %v, want %v", err, Retryable(err), tt.retryable)
+			}
+			// Ошибки клиента не размыкают предохранитель
+			if state := c.breaker.currentState(); state != stateClosed {
+				t.Errorf("breaker state = %s, want closed", state)
+			}
+		})
+	}
+}
+
+func TestLookupBreaker(t *testing.T) {
+	failing := mockinfo.NewTestServer(mockinfo.Options{ErrorRate: 1})
+	defer failing.Close()
+	healthy := mockinfo.NewTestServer(mockinfo.Options{})
+	defer healthy.Close()
+
+	c := testClient(t, failing.URL, func(cfg *Config) {
+		cfg.MaxRetries = 0
+		cfg.BreakerThreshold = 2
+		cfg.BreakerCooldown = time.Minute
+	})
+	now := time.Now()
+	c.breaker.now = func() time.Time { return now }
+	lookup := func() error {
+		_, err := c.Lookup(context.Background(), "Русская народная", "Калинка")
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := lookup(); errors.Is(err, ErrCircuitOpen) || !Retryable(err) {
+			t.Fatalf("attempt %d: err = %v, want a server error", i+1, err)
+		}
+	}
+	if state := c.breaker.currentState(); state != stateOpen {
+		t.Fatalf("breaker state = %s, want open", state)
+	}
+	if err := lookup(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+
+	// После cooldown пробная попытка к восстановившемуся сервису замыкает предохранитель
+	c.cfg.URL = healthy.URL + "/info"
+	now = now.Add(time.Minute)
+	if err := lookup(); err != nil {
+		t.Fatalf("probe after cooldown: %v", err)
+	}
+	if state := c.breaker.currentState(); state != stateClosed {
+		t.Errorf("breaker state = %s, want closed", state)
+	}
+}
+
+func TestLookupCanceled(t *testing.T) {
+	server := mockinfo.NewTestServer(mockinfo.Options{Latency: time.Second})
+	defer server.Close()
+	c := testClient(t, server.URL, func(cfg *Config) { cfg.MaxRetries = 3 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := c.Lookup(ctx, "Русская народная", "Калинка")
+	if err == nil || time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("Lookup() = %v after %s, want a quick cancellation", err, time.Since(start))
+	}
+	if state := c.breaker.currentState(); state != stateClosed {
+		t.Errorf("canceled request changed breaker state to %s", state)
+	}
+}
@@ -0,0 +1,85 @@
+package enrichment
+
+import (
+	"Anastasia/songs/internal/config"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Настройки клиента внешнего сервиса с информацией о песнях
+type Config struct {
+	// Имя источника данных (используется в метриках и как источник значений полей песни)
+	Name string
+	// Адрес метода /info
+	URL string
+	// Таймаут одной попытки запроса
+	Timeout time.Duration
+	// Количество повторов после неудачной попытки
+	MaxRetries int
+	// Начальная и максимальная задержка между повторами
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// Количество неудачных попыток подряд, после которого размыкается предохранитель
+	BreakerThreshold int
+	// Время, в течение которого разомкнутый предохранитель отклоняет запросы
+	BreakerCooldown time.Duration
+	// Максимальный размер тела ответа в байтах
+	MaxBodyBytes int64
+}
+
+// Настройки по умолчанию
+func DefaultConfig() Config {
+	return Config{
+		Name:             "info",
+		Timeout:          5 * time.Second,
+		MaxRetries:       2,
+		BackoffBase:      200 * time.Millisecond,
+		BackoffMax:       2 * time.Second,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+		MaxBodyBytes:     1 << 20,
+	}
+}
+
+// Чтение настроек из переменных окружения. Незаданные значения берутся из DefaultConfig
+func ConfigFromEnv() Config {
+	cfg := DefaultConfig()
+	cfg.URL = os.Getenv("EXTERNAL_API_URL")
+	cfg.Timeout = config.Duration("EXTERNAL_API_TIMEOUT", cfg.Timeout)
+	cfg.MaxRetries = config.Int("EXTERNAL_API_RETRIES", cfg.MaxRetries)
+	cfg.BackoffBase = config.Duration("EXTERNAL_API_BACKOFF", cfg.BackoffBase)
+	cfg.BackoffMax = config.Duration("EXTERNAL_API_BACKOFF_MAX", cfg.BackoffMax)
+	cfg.BreakerThreshold = config.Int("EXTERNAL_API_BREAKER_THRESHOLD", cfg.BreakerThreshold)
+	cfg.BreakerCooldown = config.Duration("EXTERNAL_API_BREAKER_COOLDOWN", cfg.BreakerCooldown)
+	cfg.MaxBodyBytes = int64(config.Int("EXTERNAL_API_MAX_BODY_BYTES", int(cfg.MaxBodyBytes)))
+	return cfg
+}
+
+// Настройки всех внешних источников данных. Список задаётся в ENRICHMENT_PROVIDERS
+// в виде "имя=адрес,имя=адрес"; если он пуст, используется единственный источник EXTERNAL_API_URL.
+// Остальные настройки у всех источников общие
+func ProvidersFromEnv() []Config {
+	base := ConfigFromEnv()
+
+	list := strings.TrimSpace(os.Getenv("ENRICHMENT_PROVIDERS"))
+	if list == "" {
+		return []Config{base}
+	}
+
+	var configs []Config
+	for _, item := range strings.Split(list, ",") {
+		name, url, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok || name == "" || url == "" {
+			logrus.WithField("provider", item).Warn("Invalid enrichment provider, skipping")
+			continue
+		}
+		cfg := base
+		cfg.Name = name
+		cfg.URL = url
+		configs = append(configs, cfg)
+	}
+	return configs
+}
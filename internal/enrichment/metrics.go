@@ -0,0 +1,22 @@
+package enrichment
+
+import "expvar"
+
+// Метрики клиентов публикуются через expvar (/debug/vars), отдельно для каждого клиента
+var metrics = expvar.NewMap("enrichment")
+
+// Метрики клиента с указанным именем
+func clientMetrics(name string) *expvar.Map {
+	if m, ok := metrics.Get(name).(*expvar.Map); ok {
+		return m
+	}
+	m := new(expvar.Map).Init()
+	metrics.Set(name, m)
+	return m
+}
+
+type breakerStateFunc func() string
+
+func (f breakerStateFunc) String() string {
+	return `"` + f() + `"`
+}
@@ -0,0 +1,169 @@
+// Пакет importer читает каталоги песен из файлов сторонних форматов
+package importer
+
+import (
+	"Anastasia/songs/internal/models"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Поля песни, которые можно заполнить из файла
+var songFields = []string{"group", "song", "releaseDate", "text", "link"}
+
+// Соответствие полей песни столбцам файла: поле → заголовок столбца
+type Mapping map[string]string
+
+// Соответствие по умолчанию: столбцы называются так же, как поля песни
+func DefaultMapping() Mapping {
+	mapping := Mapping{}
+	for _, field := range songFields {
+		mapping[field] = field
+	}
+	return mapping
+}
+
+// Чтение соответствия из JSON-файла вида {"group": "Artist", "song": "Title"}
+func LoadMapping(path string) (Mapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var mapping Mapping
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("mapping %s: %w", path, err)
+	}
+	return mapping, mapping.validate()
+}
+
+func (m Mapping) validate() error {
+	for field := range m {
+		if setter(field) == nil {
+			return fmt.Errorf("mapping: unknown song field %q, expected one of %s", field, strings.Join(songFields, ", "))
+		}
+	}
+	for _, field := range []string{"group", "song"} {
+		if m[field] == "" {
+			return fmt.Errorf("mapping: required field %q is not mapped", field)
+		}
+	}
+	return nil
+}
+
+// Запись файла и песня, собранная из неё
+type Row struct {
+	// Номер строки табличного файла, с которой начинается запись
+	Line int
+	// Путь к файлу, если каждая запись — отдельный файл
+	Path string
+	Song models.Songs
+}
+
+// Разделитель столбцов по расширению файла: табуляция для .tsv, иначе запятая
+func Delimiter(path string) rune {
+	if strings.EqualFold(filepath.Ext(path), ".tsv") {
+		return '\t'
+	}
+	return ','
+}
+
+// Чтение CSV/TSV с заголовком. Значения столбцов раскладываются по полям песни согласно mapping,
+// столбцы без соответствия игнорируются
+func ReadRows(r io.Reader, comma rune, mapping Mapping) ([]Row, error) {
+	if err := mapping.validate(); err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = comma == '\t'
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	// Индексы столбцов для каждого поля
+	indexes := map[string]int{}
+	var missing []string
+	for field, column := range mapping {
+		i, ok := columns[strings.ToLower(strings.TrimSpace(column))]
+		if !ok {
+			missing = append(missing, column)
+			continue
+		}
+		indexes[field] = i
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("columns not found in header: %s", strings.Join(missing, ", "))
+	}
+
+	var rows []Row
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if blank(record) {
+			continue
+		}
+
+		row := Row{Line: line}
+		for field, i := range indexes {
+			if i < len(record) {
+				*setter(field)(&row.Song) = strings.TrimSpace(record[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// Указатель на поле песни по его имени; nil для неизвестного поля
+func setter(field string) func(song *models.Songs) *string {
+	switch field {
+	case "group":
+		return func(song *models.Songs) *string { return &song.Group }
+	case "song":
+		return func(song *models.Songs) *string { return &song.Song }
+	case "releaseDate":
+		return func(song *models.Songs) *string { return &song.ReleaseDate }
+	case "text":
+		return func(song *models.Songs) *string { return &song.Text }
+	case "link":
+		return func(song *models.Songs) *string { return &song.Link }
+	}
+	return nil
+}
+
+func blank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
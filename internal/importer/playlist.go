@@ -0,0 +1,307 @@
+package importer
+
+import (
+	"Anastasia/songs/internal/models"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Форматы плейлистов
+const (
+	FormatM3U    = "m3u"
+	FormatXSPF   = "xspf"
+	FormatITunes = "itunes"
+)
+
+// Формат плейлиста по расширению файла; пустая строка, если расширение не известно
+func PlaylistFormat(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".m3u", ".m3u8":
+		return FormatM3U
+	case ".xspf":
+		return FormatXSPF
+	case ".xml", ".plist":
+		return FormatITunes
+	}
+	return ""
+}
+
+// Чтение записей плейлиста: M3U/M3U8 (с #EXTINF), XSPF или XML-выгрузки медиатеки iTunes/Music.
+// Если исполнитель и название не указаны явно, они берутся из имени файла вида «Исполнитель - Название»
+func ReadPlaylist(r io.Reader, format string) ([]models.PlaylistEntry, error) {
+	var entries []models.PlaylistEntry
+	var err error
+	switch format {
+	case FormatM3U:
+		entries, err = readM3U(r)
+	case FormatXSPF:
+		entries, err = readXSPF(r)
+	case FormatITunes:
+		entries, err = readITunes(r)
+	default:
+		return nil, fmt.Errorf("unsupported playlist format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", format, err)
+	}
+
+	for i := range entries {
+		entries[i].Position = i + 1
+		if entries[i].Group == "" || entries[i].Song == "" {
+			group, song := splitTrackName(locationName(entries[i].Location))
+			if entries[i].Group == "" {
+				entries[i].Group = group
+			}
+			if entries[i].Song == "" {
+				entries[i].Song = song
+			}
+		}
+	}
+	return entries, nil
+}
+
+// Чтение M3U: строка #EXTINF:длительность,Исполнитель - Название описывает следующий за ней путь
+func readM3U(r io.Reader) ([]models.PlaylistEntry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var entries []models.PlaylistEntry
+	var pending *models.PlaylistEntry
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+
+		switch {
+		case text == "":
+		case strings.HasPrefix(text, "#EXTINF:"):
+			group, song := splitTrackName(extinfTitle(text[len("#EXTINF:"):]))
+			pending = &models.PlaylistEntry{Group: group, Song: song}
+		case strings.HasPrefix(text, "#EXTART:") && pending != nil:
+			pending.Group = strings.TrimSpace(text[len("#EXTART:"):])
+		case strings.HasPrefix(text, "#"):
+		default:
+			entry := models.PlaylistEntry{}
+			if pending != nil {
+				entry = *pending
+				pending = nil
+			}
+			entry.Location = text
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Название из #EXTINF: всё после первой запятой вне кавычек (в атрибутах могут встречаться запятые)
+func extinfTitle(value string) string {
+	quoted := false
+	for i, c := range value {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			return strings.TrimSpace(value[i+1:])
+		}
+	}
+	return ""
+}
+
+// Чтение XSPF: элементы trackList/track с creator, title и location
+func readXSPF(r io.Reader) ([]models.PlaylistEntry, error) {
+	var playlist struct {
+		Tracks []struct {
+			Location []string `xml:"location"`
+			Creator  string   `xml:"creator"`
+			Title    string   `xml:"title"`
+		} `xml:"trackList>track"`
+	}
+	if err := xml.NewDecoder(r).Decode(&playlist); err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.PlaylistEntry, 0, len(playlist.Tracks))
+	for _, track := range playlist.Tracks {
+		entry := models.PlaylistEntry{
+			Group: strings.TrimSpace(track.Creator),
+			Song:  strings.TrimSpace(track.Title),
+		}
+		if len(track.Location) > 0 {
+			entry.Location = strings.TrimSpace(track.Location[0])
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Чтение XML-выгрузки медиатеки iTunes/Music: словарь Tracks с полями Artist, Name, Year и Location.
+// Записи упорядочиваются по Track ID
+func readITunes(r io.Reader) ([]models.PlaylistEntry, error) {
+	root, err := decodePlist(r)
+	if err != nil {
+		return nil, err
+	}
+	library, ok := root.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("plist root is not a dictionary")
+	}
+	tracks, ok := library["Tracks"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("plist has no Tracks dictionary")
+	}
+
+	ids := make([]string, 0, len(tracks))
+	for id := range tracks {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, errA := strconv.Atoi(ids[i])
+		b, errB := strconv.Atoi(ids[j])
+		if errA != nil || errB != nil {
+			return ids[i] < ids[j]
+		}
+		return a < b
+	})
+
+	entries := make([]models.PlaylistEntry, 0, len(tracks))
+	for _, id := range ids {
+		track, ok := tracks[id].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		entry := models.PlaylistEntry{
+			Group:    plistString(track, "Artist"),
+			Song:     plistString(track, "Name"),
+			Location: plistString(track, "Location"),
+		}
+		if entry.Group == "" {
+			entry.Group = plistString(track, "Album Artist")
+		}
+		if year, ok := track["Year"].(int64); ok && year > 0 {
+			entry.ReleaseDate = strconv.FormatInt(year, 10)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func plistString(dict map[string]interface{}, key string) string {
+	s, _ := dict[key].(string)
+	return strings.TrimSpace(s)
+}
+
+// Разбор XML plist в значения Go: dict — map[string]interface{}, array — []interface{},
+// integer — int64, real — float64, true/false — bool, остальные — string
+func decodePlist(r io.Reader) (interface{}, error) {
+	d := xml.NewDecoder(r)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local != "plist" {
+			return decodePlistValue(d, start, 0)
+		}
+	}
+}
+
+// Максимальная вложенность словарей и массивов plist; медиатека использует не больше четырёх уровней
+const maxPlistDepth = 32
+
+func decodePlistValue(d *xml.Decoder, start xml.StartElement, depth int) (interface{}, error) {
+	if depth > maxPlistDepth {
+		return nil, errors.New("plist is nested too deeply")
+	}
+	switch start.Name.Local {
+	case "dict":
+		dict := map[string]interface{}{}
+		var key string
+		for {
+			tok, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				if t.Name.Local == "key" {
+					if err := d.DecodeElement(&key, &t); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				value, err := decodePlistValue(d, t, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				dict[key] = value
+			case xml.EndElement:
+				return dict, nil
+			}
+		}
+	case "array":
+		var array []interface{}
+		for {
+			tok, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				value, err := decodePlistValue(d, t, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				array = append(array, value)
+			case xml.EndElement:
+				return array, nil
+			}
+		}
+	case "true", "false":
+		return start.Name.Local == "true", d.Skip()
+	}
+
+	var text string
+	if err := d.DecodeElement(&text, &start); err != nil {
+		return nil, err
+	}
+	switch start.Name.Local {
+	case "integer":
+		return strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+	case "real":
+		return strconv.ParseFloat(strings.TrimSpace(text), 64)
+	}
+	return text, nil
+}
+
+// Имя файла без расширения из пути или URL (file://...)
+func locationName(location string) string {
+	if location == "" {
+		return ""
+	}
+	if u, err := url.Parse(location); err == nil && u.Scheme != "" && len(u.Scheme) > 1 {
+		location = u.Path
+	}
+	name := path.Base(strings.ReplaceAll(location, "\\", "/"))
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+// Разделение «Исполнитель - Название»; без разделителя вся строка считается названием
+func splitTrackName(name string) (string, string) {
+	if group, song, ok := strings.Cut(name, " - "); ok {
+		return strings.TrimSpace(group), strings.TrimSpace(song)
+	}
+	return "", strings.TrimSpace(name)
+}
@@ -0,0 +1,152 @@
+package importer
+
+import (
+	"Anastasia/songs/internal/models"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadPlaylist(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		want   []models.PlaylistEntry
+	}{
+		{
+			"m3u",
+			FormatM3U,
+			"\ufeff#EXTM3U\r\n" +
+				"#EXTINF:354,Queen - Bohemian Rhapsody\r\n" +
+				"Music/Queen/01.mp3\r\n" +
+				"\r\n" +
+				"# comment\r\n" +
+				"Music/Muse - Uprising.mp3\r\n" +
+				"#EXTINF:-1 tvg-name=\"a, b\",Radio Song\r\n" +
+				"#EXTART:Some Band\r\n" +
+				"http://radio.example/stream\r\n",
+			[]models.PlaylistEntry{
+				{Position: 1, Location: "Music/Queen/01.mp3", Group: "Queen", Song: "Bohemian Rhapsody"},
+				{Position: 2, Location: "Music/Muse - Uprising.mp3", Group: "Muse", Song: "Uprising"},
+				{Position: 3, Location: "http://radio.example/stream", Group: "Some Band", Song: "Radio Song"},
+			},
+		},
+		{
+			"m3u with windows paths",
+			FormatM3U,
+			`C:\Music\Muse - Hysteria.flac`,
+			[]models.PlaylistEntry{
+				{Position: 1, Location: `C:\Music\Muse - Hysteria.flac`, Group: "Muse", Song: "Hysteria"},
+			},
+		},
+		{
+			"xspf",
+			FormatXSPF,
+			`<?xml version="1.0" encoding="UTF-8"?>
+<playlist version="1" xmlns="http://xspf.org/ns/0/">
+  <trackList>
+    <track>
+      <location>file:///music/Queen%20-%20Innuendo.mp3</location>
+      <location>http://mirror/innuendo.mp3</location>
+    </track>
+    <track>
+      <creator> Muse </creator>
+      <title>Starlight</title>
+    </track>
+  </trackList>
+</playlist>`,
+			[]models.PlaylistEntry{
+				{Position: 1, Location: "file:///music/Queen%20-%20Innuendo.mp3", Group: "Queen", Song: "Innuendo"},
+				{Position: 2, Group: "Muse", Song: "Starlight"},
+			},
+		},
+		{
+			"itunes",
+			FormatITunes,
+			`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+  <key>Major Version</key><integer>1</integer>
+  <key>Tracks</key>
+  <dict>
+    <key>10</key>
+    <dict>
+      <key>Track ID</key><integer>10</integer>
+      <key>Name</key><string>Starlight</string>
+      <key>Album Artist</key><string>Muse</string>
+      <key>Year</key><integer>2006</integer>
+      <key>Compilation</key><false/>
+      <key>Rating</key><real>0.8</real>
+    </dict>
+    <key>9</key>
+    <dict>
+      <key>Location</key><string>file:///Music/Queen%20-%20Innuendo.m4a</string>
+    </dict>
+  </dict>
+  <key>Playlists</key>
+  <array><dict><key>Name</key><string>Library</string></dict></array>
+</dict>
+</plist>`,
+			[]models.PlaylistEntry{
+				{Position: 1, Location: "file:///Music/Queen%20-%20Innuendo.m4a", Group: "Queen", Song: "Innuendo"},
+				{Position: 2, Group: "Muse", Song: "Starlight", ReleaseDate: "2006"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadPlaylist(strings.NewReader(tt.data), tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("entries = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadPlaylistErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		err    string
+	}{
+		{"unknown format", "pls", "", `unsupported playlist format "pls"`},
+		{"m3u line too long", FormatM3U, strings.Repeat("a", 2*1024*1024), "token too long"},
+		{"broken xspf", FormatXSPF, "<playlist><trackList><track>", "xspf: XML syntax error"},
+		{"plist root is an array", FormatITunes, "<plist><array/></plist>", "plist root is not a dictionary"},
+		{"plist without tracks", FormatITunes, "<plist><dict><key>Tracks</key><string/></dict></plist>", "no Tracks dictionary"},
+		{"invalid integer", FormatITunes, "<plist><dict><key>Year</key><integer>soon</integer></dict></plist>", "invalid syntax"},
+		{"nested too deeply", FormatITunes, "<plist>" + strings.Repeat("<array>", 1000), "nested too deeply"},
+		{"unterminated dict", FormatITunes, "<plist><dict><key>Tracks</key><dict>", "unexpected EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadPlaylist(strings.NewReader(tt.data), tt.format)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestPlaylistFormat(t *testing.T) {
+	tests := map[string]string{
+		"list.m3u":      FormatM3U,
+		"LIST.M3U8":     FormatM3U,
+		"list.xspf":     FormatXSPF,
+		"Library.xml":   FormatITunes,
+		"Library.plist": FormatITunes,
+		"list.pls":      "",
+		"no-extension":  "",
+	}
+	for file, want := range tests {
+		if got := PlaylistFormat(file); got != want {
+			t.Errorf("PlaylistFormat(%q) = %q, want %q", file, got, want)
+		}
+	}
+}
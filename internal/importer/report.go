@@ -0,0 +1,150 @@
+package importer
+
+import (
+	"Anastasia/songs/internal/lyrics"
+	"Anastasia/songs/internal/models"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Статус записи, которую не удалось прочитать из файла
+const StatusSkipped = "skipped"
+
+// Строка отчёта об импорте одной записи
+type Entry struct {
+	// Номер строки (для табличных файлов) или путь к файлу, откуда взята запись
+	Line    int
+	Path    string
+	Status  string
+	Message string
+}
+
+func (e Entry) String() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s: %s", e.location(), e.Status)
+	}
+	return fmt.Sprintf("%s: %s: %s", e.location(), e.Status, e.Message)
+}
+
+func (e Entry) location() string {
+	if e.Path != "" {
+		return e.Path
+	}
+	return fmt.Sprintf("line %d", e.Line)
+}
+
+// Проверка записей без записи в БД: validate вызывается для каждой песни
+func Validate(rows []Row, validate func(models.Songs) error) []Entry {
+	entries := make([]Entry, 0, len(rows))
+	for _, row := range rows {
+		entry := Entry{Line: row.Line, Path: row.Path, Status: "ok"}
+		if err := validate(row.Song); err != nil {
+			entry.Status = models.BatchInvalid
+			entry.Message = err.Error()
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Отметка прошедших проверку записей, которые повторяют более раннюю запись (по нормализованным
+// группе и названию): при записи в БД они будут пропущены как дубликаты. entries — результат Validate для rows
+func MarkDuplicates(rows []Row, entries []Entry) {
+	seen := map[string]int{}
+	for i, row := range rows {
+		if entries[i].Status != "ok" {
+			continue
+		}
+		key := lyrics.NormalizeName(row.Song.Group) + "\x00" + lyrics.NormalizeName(row.Song.Song)
+		if first, ok := seen[key]; ok {
+			entries[i].Status = models.BatchDuplicate
+			entries[i].Message = "duplicates " + entries[first].location()
+			continue
+		}
+		seen[key] = i
+	}
+}
+
+// Итог по каждой записи после пакетного добавления; индексы элементов сопоставляются с записями
+func Outcomes(rows []Row, result models.BatchResult) []Entry {
+	entries := make([]Entry, 0, len(result.Items))
+	for _, item := range result.Items {
+		row := rows[item.Index]
+		entry := Entry{Line: row.Line, Path: row.Path, Status: item.Status, Message: item.Error}
+		switch {
+		case item.Status == models.BatchCreated:
+			entry.Message = fmt.Sprintf("id %d", item.ID)
+		case item.ExistingID != 0:
+			entry.Message = fmt.Sprintf("song already exists with id %d", item.ExistingID)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Итог по каждой записи импортированного плейлиста
+func PlaylistOutcomes(result models.PlaylistImport) []Entry {
+	entries := make([]Entry, 0, len(result.Entries))
+	for _, e := range result.Entries {
+		entry := Entry{Path: playlistLocation(e), Status: e.Status, Message: e.Error}
+		if e.ID != 0 {
+			entry.Message = fmt.Sprintf("%s - %s, id %d", e.Group, e.Song, e.ID)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Записи плейлиста в виде строк импорта (для проверки без записи в БД)
+func PlaylistRows(entries []models.PlaylistEntry) []Row {
+	rows := make([]Row, 0, len(entries))
+	for _, e := range entries {
+		rows = append(rows, Row{
+			Path: playlistLocation(e),
+			Song: models.Songs{Group: e.Group, Song: e.Song, ReleaseDate: e.ReleaseDate},
+		})
+	}
+	return rows
+}
+
+func playlistLocation(e models.PlaylistEntry) string {
+	if e.Location != "" {
+		return e.Location
+	}
+	return fmt.Sprintf("#%d", e.Position)
+}
+
+// Успешно ли обработана запись. Уже существующая песня пропускается и проблемой не считается
+func (e Entry) OK() bool {
+	switch e.Status {
+	case "ok", models.BatchCreated, models.BatchDuplicate, models.PlaylistMatched:
+		return true
+	}
+	return false
+}
+
+// Вывод отчёта: по строке на каждую проблемную или пропущенную как дубликат запись (с verbose — на каждую)
+// и итог по статусам. Возвращает количество проблемных записей
+func WriteReport(w io.Writer, entries []Entry, verbose bool) int {
+	counts := map[string]int{}
+	problems := 0
+	for _, e := range entries {
+		counts[e.Status]++
+		if !e.OK() {
+			problems++
+		}
+		if verbose || !e.OK() || e.Status == models.BatchDuplicate {
+			fmt.Fprintln(w, e)
+		}
+	}
+
+	statuses := make([]string, 0, len(counts))
+	for status, n := range counts {
+		statuses = append(statuses, fmt.Sprintf("%s %d", status, n))
+	}
+	sort.Strings(statuses)
+	fmt.Fprintf(w, "%d records: %s\n", len(entries), strings.Join(statuses, ", "))
+	return problems
+}
@@ -0,0 +1,77 @@
+package importer
+
+import (
+	"Anastasia/songs/internal/models"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateAndMarkDuplicates(t *testing.T) {
+	rows := []Row{
+		{Line: 2, Song: models.Songs{Group: "Muse", Song: "Uprising"}},
+		{Line: 3, Song: models.Songs{Group: "Queen", Song: ""}},
+		{Line: 4, Song: models.Songs{Group: " MUSE ", Song: "uprising"}},
+		{Line: 5, Song: models.Songs{Group: "Muse", Song: "Uprising  Live"}},
+		{Line: 6, Song: models.Songs{Group: "Queen", Song: ""}},
+		{Path: "b.mp3", Song: models.Songs{Group: "muse", Song: "UPRISING"}},
+	}
+	validate := func(song models.Songs) error {
+		if song.Song == "" {
+			return errors.New("song is required")
+		}
+		return nil
+	}
+
+	entries := Validate(rows, validate)
+	MarkDuplicates(rows, entries)
+	want := []Entry{
+		{Line: 2, Status: "ok"},
+		{Line: 3, Status: models.BatchInvalid, Message: "song is required"},
+		{Line: 4, Status: models.BatchDuplicate, Message: "duplicates line 2"},
+		{Line: 5, Status: "ok"},
+		{Line: 6, Status: models.BatchInvalid, Message: "song is required"},
+		{Path: "b.mp3", Status: models.BatchDuplicate, Message: "duplicates line 2"},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("entries = %+v, want %+v", entries, want)
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	entries := []Entry{
+		{Line: 2, Status: models.BatchCreated, Message: "id 1"},
+		{Line: 3, Status: models.BatchDuplicate, Message: "song already exists with id 7"},
+		{Line: 4, Status: models.BatchInvalid, Message: "song is required"},
+		{Path: "a.mp3", Status: StatusSkipped, Message: "no tags"},
+		{Path: "b.m3u", Status: models.PlaylistMatched},
+	}
+	tests := []struct {
+		name    string
+		verbose bool
+		want    string
+	}{
+		{"problems and duplicates", false, "line 3: duplicate: song already exists with id 7\n" +
+			"line 4: invalid: song is required\n" +
+			"a.mp3: skipped: no tags\n" +
+			"5 records: created 1, duplicate 1, invalid 1, matched 1, skipped 1\n"},
+		{"verbose", true, "line 2: created: id 1\n" +
+			"line 3: duplicate: song already exists with id 7\n" +
+			"line 4: invalid: song is required\n" +
+			"a.mp3: skipped: no tags\n" +
+			"b.m3u: matched\n" +
+			"5 records: created 1, duplicate 1, invalid 1, matched 1, skipped 1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if problems := WriteReport(&b, entries, tt.verbose); problems != 2 {
+				t.Errorf("problems = %d, want 2", problems)
+			}
+			if b.String() != tt.want {
+				t.Errorf("report = %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,512 @@
+package importer
+
+import (
+	"Anastasia/songs/internal/models"
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// Формат файла не поддерживается или файл не содержит тегов
+var ErrNoTags = errors.New("no supported tags found")
+
+// Расширения аудиофайлов, которые просматриваются при обходе каталога
+var audioExtensions = map[string]bool{
+	".mp3":  true,
+	".flac": true,
+	".ogg":  true,
+	".oga":  true,
+	".opus": true,
+}
+
+// Теги, из которых собирается песня
+const (
+	tagArtist      = "artist"
+	tagAlbumArtist = "albumartist"
+	tagTitle       = "title"
+	tagDate        = "date"
+	tagLyrics      = "lyrics"
+)
+
+// Соответствие кадров ID3v2 (2.2 и 2.3/2.4) тегам
+var id3Frames = map[string]string{
+	"TPE1": tagArtist, "TP1": tagArtist,
+	"TPE2": tagAlbumArtist, "TP2": tagAlbumArtist,
+	"TIT2": tagTitle, "TT2": tagTitle,
+	"TDRC": tagDate, "TYER": tagDate, "TYE": tagDate,
+	"USLT": tagLyrics, "ULT": tagLyrics,
+}
+
+// Соответствие полей Vorbis comment тегам
+var vorbisFields = map[string]string{
+	"ARTIST":         tagArtist,
+	"ALBUMARTIST":    tagAlbumArtist,
+	"ALBUM ARTIST":   tagAlbumArtist,
+	"TITLE":          tagTitle,
+	"DATE":           tagDate,
+	"YEAR":           tagDate,
+	"LYRICS":         tagLyrics,
+	"UNSYNCEDLYRICS": tagLyrics,
+}
+
+// Обход каталога и чтение тегов всех аудиофайлов. Файлы, теги которых прочитать не удалось,
+// возвращаются как записи отчёта со статусом StatusSkipped
+func ScanAudio(dir string) ([]Row, []Entry, error) {
+	var rows []Row
+	var skipped []Entry
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !audioExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		song, err := ReadTags(path)
+		if err != nil {
+			skipped = append(skipped, Entry{Path: path, Status: StatusSkipped, Message: err.Error()})
+			return nil
+		}
+		rows = append(rows, Row{Path: path, Song: song})
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Path < rows[j].Path })
+	return rows, skipped, nil
+}
+
+// Чтение тегов аудиофайла (ID3v2 в MP3, Vorbis comment во FLAC и OGG Vorbis/Opus).
+// Формат определяется по содержимому, а не по расширению
+func ReadTags(path string) (models.Songs, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return models.Songs{}, err
+	}
+	defer f.Close()
+
+	tags, err := readTags(bufio.NewReader(f))
+	if err != nil {
+		return models.Songs{}, err
+	}
+
+	song := models.Songs{
+		Group:       tags[tagArtist],
+		Song:        tags[tagTitle],
+		ReleaseDate: releaseDate(tags[tagDate]),
+		Text:        normalizeLyrics(tags[tagLyrics]),
+	}
+	if song.Group == "" {
+		song.Group = tags[tagAlbumArtist]
+	}
+	return song, nil
+}
+
+func readTags(r *bufio.Reader) (map[string]string, error) {
+	magic, err := r.Peek(4)
+	if err != nil {
+		return nil, ErrNoTags
+	}
+
+	var tags map[string]string
+	if bytes.HasPrefix(magic, []byte("ID3")) {
+		tags, err = readID3v2(r)
+		if err != nil {
+			return nil, err
+		}
+		// FLAC иногда начинается с ID3v2; тогда дальше идут собственные теги
+		if next, err := r.Peek(4); err == nil && string(next) == "fLaC" {
+			flacTags, err := readFLAC(r)
+			if err != nil {
+				return nil, err
+			}
+			for key, value := range flacTags {
+				tags[key] = value
+			}
+		}
+	} else {
+		switch string(magic) {
+		case "fLaC":
+			tags, err = readFLAC(r)
+		case "OggS":
+			tags, err = readOgg(r)
+		default:
+			return nil, ErrNoTags
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(tags) == 0 {
+		return nil, ErrNoTags
+	}
+	return tags, nil
+}
+
+// Чтение тега ID3v2 версий 2.2, 2.3 и 2.4
+func readID3v2(r io.Reader) (map[string]string, error) {
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("id3: %w", err)
+	}
+	version, flags := header[3], header[5]
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("id3: unsupported version 2.%d", version)
+	}
+
+	body, err := readBlock(r, syncsafe(header[6:10]))
+	if err != nil {
+		return nil, fmt.Errorf("id3: %w", err)
+	}
+	// Заголовок в конце тега (только 2.4) повторяет начальный
+	if flags&0x10 != 0 && version == 4 {
+		if _, err := io.ReadFull(r, make([]byte, 10)); err != nil {
+			return nil, fmt.Errorf("id3: %w", err)
+		}
+	}
+	// В 2.2 и 2.3 рассинхронизация применяется ко всему тегу
+	if flags&0x80 != 0 && version < 4 {
+		body = unsynchronise(body)
+	}
+	// Расширенный заголовок
+	if flags&0x40 != 0 && version > 2 && len(body) >= 4 {
+		size := int(binary.BigEndian.Uint32(body[:4])) + 4
+		if version == 4 {
+			size = syncsafe(body[:4])
+		}
+		if size > len(body) {
+			return nil, errors.New("id3: invalid extended header")
+		}
+		body = body[size:]
+	}
+
+	idLen, headerLen := 4, 10
+	if version == 2 {
+		idLen, headerLen = 3, 6
+	}
+
+	tags := map[string]string{}
+	for len(body) >= headerLen && body[0] != 0 {
+		id := string(body[:idLen])
+		var size int
+		var frameFlags uint16
+		switch version {
+		case 2:
+			size = int(body[3])<<16 | int(body[4])<<8 | int(body[5])
+		case 3:
+			size = int(binary.BigEndian.Uint32(body[4:8]))
+			frameFlags = binary.BigEndian.Uint16(body[8:10])
+		case 4:
+			size = syncsafe(body[4:8])
+			frameFlags = binary.BigEndian.Uint16(body[8:10])
+		}
+		if size > len(body)-headerLen {
+			return nil, fmt.Errorf("id3: frame %s is truncated", id)
+		}
+		data := body[headerLen : headerLen+size]
+		body = body[headerLen+size:]
+
+		tag, ok := id3Frames[id]
+		if !ok || tags[tag] != "" {
+			continue
+		}
+		data, ok = id3FrameData(version, frameFlags, data)
+		if !ok || len(data) == 0 {
+			continue
+		}
+
+		var value string
+		if tag == tagLyrics {
+			value = id3Lyrics(data)
+		} else {
+			value = id3Text(data)
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			tags[tag] = value
+		}
+	}
+	return tags, nil
+}
+
+// Содержимое кадра с учётом флагов формата. Сжатые и зашифрованные кадры пропускаются
+func id3FrameData(version byte, flags uint16, data []byte) ([]byte, bool) {
+	switch version {
+	case 3:
+		if flags&0x00C0 != 0 {
+			return nil, false
+		}
+		if flags&0x0020 != 0 && len(data) > 0 {
+			data = data[1:]
+		}
+	case 4:
+		if flags&0x000C != 0 {
+			return nil, false
+		}
+		if flags&0x0040 != 0 && len(data) > 0 {
+			data = data[1:]
+		}
+		if flags&0x0001 != 0 {
+			if len(data) < 4 {
+				return nil, false
+			}
+			data = data[4:]
+		}
+		if flags&0x0002 != 0 {
+			data = unsynchronise(data)
+		}
+	}
+	return data, true
+}
+
+// Значение текстового кадра: байт кодировки и текст. В 2.4 значений может быть несколько —
+// они разделены нулём, берётся первое
+func id3Text(data []byte) string {
+	text := decodeID3String(data[0], data[1:])
+	if i := strings.IndexByte(text, 0); i >= 0 {
+		text = text[:i]
+	}
+	return text
+}
+
+// Значение кадра USLT: байт кодировки, язык (3 байта), описание до нулевого символа и текст
+func id3Lyrics(data []byte) string {
+	if len(data) < 4 {
+		return ""
+	}
+	encoding, rest := data[0], data[4:]
+	_, text := splitTerminated(encoding, rest)
+	return decodeID3String(encoding, text)
+}
+
+// Разделение по первому завершающему нулю кодировки (один байт или два для UTF-16)
+func splitTerminated(encoding byte, data []byte) ([]byte, []byte) {
+	if encoding == 1 || encoding == 2 {
+		for i := 0; i+1 < len(data); i += 2 {
+			if data[i] == 0 && data[i+1] == 0 {
+				return data[:i], data[i+2:]
+			}
+		}
+		return data, nil
+	}
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return data[:i], data[i+1:]
+	}
+	return data, nil
+}
+
+// Декодирование строки ID3: 0 — ISO-8859-1, 1 — UTF-16 с BOM, 2 — UTF-16BE, 3 — UTF-8
+func decodeID3String(encoding byte, data []byte) string {
+	switch encoding {
+	case 0:
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	case 1:
+		if len(data) >= 2 && data[0] == 0xFF && data[1] == 0xFE {
+			return decodeUTF16(data[2:], binary.LittleEndian)
+		}
+		if len(data) >= 2 && data[0] == 0xFE && data[1] == 0xFF {
+			data = data[2:]
+		}
+		return decodeUTF16(data, binary.BigEndian)
+	case 2:
+		return decodeUTF16(data, binary.BigEndian)
+	default:
+		return string(data)
+	}
+}
+
+func decodeUTF16(data []byte, order binary.ByteOrder) string {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, order.Uint16(data[i:]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// Чтение блока указанного в заголовке размера. Память выделяется по мере чтения,
+// чтобы повреждённый заголовок короткого файла не приводил к большим выделениям
+func readBlock(r io.Reader, size int) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, int64(size)))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < size {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return data, nil
+}
+
+// Размер в формате syncsafe: по 7 значащих бит в байте
+func syncsafe(b []byte) int {
+	return int(b[0]&0x7F)<<21 | int(b[1]&0x7F)<<14 | int(b[2]&0x7F)<<7 | int(b[3]&0x7F)
+}
+
+// Отмена рассинхронизации: последовательность FF 00 заменяется на FF
+func unsynchronise(data []byte) []byte {
+	return bytes.ReplaceAll(data, []byte{0xFF, 0x00}, []byte{0xFF})
+}
+
+// Чтение блока VORBIS_COMMENT из метаданных FLAC; остальные блоки пропускаются
+func readFLAC(r *bufio.Reader) (map[string]string, error) {
+	if _, err := r.Discard(4); err != nil {
+		return nil, fmt.Errorf("flac: %w", err)
+	}
+
+	header := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, fmt.Errorf("flac: %w", err)
+		}
+		last, blockType := header[0]&0x80 != 0, header[0]&0x7F
+		size := int(header[1])<<16 | int(header[2])<<8 | int(header[3])
+
+		if blockType == 4 {
+			block, err := readBlock(r, size)
+			if err != nil {
+				return nil, fmt.Errorf("flac: %w", err)
+			}
+			return parseVorbisComment(block)
+		}
+		if _, err := r.Discard(size); err != nil {
+			return nil, fmt.Errorf("flac: %w", err)
+		}
+		if last {
+			return map[string]string{}, nil
+		}
+	}
+}
+
+// Максимальное количество страниц OGG, просматриваемых в поисках комментариев
+const maxOggPages = 64
+
+// Чтение комментариев из второго пакета потока OGG (Vorbis или Opus)
+func readOgg(r io.Reader) (map[string]string, error) {
+	var packet []byte
+	packets := 0
+	header := make([]byte, 27)
+
+	for page := 0; page < maxOggPages; page++ {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, fmt.Errorf("ogg: %w", err)
+		}
+		if string(header[:4]) != "OggS" {
+			return nil, errors.New("ogg: invalid page")
+		}
+		segments := make([]byte, header[26])
+		if _, err := io.ReadFull(r, segments); err != nil {
+			return nil, fmt.Errorf("ogg: %w", err)
+		}
+
+		for _, size := range segments {
+			data := make([]byte, size)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return nil, fmt.Errorf("ogg: %w", err)
+			}
+			packet = append(packet, data...)
+			// Сегмент короче 255 байт завершает пакет
+			if size == 255 {
+				continue
+			}
+			packets++
+			if packets == 2 {
+				return parseOggComment(packet)
+			}
+			packet = packet[:0]
+		}
+	}
+	return nil, errors.New("ogg: comment header not found")
+}
+
+func parseOggComment(packet []byte) (map[string]string, error) {
+	switch {
+	case bytes.HasPrefix(packet, []byte("\x03vorbis")):
+		return parseVorbisComment(packet[7:])
+	case bytes.HasPrefix(packet, []byte("OpusTags")):
+		return parseVorbisComment(packet[8:])
+	}
+	return nil, errors.New("ogg: unsupported codec")
+}
+
+// Разбор Vorbis comment: строка производителя и список полей "ИМЯ=значение" (длины little-endian)
+func parseVorbisComment(data []byte) (map[string]string, error) {
+	errInvalid := errors.New("vorbis comment: invalid block")
+	next := func() ([]byte, bool) {
+		if len(data) < 4 {
+			return nil, false
+		}
+		size := int(binary.LittleEndian.Uint32(data))
+		if size > len(data)-4 {
+			return nil, false
+		}
+		value := data[4 : 4+size]
+		data = data[4+size:]
+		return value, true
+	}
+
+	if _, ok := next(); !ok {
+		return nil, errInvalid
+	}
+	if len(data) < 4 {
+		return nil, errInvalid
+	}
+	count := int(binary.LittleEndian.Uint32(data))
+	data = data[4:]
+
+	tags := map[string]string{}
+	for i := 0; i < count; i++ {
+		field, ok := next()
+		if !ok {
+			return nil, errInvalid
+		}
+		name, value, found := strings.Cut(string(field), "=")
+		if !found {
+			continue
+		}
+		tag, ok := vorbisFields[strings.ToUpper(name)]
+		if !ok || tags[tag] != "" {
+			continue
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			tags[tag] = value
+		}
+	}
+	return tags, nil
+}
+
+// Дата выхода из тега в формате библиотеки: ДД.ММ.ГГГГ для полной даты, иначе только год
+func releaseDate(value string) string {
+	if len(value) >= 10 {
+		if t, err := time.Parse("2006-01-02", value[:10]); err == nil {
+			return t.Format("02.01.2006")
+		}
+	}
+	if len(value) >= 4 {
+		if _, err := time.Parse("2006", value[:4]); err == nil {
+			return value[:4]
+		}
+	}
+	return ""
+}
+
+// Текст песни с переводами строк \n
+func normalizeLyrics(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
+}
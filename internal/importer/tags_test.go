@@ -0,0 +1,302 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func syncsafeBytes(n int) []byte {
+	return []byte{byte(n >> 21 & 0x7F), byte(n >> 14 & 0x7F), byte(n >> 7 & 0x7F), byte(n & 0x7F)}
+}
+
+func id3Tag(version, flags byte, frames ...[]byte) []byte {
+	body := bytes.Join(frames, nil)
+	tag := append([]byte{'I', 'D', '3', version, 0, flags}, syncsafeBytes(len(body))...)
+	return append(tag, body...)
+}
+
+func id3Frame22(id string, data []byte) []byte {
+	frame := append([]byte(id), byte(len(data)>>16), byte(len(data)>>8), byte(len(data)))
+	return append(frame, data...)
+}
+
+func id3Frame23(id string, flags uint16, data []byte) []byte {
+	frame := binary.BigEndian.AppendUint32([]byte(id), uint32(len(data)))
+	frame = binary.BigEndian.AppendUint16(frame, flags)
+	return append(frame, data...)
+}
+
+func id3Frame24(id string, flags uint16, data []byte) []byte {
+	frame := append([]byte(id), syncsafeBytes(len(data))...)
+	frame = binary.BigEndian.AppendUint16(frame, flags)
+	return append(frame, data...)
+}
+
+func latin1(text string) []byte {
+	data := []byte{0}
+	for _, r := range text {
+		data = append(data, byte(r))
+	}
+	return data
+}
+
+func utf16LE(text string) []byte {
+	data := []byte{0xFF, 0xFE}
+	for _, r := range text {
+		data = binary.LittleEndian.AppendUint16(data, uint16(r))
+	}
+	return data
+}
+
+func vorbisComment(fields ...string) []byte {
+	data := binary.LittleEndian.AppendUint32(nil, 6)
+	data = append(data, "vendor"...)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(fields)))
+	for _, field := range fields {
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(field)))
+		data = append(data, field...)
+	}
+	return data
+}
+
+func flacBlock(blockType byte, last bool, data []byte) []byte {
+	if last {
+		blockType |= 0x80
+	}
+	return append([]byte{blockType, byte(len(data) >> 16), byte(len(data) >> 8), byte(len(data))}, data...)
+}
+
+func flacFile(blocks ...[]byte) []byte {
+	return append([]byte("fLaC"), bytes.Join(blocks, nil)...)
+}
+
+// Страница OGG с одним пакетом на каждый переданный срез
+func oggPage(packets ...[]byte) []byte {
+	var segments, body []byte
+	for _, packet := range packets {
+		n := len(packet)
+		for ; n >= 255; n -= 255 {
+			segments = append(segments, 255)
+		}
+		segments = append(segments, byte(n))
+		body = append(body, packet...)
+	}
+	header := make([]byte, 26)
+	copy(header, "OggS")
+	page := append(header, byte(len(segments)))
+	page = append(page, segments...)
+	return append(page, body...)
+}
+
+func TestReadTags(t *testing.T) {
+	lyrics := append([]byte{1, 'e', 'n', 'g'}, utf16LE("desc")...)
+	lyrics = append(lyrics, 0, 0)
+	lyrics = append(lyrics, utf16LE("Line one\r\nLine two")...)
+
+	tests := []struct {
+		name string
+		data []byte
+		want map[string]string
+	}{
+		{
+			"id3v2.3",
+			id3Tag(3, 0,
+				id3Frame23("TPE1", 0, latin1("Mötley Crüe")),
+				id3Frame23("TIT2", 0, latin1("Kickstart")),
+				id3Frame23("TYER", 0, latin1("1985")),
+				id3Frame23("USLT", 0, lyrics),
+				id3Frame23("TXXX", 0, latin1("ignored")),
+			),
+			map[string]string{tagArtist: "Mötley Crüe", tagTitle: "Kickstart", tagDate: "1985", tagLyrics: "Line one\r\nLine two"},
+		},
+		{
+			"id3v2.4 utf-8 with several values",
+			id3Tag(4, 0,
+				id3Frame24("TPE1", 0, append([]byte{3}, "Muse\x00Matt Bellamy"...)),
+				id3Frame24("TDRC", 0, append([]byte{3}, "2009-09-07"...)),
+			),
+			map[string]string{tagArtist: "Muse", tagDate: "2009-09-07"},
+		},
+		{
+			"id3v2.2",
+			id3Tag(2, 0, id3Frame22("TT2", latin1("Song")), id3Frame22("TP1", latin1("Band"))),
+			map[string]string{tagTitle: "Song", tagArtist: "Band"},
+		},
+		{
+			"padding after frames",
+			id3Tag(3, 0, id3Frame23("TIT2", 0, latin1("Song")), make([]byte, 32)),
+			map[string]string{tagTitle: "Song"},
+		},
+		{
+			"first frame wins",
+			id3Tag(3, 0, id3Frame23("TIT2", 0, latin1("First")), id3Frame23("TIT2", 0, latin1("Second"))),
+			map[string]string{tagTitle: "First"},
+		},
+		{
+			"compressed frame is skipped",
+			id3Tag(3, 0, id3Frame23("TPE1", 0x0080, latin1("Packed")), id3Frame23("TIT2", 0, latin1("Song"))),
+			map[string]string{tagTitle: "Song"},
+		},
+		{
+			"id3v2.4 data length indicator and unsynchronisation",
+			id3Tag(4, 0, id3Frame24("TIT2", 0x0003, append([]byte{0, 0, 0, 5}, 0, 'A', 0xFF, 0x00, 'B'))),
+			map[string]string{tagTitle: "AÿB"},
+		},
+		{
+			"id3v2.3 unsynchronised tag",
+			id3Tag(3, 0x80, bytes.ReplaceAll(id3Frame23("TIT2", 0, []byte{0, 'A', 0xFF, 'B'}), []byte{0xFF}, []byte{0xFF, 0x00})),
+			map[string]string{tagTitle: "AÿB"},
+		},
+		{
+			"flac",
+			flacFile(
+				flacBlock(0, false, make([]byte, 34)),
+				flacBlock(1, false, make([]byte, 100)),
+				flacBlock(4, true, vorbisComment("title=Song", "ALBUM ARTIST=Band", "Date=2001", "COMMENT")),
+			),
+			map[string]string{tagTitle: "Song", tagAlbumArtist: "Band", tagDate: "2001"},
+		},
+		{
+			"id3 before flac",
+			append(
+				id3Tag(3, 0, id3Frame23("TPE1", 0, latin1("Band"))),
+				flacFile(flacBlock(4, true, vorbisComment("TITLE=Song")))...,
+			),
+			map[string]string{tagArtist: "Band", tagTitle: "Song"},
+		},
+		{
+			"ogg vorbis with a packet split across segments",
+			append(
+				oggPage([]byte("\x01vorbis")),
+				oggPage(append([]byte("\x03vorbis"), vorbisComment("TITLE=Song", "LYRICS="+strings.Repeat("la ", 200))...))...,
+			),
+			map[string]string{tagTitle: "Song", tagLyrics: strings.TrimSpace(strings.Repeat("la ", 200))},
+		},
+		{
+			"opus",
+			oggPage([]byte("OpusHead"), append([]byte("OpusTags"), vorbisComment("ARTIST=Band")...)),
+			map[string]string{tagArtist: "Band"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readTags(bufio.NewReader(bytes.NewReader(tt.data)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tags = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadTagsErrors(t *testing.T) {
+	truncatedFrame := id3Tag(3, 0, id3Frame23("TIT2", 0, latin1("Song")))
+	binary.BigEndian.PutUint32(truncatedFrame[14:], 100)
+	oversizedFrame := id3Tag(3, 0, id3Frame23("TIT2", 0, latin1("Song")))
+	binary.BigEndian.PutUint32(oversizedFrame[14:], 0xFFFFFFFF)
+	oversizedTag := id3Tag(4, 0, id3Frame24("TIT2", 0, latin1("Song")))
+	copy(oversizedTag[6:], []byte{0x7F, 0x7F, 0x7F, 0x7F})
+	truncatedComment := vorbisComment("TITLE=Song")
+	truncatedComment = truncatedComment[:len(truncatedComment)-3]
+
+	tests := []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{"empty file", nil, ErrNoTags.Error()},
+		{"unknown format", []byte("RIFF....WAVE"), ErrNoTags.Error()},
+		{"unsupported id3 version", id3Tag(5, 0), "unsupported version"},
+		{"truncated tag", id3Tag(3, 0, id3Frame23("TIT2", 0, latin1("Song")))[:15], "unexpected EOF"},
+		{"oversized tag", oversizedTag, "unexpected EOF"},
+		{"truncated frame", truncatedFrame, "frame TIT2 is truncated"},
+		{"oversized frame", oversizedFrame, "frame TIT2 is truncated"},
+		{"invalid extended header", id3Tag(3, 0x40, []byte{0, 0, 1, 0}), "invalid extended header"},
+		{"id3 without frames", id3Tag(3, 0, make([]byte, 16)), ErrNoTags.Error()},
+		{"flac without comment", flacFile(flacBlock(0, true, make([]byte, 34))), ErrNoTags.Error()},
+		{"truncated flac block", flacFile(flacBlock(4, true, vorbisComment("TITLE=Song")))[:20], "unexpected EOF"},
+		{"invalid vorbis comment", flacFile(flacBlock(4, true, truncatedComment)), "invalid block"},
+		{"ogg with unknown codec", oggPage([]byte("first"), []byte("second")), "unsupported codec"},
+		{"truncated ogg page", oggPage([]byte("\x01vorbis"))[:30], "unexpected EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readTags(bufio.NewReader(bytes.NewReader(tt.data)))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestReadTagsFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"b.mp3": id3Tag(4, 0,
+			id3Frame24("TPE2", 0, latin1("Album Band")),
+			id3Frame24("TIT2", 0, latin1("Song")),
+			id3Frame24("TDRC", 0, latin1("2009-09-07T10:00")),
+			id3Frame24("USLT", 0, append([]byte{0, 'e', 'n', 'g', 0}, "One\r\nTwo\rThree"...)),
+		),
+		"a.flac":     flacFile(flacBlock(4, true, vorbisComment("ARTIST=Band", "TITLE=Other", "DATE=200"))),
+		"broken.ogg": []byte("OggS"),
+		"notes.txt":  []byte("ID3"),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rows, skipped, err := ScanAudio(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("rows = %+v, want 2", rows)
+	}
+	if got := rows[0].Song; got.Group != "Band" || got.Song != "Other" || got.ReleaseDate != "" {
+		t.Errorf("flac song = %+v", got)
+	}
+	if got := rows[1].Song; got.Group != "Album Band" || got.ReleaseDate != "07.09.2009" || got.Text != "One\nTwo\nThree" {
+		t.Errorf("mp3 song = %+v", got)
+	}
+	if len(skipped) != 1 || filepath.Base(skipped[0].Path) != "broken.ogg" || skipped[0].Status != StatusSkipped {
+		t.Errorf("skipped = %+v, want broken.ogg", skipped)
+	}
+}
+
+func TestReleaseDate(t *testing.T) {
+	tests := []struct {
+		value, want string
+	}{
+		{"2009-09-07", "07.09.2009"},
+		{"2009-09-07T10:00:00", "07.09.2009"},
+		{"2009", "2009"},
+		{"2009-13-40", "2009"},
+		{"09", ""},
+		{"unknown", ""},
+	}
+	for _, tt := range tests {
+		if got := releaseDate(tt.value); got != tt.want {
+			t.Errorf("releaseDate(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestReadBlock(t *testing.T) {
+	_, err := readBlock(bytes.NewReader([]byte("abc")), 1<<30)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("error = %v, want unexpected EOF", err)
+	}
+}
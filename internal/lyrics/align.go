@@ -0,0 +1,52 @@
+package lyrics
+
+import (
+	"Anastasia/songs/internal/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Хэш текста для проверки, что перевод выровнен по текущей версии оригинала
+func Hash(text string) string {
+	sum := sha256.Sum256([]byte(Normalize(text)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Проверка, что перевод повторяет структуру оригинала: те же части и столько же строк в каждой.
+// Возвращает *models.ValidationError с описанием первого расхождения
+func Align(original, translation models.Lyrics) error {
+	if len(original.Sections) != len(translation.Sections) {
+		return invalid("text", fmt.Sprintf("translation has %d sections, original has %d",
+			len(translation.Sections), len(original.Sections)))
+	}
+	for i, s := range original.Sections {
+		t := translation.Sections[i]
+		if len(s.Lines) != len(t.Lines) {
+			return invalid("text", fmt.Sprintf("section %d (%s %d) has %d lines, original has %d",
+				i+1, s.Type, s.Number, len(t.Lines), len(s.Lines)))
+		}
+	}
+	return nil
+}
+
+// Чередование строк оригинала и перевода по частям оригинала. Если перевод не выровнен
+// (оригинал изменился), недостающие строки перевода остаются пустыми
+func Interleave(original, translation models.Lyrics) []models.BilingualSection {
+	translated := Lines(translation)
+	sections := make([]models.BilingualSection, 0, len(original.Sections))
+	number := 0
+	for _, s := range original.Sections {
+		section := models.BilingualSection{Type: s.Type, Number: s.Number, Lines: []models.BilingualLine{}}
+		for _, line := range s.Lines {
+			number++
+			bl := models.BilingualLine{Number: number, Original: line}
+			if number <= len(translated) {
+				bl.Translation = translated[number-1].Text
+			}
+			section.Lines = append(section.Lines, bl)
+		}
+		sections = append(sections, section)
+	}
+	return sections
+}
@@ -0,0 +1,106 @@
+package lyrics
+
+import (
+	"Anastasia/songs/internal/models"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const testOriginal = "[Verse]\nOne\nTwo\n\n[Chorus]\nLa la\nLa\n\n[Chorus]"
+
+func TestHash(t *testing.T) {
+	if Hash("One\r\nTwo  \n\n") != Hash("One\nTwo") {
+		t.Error("Hash depends on line endings and trailing spaces")
+	}
+	if Hash("One\nTwo") == Hash("One\ntwo") {
+		t.Error("Hash ignores changes of the text")
+	}
+}
+
+func TestAlign(t *testing.T) {
+	tests := []struct {
+		name        string
+		translation string
+		problem     string
+	}{
+		{"translated labels", "[Куплет]\nРаз\nДва\n\n[Припев]\nЛя ля\nЛя\n\n[Припев]", ""},
+		{"without labels", "Раз\nДва\n\nЛя ля\nЛя\n\nЛя ля\nЛя", ""},
+		{"missing section", "Раз\nДва\n\nЛя ля\nЛя", "translation has 2 sections, original has 3"},
+		{"extra section", "Раз\n\nДва\n\nЛя ля\nЛя\n\n[Припев]", "translation has 4 sections, original has 3"},
+		{"missing line", "[Куплет]\nРаз\nДва\n\n[Припев]\nЛя ля\n\n[Припев]", "section 2 (chorus 1) has 1 lines, original has 2"},
+		{"extra line", "Раз\nДва\nТри\n\nЛя ля\nЛя\n\nЛя ля\nЛя", "section 1 (verse 1) has 3 lines, original has 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Align(Parse(testOriginal), Parse(tt.translation))
+			if tt.problem == "" {
+				if err != nil {
+					t.Errorf("Align = %v, want nil", err)
+				}
+				return
+			}
+			var validationErr *models.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("error = %v, want *models.ValidationError", err)
+			}
+			if got := validationErr.Fields["text"]; got != tt.problem {
+				t.Errorf("problem = %q, want %q", got, tt.problem)
+			}
+		})
+	}
+}
+
+func TestInterleave(t *testing.T) {
+	verse := func(lines ...models.BilingualLine) models.BilingualSection {
+		return models.BilingualSection{Type: models.SectionVerse, Number: 1, Lines: lines}
+	}
+	chorus := func(number int, lines ...models.BilingualLine) models.BilingualSection {
+		return models.BilingualSection{Type: models.SectionChorus, Number: number, Lines: lines}
+	}
+	tests := []struct {
+		name        string
+		translation string
+		want        []models.BilingualSection
+	}{
+		{
+			"aligned",
+			"[Куплет]\nРаз\nДва\n\n[Припев]\nЛя ля\nЛя\n\n[Припев]",
+			[]models.BilingualSection{
+				verse(models.BilingualLine{Number: 1, Original: "One", Translation: "Раз"}, models.BilingualLine{Number: 2, Original: "Two", Translation: "Два"}),
+				chorus(1, models.BilingualLine{Number: 3, Original: "La la", Translation: "Ля ля"}, models.BilingualLine{Number: 4, Original: "La", Translation: "Ля"}),
+				chorus(2, models.BilingualLine{Number: 5, Original: "La la", Translation: "Ля ля"}, models.BilingualLine{Number: 6, Original: "La", Translation: "Ля"}),
+			},
+		},
+		{
+			"outdated translation is shorter",
+			"Раз\nДва\n\nЛя ля",
+			[]models.BilingualSection{
+				verse(models.BilingualLine{Number: 1, Original: "One", Translation: "Раз"}, models.BilingualLine{Number: 2, Original: "Two", Translation: "Два"}),
+				chorus(1, models.BilingualLine{Number: 3, Original: "La la", Translation: "Ля ля"}, models.BilingualLine{Number: 4, Original: "La"}),
+				chorus(2, models.BilingualLine{Number: 5, Original: "La la"}, models.BilingualLine{Number: 6, Original: "La"}),
+			},
+		},
+		{
+			"outdated translation is longer",
+			"Раз\nДва\nТри\nЧетыре\nПять\nШесть\nСемь",
+			[]models.BilingualSection{
+				verse(models.BilingualLine{Number: 1, Original: "One", Translation: "Раз"}, models.BilingualLine{Number: 2, Original: "Two", Translation: "Два"}),
+				chorus(1, models.BilingualLine{Number: 3, Original: "La la", Translation: "Три"}, models.BilingualLine{Number: 4, Original: "La", Translation: "Четыре"}),
+				chorus(2, models.BilingualLine{Number: 5, Original: "La la", Translation: "Пять"}, models.BilingualLine{Number: 6, Original: "La", Translation: "Шесть"}),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Interleave(Parse(testOriginal), Parse(tt.translation))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Interleave = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if got := Interleave(Parse(""), Parse("Раз")); len(got) != 0 || got == nil {
+		t.Errorf("Interleave of empty lyrics = %+v, want empty sections", got)
+	}
+}
@@ -0,0 +1,311 @@
+package lyrics
+
+import (
+	"Anastasia/songs/internal/models"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Языки строк
+const (
+	LanguageRussian = "ru"
+	LanguageEnglish = "en"
+)
+
+var (
+	// Названия схем рифмовки четверостиший
+	rhymePatterns = map[string]string{
+		"AABB": "couplet",
+		"ABAB": "alternate",
+		"ABBA": "enclosed",
+		"AAAA": "monorhyme",
+		"ABCB": "ballad",
+	}
+	// Звонкие согласные, оглушаемые на конце слова
+	devoiced = map[rune]rune{'б': 'п', 'в': 'ф', 'г': 'к', 'д': 'т', 'ж': 'ш', 'з': 'с'}
+	// Написания открытых конечных гласных английского, звучащие одинаково
+	englishOpenVowels = map[string]string{
+		"ee": "ee", "ea": "ee", "ie": "ee", "e": "ee",
+		"ay": "ay", "ai": "ay", "ey": "ay",
+		"oo": "oo", "ew": "oo", "ue": "oo", "ou": "oo",
+	}
+)
+
+// Разбор рифм и слогов по частям текста. Рифма определяется по окончанию последнего слова строки
+// (правила произношения без словаря ударений), слоги — по гласным
+func Analyze(l models.Lyrics) models.LyricsAnalysis {
+	analysis := models.LyricsAnalysis{Sections: []models.SectionAnalysis{}}
+	number := 0
+	for _, s := range l.Sections {
+		section := models.SectionAnalysis{Type: s.Type, Number: s.Number, Lines: []models.LineAnalysis{}}
+		var keys []string
+		var letters []string
+		syllables, counted := 0, 0
+		for _, text := range s.Lines {
+			number++
+			line := models.LineAnalysis{Number: number, Text: text, Language: lineLanguage(text), Rhyme: "-"}
+			words := Words(text)
+			for _, w := range words {
+				line.Syllables += wordSyllables(w)
+			}
+
+			if len(words) > 0 {
+				key := rhymeKey(words[len(words)-1])
+				line.Rhyme = ""
+				for i, k := range keys {
+					if key != "" && k == key {
+						line.Rhyme = letters[i]
+						break
+					}
+				}
+				if line.Rhyme == "" {
+					line.Rhyme = rhymeLetter(distinct(letters))
+				}
+				keys = append(keys, key)
+				letters = append(letters, line.Rhyme)
+				syllables += line.Syllables
+				counted++
+			}
+			section.Lines = append(section.Lines, line)
+		}
+
+		section.Scheme = strings.Join(letters, "")
+		section.Pattern = schemePattern(letters)
+		if counted > 0 {
+			section.AverageSyllables = math.Round(float64(syllables)/float64(counted)*10) / 10
+		}
+		analysis.Sections = append(analysis.Sections, section)
+	}
+	return analysis
+}
+
+// Количество различных букв схемы
+func distinct(letters []string) int {
+	seen := map[string]bool{}
+	for _, l := range letters {
+		seen[l] = true
+	}
+	return len(seen)
+}
+
+// Буква рифмы по её порядковому номеру: A–Z, затем A1, B1 и т.д.
+func rhymeLetter(n int) string {
+	if n < 26 {
+		return string(rune('A' + n))
+	}
+	return fmt.Sprintf("%c%d", 'A'+n%26, n/26)
+}
+
+// Название схемы, если часть состоит из четверостиший с одной и той же схемой
+func schemePattern(letters []string) string {
+	if len(letters) == 0 || len(letters)%4 != 0 {
+		return ""
+	}
+	pattern := ""
+	for i := 0; i < len(letters); i += 4 {
+		// Буквы каждого четверостишия назначаются заново с A
+		relettered := map[string]string{}
+		var b strings.Builder
+		for _, l := range letters[i : i+4] {
+			if _, ok := relettered[l]; !ok {
+				relettered[l] = rhymeLetter(len(relettered))
+			}
+			b.WriteString(relettered[l])
+		}
+		name, ok := rhymePatterns[b.String()]
+		if !ok || (pattern != "" && name != pattern) {
+			return ""
+		}
+		pattern = name
+	}
+	return pattern
+}
+
+// Язык строки по преобладающему алфавиту
+func lineLanguage(text string) string {
+	cyrillic, latin := 0, 0
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+	switch {
+	case cyrillic == 0 && latin == 0:
+		return ""
+	case cyrillic >= latin:
+		return LanguageRussian
+	}
+	return LanguageEnglish
+}
+
+func isCyrillic(word string) bool {
+	for _, r := range word {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// Количество слогов слова: в русском — по числу гласных, в английском — по группам гласных
+// без немой конечной e и окончаний -es, -ed
+func wordSyllables(word string) int {
+	if isCyrillic(word) {
+		n := 0
+		for _, r := range word {
+			if strings.ContainsRune("аеиоуыэюяё", r) {
+				n++
+			}
+		}
+		return n
+	}
+
+	w := []rune(strings.ReplaceAll(word, "'", ""))
+	if len(w) == 0 || !unicode.IsLetter(w[0]) {
+		return 0
+	}
+	if len(w) >= 3 {
+		switch {
+		case len(w) > 3 && strings.HasSuffix(string(w), "ed") && !strings.ContainsRune("td", w[len(w)-3]):
+			w = w[:len(w)-2]
+		case len(w) > 3 && strings.HasSuffix(string(w), "es") && !strings.ContainsRune("sxzhc", w[len(w)-3]):
+			w = w[:len(w)-2]
+		case w[len(w)-1] == 'e' && !isEnglishVowel(w, len(w)-2) && w[len(w)-2] != 'l':
+			w = w[:len(w)-1]
+		}
+	}
+
+	n := 0
+	for i := range w {
+		if isEnglishVowel(w, i) && (i == 0 || !isEnglishVowel(w, i-1)) {
+			n++
+		}
+	}
+	return max(n, 1)
+}
+
+// Гласная английского слова; y в начале слова — согласная, ī — обозначение звука [ai] в ключе рифмы
+func isEnglishVowel(w []rune, i int) bool {
+	if i < 0 {
+		return false
+	}
+	return strings.ContainsRune("aeiouī", w[i]) || (w[i] == 'y' && i > 0)
+}
+
+func hasEnglishVowel(w []rune) bool {
+	for i := range w {
+		if isEnglishVowel(w, i) {
+			return true
+		}
+	}
+	return false
+}
+
+// Ключ рифмы последнего слова строки: слова с одинаковым ключом рифмуются
+func rhymeKey(word string) string {
+	if isCyrillic(word) {
+		return russianRhymeKey(word)
+	}
+	return englishRhymeKey(word)
+}
+
+// Окончание от последней гласной с учётом произношения: я, ю, е, ы читаются как а, у, э, и,
+// безударное о — как а, конечные звонкие согласные оглушаются. У открытого слога (слово
+// оканчивается гласной) к ключу добавляется предшествующая согласная, мягкая перед я, ю, е, ё
+// (отмечается апострофом): меня — огня, но не меня — весна
+func russianRhymeKey(word string) string {
+	var sounds []rune
+	for _, r := range strings.ReplaceAll(word, "-", "") {
+		if strings.ContainsRune("яюеё", r) && len(sounds) > 0 && !strings.ContainsRune("аэиу'", sounds[len(sounds)-1]) {
+			sounds = append(sounds, '\'')
+		}
+		switch r {
+		case 'ь', 'ъ':
+			continue
+		case 'я':
+			r = 'а'
+		case 'ю':
+			r = 'у'
+		case 'е':
+			r = 'э'
+		case 'ё', 'о':
+			r = 'а'
+		case 'ы':
+			r = 'и'
+		}
+		sounds = append(sounds, r)
+	}
+
+	last := -1
+	for i, r := range sounds {
+		if strings.ContainsRune("аэиу", r) {
+			last = i
+		}
+	}
+	if last < 0 {
+		return ""
+	}
+
+	key := append([]rune{}, sounds[last:]...)
+	for i := len(key) - 1; i > 0; i-- {
+		d, ok := devoiced[key[i]]
+		if !ok {
+			break
+		}
+		key[i] = d
+	}
+	if len(key) == 1 && last > 0 {
+		start := last - 1
+		if sounds[start] == '\'' && start > 0 {
+			start--
+		}
+		key = sounds[start:]
+	}
+	return string(key)
+}
+
+// Окончание от последней группы гласных без немой конечной e; одинаково звучащие окончания
+// приводятся к одному написанию: see — me — tea, night — bite, high — sky
+func englishRhymeKey(word string) string {
+	w := []rune(strings.ReplaceAll(strings.ReplaceAll(word, "'", ""), "igh", "ī"))
+	// Конечная e немая, только если в слове есть другая гласная: she и the рифмуются с me
+	if n := len(w); n > 2 && w[n-1] == 'e' && !isEnglishVowel(w, n-2) && hasEnglishVowel(w[:n-1]) {
+		// i перед согласной и немой e читается [ai]: time, fire
+		if n > 3 && w[n-3] == 'i' && !isEnglishVowel(w, n-4) {
+			w[n-3] = 'ī'
+		}
+		w = w[:n-1]
+	}
+	// Конечная y после согласной в односложном слове читается [ai]: sky, my
+	if n := len(w); n > 1 && w[n-1] == 'y' && !strings.ContainsAny(string(w[:n-1]), "aeiouī") {
+		w[n-1] = 'ī'
+	}
+
+	end := -1
+	for i := len(w) - 1; i >= 0; i-- {
+		if isEnglishVowel(w, i) {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return ""
+	}
+	start := end
+	for start > 0 && isEnglishVowel(w, start-1) {
+		start--
+	}
+
+	group, rest := string(w[start:end+1]), string(w[end+1:])
+	if rest == "" {
+		if sound, ok := englishOpenVowels[group]; ok {
+			return sound
+		}
+	}
+	return group + rest
+}
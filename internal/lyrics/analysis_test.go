@@ -0,0 +1,187 @@
+package lyrics
+
+import (
+	"Anastasia/songs/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestWordSyllables(t *testing.T) {
+	tests := []struct {
+		word string
+		want int
+	}{
+		{"мама", 2},
+		{"ёжик", 2},
+		{"жизнь", 1},
+		{"вдохновение", 5},
+		{"кто-то", 2},
+		{"a", 1},
+		{"cat", 1},
+		{"time", 1},
+		{"the", 1},
+		{"she", 1},
+		{"little", 2},
+		{"jumped", 1},
+		{"wanted", 2},
+		{"makes", 1},
+		{"boxes", 2},
+		{"yellow", 2},
+		{"rhythm", 1},
+		{"beautiful", 3},
+		{"don't", 1},
+		{"1999", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if got := wordSyllables(tt.word); got != tt.want {
+				t.Errorf("wordSyllables(%q) = %d, want %d", tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRhymeKey(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		rhyme bool
+	}{
+		{"меня", "огня", true},
+		{"меня", "весна", false},
+		{"семья", "земля", false},
+		{"моя", "твоя", true},
+		{"любовь", "кровь", true},
+		{"морозы", "розы", true},
+		{"сад", "взгляд", true},
+		{"ночь", "дочь", true},
+		{"грусть", "пусть", true},
+		{"дом", "дым", false},
+		{"night", "bite", true},
+		{"high", "sky", true},
+		{"see", "tea", true},
+		{"she", "me", true},
+		{"the", "free", true},
+		{"love", "above", true},
+		{"fire", "desire", true},
+		{"day", "away", true},
+		{"car", "star", true},
+		{"me", "my", false},
+		{"cat", "dog", false},
+		{"time", "team", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			a, b := rhymeKey(tt.a), rhymeKey(tt.b)
+			if a == "" || b == "" {
+				t.Fatalf("empty rhyme key: %q, %q", a, b)
+			}
+			if (a == b) != tt.rhyme {
+				t.Errorf("rhymeKey = %q, %q, want rhyme %v", a, b, tt.rhyme)
+			}
+		})
+	}
+
+	for _, word := range []string{"брр", "hmm", "1999"} {
+		if key := rhymeKey(word); key != "" {
+			t.Errorf("rhymeKey(%q) = %q, want empty", word, key)
+		}
+	}
+}
+
+func TestSchemePattern(t *testing.T) {
+	tests := []struct {
+		scheme string
+		want   string
+	}{
+		{"", ""},
+		{"ABAB", "alternate"},
+		{"AABB", "couplet"},
+		{"ABBA", "enclosed"},
+		{"AAAA", "monorhyme"},
+		{"ABCB", "ballad"},
+		{"ABCD", ""},
+		{"ABA", ""},
+		{"ABABCDCD", "alternate"},
+		{"ABABCBCB", "alternate"},
+		{"AABBCCDDE", ""},
+		{"ABABCCDD", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scheme, func(t *testing.T) {
+			var letters []string
+			for _, r := range tt.scheme {
+				letters = append(letters, string(r))
+			}
+			if got := schemePattern(letters); got != tt.want {
+				t.Errorf("schemePattern(%q) = %q, want %q", tt.scheme, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	text := "[Verse]\n" +
+		"Я помню чудное мгновенье:\n" +
+		"Передо мной явилась ты,\n" +
+		"Как мимолётное виденье,\n" +
+		"Как гений чистой красоты.\n" +
+		"Шли годы. Бурь порыв мятежный\n" +
+		"Рассеял прежние мечты,\n" +
+		"И я забыл твой голос нежный,\n" +
+		"Твои небесные черты.\n" +
+		"\n" +
+		"[Chorus]\n" +
+		"We sing all through the night\n" +
+		"Until the morning light\n" +
+		"...\n" +
+		"Come dance along with me\n" +
+		"Tonight we will be free"
+
+	ru := func(number int, text string, syllables int, rhyme string) models.LineAnalysis {
+		return models.LineAnalysis{Number: number, Text: text, Language: LanguageRussian, Syllables: syllables, Rhyme: rhyme}
+	}
+	en := func(number int, text string, rhyme string) models.LineAnalysis {
+		return models.LineAnalysis{Number: number, Text: text, Language: LanguageEnglish, Syllables: 6, Rhyme: rhyme}
+	}
+	want := models.LyricsAnalysis{Sections: []models.SectionAnalysis{
+		{
+			Type:             models.SectionVerse,
+			Number:           1,
+			Scheme:           "ABABCBCB",
+			Pattern:          "alternate",
+			AverageSyllables: 8.5,
+			Lines: []models.LineAnalysis{
+				ru(1, "Я помню чудное мгновенье:", 9, "A"),
+				ru(2, "Передо мной явилась ты,", 8, "B"),
+				ru(3, "Как мимолётное виденье,", 9, "A"),
+				ru(4, "Как гений чистой красоты.", 8, "B"),
+				ru(5, "Шли годы. Бурь порыв мятежный", 9, "C"),
+				ru(6, "Рассеял прежние мечты,", 8, "B"),
+				ru(7, "И я забыл твой голос нежный,", 9, "C"),
+				ru(8, "Твои небесные черты.", 8, "B"),
+			},
+		},
+		{
+			Type:             models.SectionChorus,
+			Number:           1,
+			Scheme:           "AABB",
+			Pattern:          "couplet",
+			AverageSyllables: 6,
+			Lines: []models.LineAnalysis{
+				en(9, "We sing all through the night", "A"),
+				en(10, "Until the morning light", "A"),
+				{Number: 11, Text: "...", Rhyme: "-"},
+				en(12, "Come dance along with me", "B"),
+				en(13, "Tonight we will be free", "B"),
+			},
+		},
+	}}
+
+	if got := Analyze(Parse(text)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Analyze = %+v, want %+v", got, want)
+	}
+
+	if got := Analyze(Parse("")); got.Sections == nil || len(got.Sections) != 0 {
+		t.Errorf("Analyze of empty lyrics = %+v, want no sections", got)
+	}
+}
@@ -0,0 +1,424 @@
+package lyrics
+
+import (
+	"Anastasia/songs/internal/models"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Типы частей ChordPro, кроме частей текста песни
+const (
+	chordSectionTab  = "tab"
+	chordSectionGrid = "grid"
+)
+
+// Директивы начала и конца окружений ChordPro и их сокращения
+var (
+	startDirectives = map[string]string{
+		"start_of_chorus": models.SectionChorus, "soc": models.SectionChorus,
+		"start_of_verse": models.SectionVerse, "sov": models.SectionVerse,
+		"start_of_bridge": models.SectionBridge, "sob": models.SectionBridge,
+		"start_of_tab": chordSectionTab, "sot": chordSectionTab,
+		"start_of_grid": chordSectionGrid, "sog": chordSectionGrid,
+	}
+	endDirectives = map[string]string{
+		"end_of_chorus": models.SectionChorus, "eoc": models.SectionChorus,
+		"end_of_verse": models.SectionVerse, "eov": models.SectionVerse,
+		"end_of_bridge": models.SectionBridge, "eob": models.SectionBridge,
+		"end_of_tab": chordSectionTab, "eot": chordSectionTab,
+		"end_of_grid": chordSectionGrid, "eog": chordSectionGrid,
+	}
+	// Сокращённые имена директив метаданных
+	metaAliases = map[string]string{"t": "title", "st": "subtitle"}
+	// Директивы комментариев
+	commentDirectives = map[string]bool{
+		"comment": true, "c": true, "comment_italic": true, "ci": true,
+		"comment_box": true, "cb": true, "highlight": true,
+	}
+	// Директивы метаданных; остальные директивы (шрифты, цвета, new_page и т.д.) пропускаются
+	metaDirectives = map[string]bool{
+		"title": true, "subtitle": true, "artist": true, "composer": true, "lyricist": true,
+		"album": true, "year": true, "copyright": true, "tempo": true, "time": true, "duration": true,
+	}
+)
+
+// Разбор текста в формате ChordPro: аккорды в квадратных скобках внутри строк, директивы
+// в фигурных скобках. Возвращает *models.ValidationError с номерами некорректных строк
+func ParseChordPro(text string) (models.ChordSheet, error) {
+	sheet := models.ChordSheet{Metadata: map[string]string{}, Sections: []models.ChordSection{}}
+	problems := map[string]string{}
+
+	var current *models.ChordSection
+	// Окружение (soc/eoc и т.д.), в котором находится разбор, и строка его начала
+	environment, environmentLine := "", 0
+	flush := func() {
+		if current != nil && (len(current.Lines) > 0 || current.Repeat) {
+			sheet.Sections = append(sheet.Sections, *current)
+		}
+		current = nil
+	}
+
+	for i, raw := range strings.Split(Normalize(text), "\n") {
+		lineNo := fmt.Sprintf("line %d", i+1)
+		line := strings.TrimRight(raw, " \t")
+		trimmed := strings.TrimSpace(line)
+
+		if environment == chordSectionTab || environment == chordSectionGrid {
+			if name, _, ok := directive(trimmed); ok && endDirectives[name] == environment {
+				flush()
+				environment = ""
+				continue
+			}
+			// Табулатура и сетка сохраняются как есть
+			current.Lines = append(current.Lines, models.ChordLine{Text: line})
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "#"):
+			continue
+		case trimmed == "":
+			if environment == "" {
+				flush()
+			}
+			continue
+		}
+
+		name, value, ok := directive(trimmed)
+		if !ok {
+			chordLine, err := parseChordLine(line)
+			if err != nil {
+				problems[lineNo] = err.Error()
+				continue
+			}
+			if current == nil {
+				current = &models.ChordSection{Lines: []models.ChordLine{}}
+			}
+			current.Lines = append(current.Lines, chordLine)
+			continue
+		}
+
+		if alias, ok := metaAliases[name]; ok {
+			name = alias
+		}
+		switch {
+		case startDirectives[name] != "":
+			if environment != "" {
+				problems[lineNo] = fmt.Sprintf("%s inside start_of_%s started at line %d", name, environment, environmentLine)
+				continue
+			}
+			flush()
+			environment, environmentLine = startDirectives[name], i+1
+			current = &models.ChordSection{Type: environment, Label: sectionLabel(value), Lines: []models.ChordLine{}}
+		case endDirectives[name] != "":
+			if endDirectives[name] != environment {
+				problems[lineNo] = fmt.Sprintf("%s without matching start_of_%s", name, endDirectives[name])
+				continue
+			}
+			flush()
+			environment = ""
+		case name == "chorus":
+			if environment != "" {
+				problems[lineNo] = "chorus reference inside a section"
+				continue
+			}
+			flush()
+			sheet.Sections = append(sheet.Sections, models.ChordSection{
+				Type: models.SectionChorus, Label: sectionLabel(value), Lines: []models.ChordLine{}, Repeat: true,
+			})
+		case commentDirectives[name]:
+			if current == nil {
+				current = &models.ChordSection{Lines: []models.ChordLine{}}
+			}
+			current.Lines = append(current.Lines, models.ChordLine{Comment: value})
+		case name == "key":
+			if _, _, err := parseKey(value); err != nil {
+				problems[lineNo] = err.Error()
+				continue
+			}
+			sheet.Key = value
+		case name == "capo":
+			capo, err := strconv.Atoi(value)
+			if err != nil || capo < 0 || capo > maxCapo {
+				problems[lineNo] = fmt.Sprintf("capo must be a fret number from 0 to %d", maxCapo)
+				continue
+			}
+			sheet.Capo = capo
+		case metaDirectives[name]:
+			sheet.Metadata[name] = value
+		}
+	}
+	flush()
+
+	if environment != "" {
+		problems[fmt.Sprintf("line %d", environmentLine)] = fmt.Sprintf("start_of_%s is not closed", environment)
+	}
+	if len(problems) == 0 && !hasLyrics(sheet) {
+		problems["chordpro"] = "no lyrics"
+	}
+	if len(problems) > 0 {
+		return models.ChordSheet{}, &models.ValidationError{Fields: problems}
+	}
+	return sheet, nil
+}
+
+// Имя и значение директивы {name: value} или {name value}; имя в нижнем регистре
+func directive(line string) (string, string, bool) {
+	if !strings.HasPrefix(line, "{") || !strings.HasSuffix(line, "}") {
+		return "", "", false
+	}
+	body := strings.TrimSpace(line[1 : len(line)-1])
+	end := strings.IndexAny(body, ": \t")
+	if end < 0 {
+		return strings.ToLower(body), "", true
+	}
+	return strings.ToLower(body[:end]), strings.TrimSpace(strings.TrimPrefix(body[end:], ":")), true
+}
+
+// Метка части: {soc: Припев} или {start_of_chorus: label="Припев"}
+func sectionLabel(value string) string {
+	if label, ok := strings.CutPrefix(value, "label="); ok {
+		return strings.Trim(label, `"'`)
+	}
+	return value
+}
+
+// Разбор строки текста с аккордами [C] в местах их смены
+func parseChordLine(line string) (models.ChordLine, error) {
+	var text []rune
+	var chords []models.ChordPosition
+	for rest := line; rest != ""; {
+		open := strings.IndexByte(rest, '[')
+		if open < 0 {
+			text = append(text, []rune(rest)...)
+			break
+		}
+		text = append(text, []rune(rest[:open])...)
+		end := strings.IndexByte(rest[open:], ']')
+		if end < 0 {
+			return models.ChordLine{}, fmt.Errorf("unclosed [ at %q", rest[open:])
+		}
+		chord := strings.TrimSpace(rest[open+1 : open+end])
+		if _, err := parseChord(chord); err != nil {
+			return models.ChordLine{}, err
+		}
+		chords = append(chords, models.ChordPosition{Chord: chord, Position: len(text)})
+		rest = rest[open+end+1:]
+	}
+	return models.ChordLine{Text: strings.TrimRight(string(text), " \t"), Chords: chords}, nil
+}
+
+func hasLyrics(sheet models.ChordSheet) bool {
+	for _, s := range sheet.Sections {
+		if s.Type == chordSectionTab || s.Type == chordSectionGrid {
+			continue
+		}
+		for _, line := range s.Lines {
+			if strings.TrimSpace(line.Text) != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Обычный текст песни из текста с аккордами: части через пустую строку, у припевов и частей
+// с меткой — строка-метка, понятная Parse. Табулатуры, сетки и комментарии пропускаются
+func ChordsText(sheet models.ChordSheet) string {
+	var blocks []string
+	for _, s := range sheet.Sections {
+		if s.Type == chordSectionTab || s.Type == chordSectionGrid {
+			continue
+		}
+		var lines []string
+		if s.Label != "" || s.Type == models.SectionChorus || s.Type == models.SectionBridge {
+			lines = append(lines, "["+sectionTitle(s)+"]")
+		}
+		text := false
+		for _, line := range s.Lines {
+			if t := strings.TrimSpace(line.Text); t != "" {
+				lines, text = append(lines, t), true
+			}
+		}
+		// Ссылка на припев остаётся одной меткой: Parse повторит предыдущий припев
+		if text || s.Repeat {
+			blocks = append(blocks, strings.Join(lines, "\n"))
+		}
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// Соответствует ли текст с аккордами обычному тексту песни
+func ChordsMatch(chordpro, text string) bool {
+	sheet, err := ParseChordPro(chordpro)
+	return err == nil && SameLyrics(ChordsText(sheet), text)
+}
+
+// Заголовок части для вывода: метка или название типа
+func sectionTitle(s models.ChordSection) string {
+	if s.Label != "" {
+		return s.Label
+	}
+	switch s.Type {
+	case models.SectionChorus:
+		return "Chorus"
+	case models.SectionBridge:
+		return "Bridge"
+	case models.SectionVerse:
+		return "Verse"
+	case chordSectionTab:
+		return "Tab"
+	}
+	return ""
+}
+
+// Текст с аккордами над строками моноширинным шрифтом. Если аккорды не помещаются над своими
+// слогами, строка текста раздвигается
+func FormatChords(sheet models.ChordSheet) string {
+	var b strings.Builder
+	if title := sheet.Metadata["title"]; title != "" {
+		b.WriteString(title)
+		if artist := sheet.Metadata["artist"]; artist != "" {
+			b.WriteString(" — " + artist)
+		}
+		b.WriteByte('\n')
+	}
+	if sheet.Key != "" {
+		fmt.Fprintf(&b, "Key: %s\n", sheet.Key)
+	}
+	if sheet.Capo > 0 {
+		fmt.Fprintf(&b, "Capo: %d\n", sheet.Capo)
+	}
+
+	for _, s := range sheet.Sections {
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		if title := sectionTitle(s); title != "" {
+			fmt.Fprintf(&b, "[%s]\n", title)
+		}
+		for _, line := range s.Lines {
+			switch {
+			case line.Comment != "":
+				fmt.Fprintf(&b, "(%s)\n", line.Comment)
+			case len(line.Chords) == 0:
+				b.WriteString(line.Text + "\n")
+			default:
+				chords, text := chordsOverText(line)
+				b.WriteString(chords + "\n")
+				if strings.TrimSpace(text) != "" {
+					b.WriteString(text + "\n")
+				}
+			}
+		}
+	}
+	return b.String()
+}
+
+// Строка аккордов и выровненная под неё строка текста
+func chordsOverText(line models.ChordLine) (string, string) {
+	text := []rune(line.Text)
+	var chords, lyrics []rune
+	consumed := 0
+	for _, c := range line.Chords {
+		position := min(c.Position, len(text))
+		lyrics = append(lyrics, text[consumed:position]...)
+		consumed = position
+
+		// Между аккордами хотя бы один пробел
+		column := len(lyrics)
+		if len(chords) > 0 && column <= len(chords) {
+			gap := len(chords) + 1 - column
+			// Внутри слова раздвинутые слоги соединяются дефисами
+			fill := ' '
+			if position > 0 && position < len(text) && text[position-1] != ' ' && text[position] != ' ' {
+				fill = '-'
+			}
+			lyrics = append(lyrics, []rune(strings.Repeat(string(fill), gap))...)
+			column = len(lyrics)
+		}
+		chords = append(chords, []rune(strings.Repeat(" ", column-len(chords)))...)
+		chords = append(chords, []rune(c.Chord)...)
+	}
+	lyrics = append(lyrics, text[consumed:]...)
+	return string(chords), strings.TrimRight(string(lyrics), " -")
+}
+
+// Запись в формате ChordPro
+func FormatChordPro(sheet models.ChordSheet) string {
+	var b strings.Builder
+	keys := make([]string, 0, len(sheet.Metadata))
+	for key := range sheet.Metadata {
+		keys = append(keys, key)
+	}
+	// Название и исполнитель первыми, остальные по алфавиту
+	rank := map[string]int{"title": 0, "subtitle": 1, "artist": 2}
+	sort.Slice(keys, func(i, j int) bool {
+		ri, okI := rank[keys[i]]
+		rj, okJ := rank[keys[j]]
+		switch {
+		case okI && okJ:
+			return ri < rj
+		case okI != okJ:
+			return okI
+		}
+		return keys[i] < keys[j]
+	})
+	for _, key := range keys {
+		fmt.Fprintf(&b, "{%s: %s}\n", key, sheet.Metadata[key])
+	}
+	if sheet.Key != "" {
+		fmt.Fprintf(&b, "{key: %s}\n", sheet.Key)
+	}
+	if sheet.Capo > 0 {
+		fmt.Fprintf(&b, "{capo: %d}\n", sheet.Capo)
+	}
+
+	for _, s := range sheet.Sections {
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		if s.Repeat {
+			b.WriteString(withValue("chorus", s.Label) + "\n")
+			continue
+		}
+		if s.Type != "" {
+			b.WriteString(withValue("start_of_"+s.Type, s.Label) + "\n")
+		}
+		for _, line := range s.Lines {
+			if line.Comment != "" {
+				fmt.Fprintf(&b, "{comment: %s}\n", line.Comment)
+				continue
+			}
+			b.WriteString(inlineChords(line) + "\n")
+		}
+		if s.Type != "" {
+			fmt.Fprintf(&b, "{end_of_%s}\n", s.Type)
+		}
+	}
+	return b.String()
+}
+
+func withValue(name, value string) string {
+	if value == "" {
+		return "{" + name + "}"
+	}
+	return "{" + name + ": " + value + "}"
+}
+
+// Строка текста с аккордами в квадратных скобках
+func inlineChords(line models.ChordLine) string {
+	text := []rune(line.Text)
+	var b strings.Builder
+	consumed := 0
+	for _, c := range line.Chords {
+		position := min(c.Position, len(text))
+		b.WriteString(string(text[consumed:position]))
+		consumed = position
+		b.WriteString("[" + c.Chord + "]")
+	}
+	b.WriteString(string(text[consumed:]))
+	return b.String()
+}
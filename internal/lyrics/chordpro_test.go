@@ -0,0 +1,191 @@
+package lyrics
+
+import (
+	"Anastasia/songs/internal/models"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const testChordPro = `{title: Песня}
+{artist: Band}
+{t: Override}
+{key: G}
+{capo: 2}
+# комментарий файла
+
+[G]Привет, [D]мир
+Вторая [Em]строка
+
+{soc: Припев}
+[C]Ла-ла-[G]ла
+{eoc}
+
+{c: Повтор}
+{chorus}
+
+{sot}
+e|---0---|
+  spaced
+{eot}
+`
+
+func TestParseChordPro(t *testing.T) {
+	want := models.ChordSheet{
+		Metadata: map[string]string{"title": "Override", "artist": "Band"},
+		Key:      "G",
+		Capo:     2,
+		Sections: []models.ChordSection{
+			{Lines: []models.ChordLine{
+				{Text: "Привет, мир", Chords: []models.ChordPosition{{Chord: "G", Position: 0}, {Chord: "D", Position: 8}}},
+				{Text: "Вторая строка", Chords: []models.ChordPosition{{Chord: "Em", Position: 7}}},
+			}},
+			{Type: models.SectionChorus, Label: "Припев", Lines: []models.ChordLine{
+				{Text: "Ла-ла-ла", Chords: []models.ChordPosition{{Chord: "C", Position: 0}, {Chord: "G", Position: 6}}},
+			}},
+			{Lines: []models.ChordLine{{Comment: "Повтор"}}},
+			{Type: models.SectionChorus, Lines: []models.ChordLine{}, Repeat: true},
+			{Type: chordSectionTab, Lines: []models.ChordLine{{Text: "e|---0---|"}, {Text: "  spaced"}}},
+		},
+	}
+
+	sheet, err := ParseChordPro(testChordPro)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sheet, want) {
+		t.Errorf("ParseChordPro = %+v, want %+v", sheet, want)
+	}
+
+	again, err := ParseChordPro(FormatChordPro(sheet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, sheet) {
+		t.Errorf("round trip = %+v, want %+v", again, sheet)
+	}
+}
+
+func TestParseChordProErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		chordpro string
+		fields   []string
+	}{
+		{"unknown chord", "[C]La [X]la", []string{"line 1"}},
+		{"unclosed chord", "La\n[C]La [G", []string{"line 2"}},
+		{"nested sections", "{soc}\n{sov}\nLa\n{eoc}", []string{"line 2"}},
+		{"end without start", "{eoc}\nLa", []string{"line 1"}},
+		{"mismatched end", "{sov}\nLa\n{eoc}\n{eov}", []string{"line 3"}},
+		{"section is not closed", "La\n{soc}\nLa", []string{"line 2"}},
+		{"chorus reference inside a section", "{soc}\n{chorus}\nLa\n{eoc}", []string{"line 2"}},
+		{"invalid key", "{key: H}\nLa", []string{"line 1"}},
+		{"invalid capo", "{capo: 13}\nLa", []string{"line 1"}},
+		{"no lyrics", "{title: Song}\n[C] [G]", []string{"chordpro"}},
+		{"only a tab", "{sot}\n[C]e|--|\n{eot}", []string{"chordpro"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseChordPro(tt.chordpro)
+			var validationErr *models.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("error = %v, want *models.ValidationError", err)
+			}
+			if len(validationErr.Fields) != len(tt.fields) {
+				t.Errorf("fields = %v, want %v", validationErr.Fields, tt.fields)
+			}
+			for _, field := range tt.fields {
+				if _, ok := validationErr.Fields[field]; !ok {
+					t.Errorf("fields = %v, want %q", validationErr.Fields, field)
+				}
+			}
+		})
+	}
+}
+
+func TestChordsText(t *testing.T) {
+	sheet, err := ParseChordPro(testChordPro)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Привет, мир\nВторая строка\n\n[Припев]\nЛа-ла-ла\n\n[Chorus]"
+	if got := ChordsText(sheet); got != want {
+		t.Errorf("ChordsText = %q, want %q", got, want)
+	}
+
+	// Припев по ссылке разбирается как повтор предыдущего припева
+	parsed := Parse(want)
+	if n := len(parsed.Sections); n != 3 || !parsed.Sections[2].Repeat || parsed.Sections[2].Lines[0] != "Ла-ла-ла" {
+		t.Errorf("Parse(ChordsText) = %+v", parsed.Sections)
+	}
+}
+
+func TestFormatChords(t *testing.T) {
+	sheet, err := ParseChordPro(testChordPro)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Override — Band\nKey: G\nCapo: 2\n" +
+		"\nG       D\nПривет, мир\n       Em\nВторая строка\n" +
+		"\n[Припев]\nC     G\nЛа-ла-ла\n" +
+		"\n(Повтор)\n" +
+		"\n[Chorus]\n" +
+		"\n[Tab]\ne|---0---|\n  spaced\n"
+	if got := FormatChords(sheet); got != want {
+		t.Errorf("FormatChords = %q, want %q", got, want)
+	}
+}
+
+func TestChordsOverText(t *testing.T) {
+	tests := []struct {
+		line           string
+		chords, lyrics string
+	}{
+		{"[Cmaj7]Hel[G]lo", "Cmaj7 G", "Hel---lo"},
+		{"[Am]Word [Cmaj7]next [G]end", "Am   Cmaj7 G", "Word next  end"},
+		{"[C] [G]", "C G", ""},
+		{"Hello[C]", "     C", "Hello"},
+		{"Сло[Dm]во", "   Dm", "Слово"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			line, err := parseChordLine(tt.line)
+			if err != nil {
+				t.Fatal(err)
+			}
+			chords, lyrics := chordsOverText(line)
+			if chords != tt.chords || lyrics != tt.lyrics {
+				t.Errorf("chordsOverText = %q, %q, want %q, %q", chords, lyrics, tt.chords, tt.lyrics)
+			}
+		})
+	}
+}
+
+func TestLyricsMatch(t *testing.T) {
+	lrc := "[00:01.00]Привет, мир\n[00:02.00]Вторая строка\n[00:03.00]\n" +
+		"[00:04.00]Ла-ла-ла\n[00:05.00]\n[00:06.00]Ла-ла-ла"
+	tests := []struct {
+		name           string
+		text           string
+		synced, chords bool
+	}{
+		{"text derived from chords", "Привет, мир\nВторая строка\n\n[Припев]\nЛа-ла-ла\n\n[Chorus]", true, true},
+		{"text derived from synced lyrics", "Привет, мир\nВторая строка\n\nЛа-ла-ла\n\nЛа-ла-ла", true, true},
+		{"edited text", "Привет, мир\nДругая строка\n\nЛа-ла-ла\n\nЛа-ла-ла", false, false},
+		{"removed chorus repeat", "Привет, мир\nВторая строка\n\nЛа-ла-ла", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SyncedMatches(lrc, tt.text); got != tt.synced {
+				t.Errorf("SyncedMatches = %v, want %v", got, tt.synced)
+			}
+			if got := ChordsMatch(testChordPro, tt.text); got != tt.chords {
+				t.Errorf("ChordsMatch = %v, want %v", got, tt.chords)
+			}
+		})
+	}
+
+	if SyncedMatches("not lrc", "not lrc") || ChordsMatch("[X]la", "la") {
+		t.Error("invalid stored text matches")
+	}
+}
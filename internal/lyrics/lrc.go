@@ -0,0 +1,320 @@
+package lyrics
+
+import (
+	"Anastasia/songs/internal/models"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Длительность последней строки при выгрузке в субтитры, если после неё нет метки времени
+const lastCueDuration = 4000
+
+// Метка времени строки [мм:сс.xx] или слова <мм:сс.xx>; дробная часть — десятые, сотые или тысячные
+var (
+	lineTagRe = regexp.MustCompile(`^\[(\d{1,3}):(\d{1,2})(?:[.:](\d{1,3}))?\]`)
+	wordTagRe = regexp.MustCompile(`<(\d{1,3}):(\d{1,2})(?:[.:](\d{1,3}))?>`)
+	metaTagRe = regexp.MustCompile(`^\[([A-Za-z#]+):(.*)\]$`)
+)
+
+// Разбор текста в формате LRC, в том числе расширенного (метки времени слов <мм:сс.xx>).
+// Строка с несколькими метками повторяется в каждой из них; тег offset применяется ко всем меткам.
+// Возвращает *models.ValidationError с номерами некорректных строк
+func ParseLRC(text string) (models.SyncedLyrics, error) {
+	result := models.SyncedLyrics{Metadata: map[string]string{}, Lines: []models.SyncedLine{}}
+	problems := map[string]string{}
+	var offset int64
+
+	for i, raw := range strings.Split(Normalize(text), "\n") {
+		lineNo := fmt.Sprintf("line %d", i+1)
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+
+		if !lineTagRe.MatchString(line) {
+			m := metaTagRe.FindStringSubmatch(line)
+			if m == nil {
+				problems[lineNo] = "missing [mm:ss.xx] timestamp"
+				continue
+			}
+			key, value := strings.ToLower(m[1]), strings.TrimSpace(m[2])
+			if key == "offset" {
+				n, err := strconv.ParseInt(value, 10, 64)
+				if err != nil {
+					problems[lineNo] = "offset must be an integer number of milliseconds"
+					continue
+				}
+				offset = n
+				continue
+			}
+			result.Metadata[key] = value
+			continue
+		}
+
+		var times []int64
+		for {
+			m := lineTagRe.FindStringSubmatch(line)
+			if m == nil {
+				break
+			}
+			t, err := timestamp(m[1:])
+			if err != nil {
+				problems[lineNo] = err.Error()
+				break
+			}
+			times = append(times, t)
+			line = line[len(m[0]):]
+		}
+		if _, failed := problems[lineNo]; failed {
+			continue
+		}
+
+		text, words, err := parseWords(line, times[0])
+		if err != nil {
+			problems[lineNo] = err.Error()
+			continue
+		}
+		for _, t := range times {
+			synced := models.SyncedLine{Time: t, Text: text}
+			// Метки слов заданы относительно первой метки строки
+			for _, w := range words {
+				synced.Words = append(synced.Words, models.SyncedWord{Time: w.Time + t - times[0], Text: w.Text})
+			}
+			result.Lines = append(result.Lines, synced)
+		}
+	}
+
+	if len(problems) == 0 && !hasText(result.Lines) {
+		problems["lrc"] = "no timed lines with text"
+	}
+	if len(problems) > 0 {
+		return models.SyncedLyrics{}, &models.ValidationError{Fields: problems}
+	}
+
+	// Положительный offset означает, что текст должен появляться раньше
+	for i := range result.Lines {
+		result.Lines[i].Time = max(result.Lines[i].Time-offset, 0)
+		for j := range result.Lines[i].Words {
+			result.Lines[i].Words[j].Time = max(result.Lines[i].Words[j].Time-offset, 0)
+		}
+	}
+	sort.SliceStable(result.Lines, func(i, j int) bool { return result.Lines[i].Time < result.Lines[j].Time })
+	return result, nil
+}
+
+// Разбор текста строки с метками слов. Текст до первой метки начинается вместе со строкой
+func parseWords(line string, start int64) (string, []models.SyncedWord, error) {
+	tags := wordTagRe.FindAllStringSubmatchIndex(line, -1)
+	if len(tags) == 0 {
+		if brokenWordTag(line) {
+			return "", nil, fmt.Errorf("invalid word timestamp in %q", line)
+		}
+		return strings.TrimSpace(line), nil, nil
+	}
+
+	var words []models.SyncedWord
+	if head := strings.TrimSpace(line[:tags[0][0]]); head != "" {
+		if brokenWordTag(head) {
+			return "", nil, fmt.Errorf("invalid word timestamp in %q", head)
+		}
+		words = append(words, models.SyncedWord{Time: start, Text: head})
+	}
+	for i, tag := range tags {
+		t, err := timestamp([]string{submatch(line, tag, 1), submatch(line, tag, 2), submatch(line, tag, 3)})
+		if err != nil {
+			return "", nil, err
+		}
+		end := len(line)
+		if i+1 < len(tags) {
+			end = tags[i+1][0]
+		}
+		if word := strings.TrimSpace(line[tag[1]:end]); word != "" {
+			if brokenWordTag(word) {
+				return "", nil, fmt.Errorf("invalid word timestamp in %q", word)
+			}
+			words = append(words, models.SyncedWord{Time: t, Text: word})
+		}
+	}
+
+	texts := make([]string, len(words))
+	for i, w := range words {
+		texts[i] = w.Text
+	}
+	return strings.Join(texts, " "), words, nil
+}
+
+// Остаток метки слова, которую не удалось разобрать, например "<0:x>"
+func brokenWordTag(s string) bool {
+	return strings.ContainsAny(s, "<>") && strings.Contains(s, ":")
+}
+
+func submatch(s string, loc []int, n int) string {
+	if loc[2*n] < 0 {
+		return ""
+	}
+	return s[loc[2*n]:loc[2*n+1]]
+}
+
+// Время в миллисекундах из минут, секунд и дробной части
+func timestamp(parts []string) (int64, error) {
+	minutes, _ := strconv.ParseInt(parts[0], 10, 64)
+	seconds, _ := strconv.ParseInt(parts[1], 10, 64)
+	if seconds >= 60 {
+		return 0, fmt.Errorf("invalid timestamp %s:%s, seconds must be below 60", parts[0], parts[1])
+	}
+	var ms int64
+	if fraction := parts[2]; fraction != "" {
+		ms, _ = strconv.ParseInt(fraction, 10, 64)
+		switch len(fraction) {
+		case 1:
+			ms *= 100
+		case 2:
+			ms *= 10
+		}
+	}
+	return (minutes*60+seconds)*1000 + ms, nil
+}
+
+func hasText(lines []models.SyncedLine) bool {
+	for _, line := range lines {
+		if line.Text != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Обычный текст из синхронизированного: строки в порядке времени, пустые строки (паузы)
+// разделяют куплеты
+func PlainText(synced models.SyncedLyrics) string {
+	var b strings.Builder
+	pause := false
+	for _, line := range synced.Lines {
+		if line.Text == "" {
+			pause = b.Len() > 0
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+			if pause {
+				b.WriteByte('\n')
+			}
+		}
+		pause = false
+		b.WriteString(line.Text)
+	}
+	return b.String()
+}
+
+// Соответствует ли синхронизированный текст обычному тексту песни
+func SyncedMatches(lrc, text string) bool {
+	synced, err := ParseLRC(lrc)
+	return err == nil && SameLyrics(PlainText(synced), text)
+}
+
+// Запись в формате LRC: теги метаданных, затем строки с метками времени (и слов, если они есть)
+func FormatLRC(synced models.SyncedLyrics) string {
+	var b strings.Builder
+	keys := make([]string, 0, len(synced.Metadata))
+	for key := range synced.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "[%s:%s]\n", key, synced.Metadata[key])
+	}
+
+	for _, line := range synced.Lines {
+		fmt.Fprintf(&b, "[%s]", lrcTime(line.Time))
+		if len(line.Words) == 0 {
+			b.WriteString(line.Text)
+		}
+		for i, w := range line.Words {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			fmt.Fprintf(&b, "<%s>%s", lrcTime(w.Time), w.Text)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Запись в формате SubRip: по субтитру на каждую непустую строку до начала следующей строки
+func FormatSRT(synced models.SyncedLyrics) string {
+	var b strings.Builder
+	for i, cue := range cues(synced) {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1,
+			clockTime(cue.start, ","), clockTime(cue.end, ","), cue.line.Text)
+	}
+	return b.String()
+}
+
+// Запись в формате WebVTT. Метки времени слов передаются внутренними метками субтитра
+// (караоке-подсветка в проигрывателях)
+func FormatVTT(synced models.SyncedLyrics) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for _, cue := range cues(synced) {
+		fmt.Fprintf(&b, "%s --> %s\n", clockTime(cue.start, "."), clockTime(cue.end, "."))
+		if len(cue.line.Words) == 0 {
+			b.WriteString(vttEscape(cue.line.Text))
+		}
+		for i, w := range cue.line.Words {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			if w.Time > cue.start && w.Time < cue.end {
+				fmt.Fprintf(&b, "<%s>", clockTime(w.Time, "."))
+			}
+			b.WriteString(vttEscape(w.Text))
+		}
+		b.WriteString("\n\n")
+	}
+	return b.String()
+}
+
+type cue struct {
+	start, end int64
+	line       models.SyncedLine
+}
+
+// Субтитры для непустых строк; каждый длится до начала следующей строки
+func cues(synced models.SyncedLyrics) []cue {
+	var result []cue
+	for i, line := range synced.Lines {
+		if line.Text == "" {
+			continue
+		}
+		end := line.Time + lastCueDuration
+		for _, next := range synced.Lines[i+1:] {
+			if next.Time > line.Time {
+				end = next.Time
+				break
+			}
+		}
+		result = append(result, cue{start: line.Time, end: end, line: line})
+	}
+	return result
+}
+
+// Время в формате LRC: мм:сс.xx, или мм:сс.xxx, если время не кратно сотой доле секунды
+// (например, после применения offset)
+func lrcTime(ms int64) string {
+	if ms%10 != 0 {
+		return fmt.Sprintf("%02d:%02d.%03d", ms/60000, ms/1000%60, ms%1000)
+	}
+	return fmt.Sprintf("%02d:%02d.%02d", ms/60000, ms/1000%60, ms%1000/10)
+}
+
+// Время в формате чч:мм:сс,ммм (SRT) или чч:мм:сс.ммм (WebVTT)
+func clockTime(ms int64, sep string) string {
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+func vttEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,185 @@
+package lyrics
+
+import (
+	"Anastasia/songs/internal/models"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseLRC(t *testing.T) {
+	tests := []struct {
+		name string
+		lrc  string
+		want models.SyncedLyrics
+	}{
+		{
+			"metadata, fractions and sorting",
+			"[ar: Queen]\r\n[ti:Innuendo [Live]]\r\n[00:12.5]Second\r\n[00:01.07]First\r\n[00:20:123]Third\r\n[00:30]",
+			models.SyncedLyrics{
+				Metadata: map[string]string{"ar": "Queen", "ti": "Innuendo [Live]"},
+				Lines: []models.SyncedLine{
+					{Time: 1070, Text: "First"},
+					{Time: 12500, Text: "Second"},
+					{Time: 20123, Text: "Third"},
+					{Time: 30000, Text: ""},
+				},
+			},
+		},
+		{
+			"line with several timestamps",
+			"[01:00.00][00:10.00]Chorus\n[00:20.00]Verse",
+			models.SyncedLyrics{
+				Metadata: map[string]string{},
+				Lines: []models.SyncedLine{
+					{Time: 10000, Text: "Chorus"},
+					{Time: 20000, Text: "Verse"},
+					{Time: 60000, Text: "Chorus"},
+				},
+			},
+		},
+		{
+			"enhanced word tags",
+			"[00:05.00]Oh <00:06.00>hello <00:06.50>world<00:07.25>",
+			models.SyncedLyrics{
+				Metadata: map[string]string{},
+				Lines: []models.SyncedLine{{
+					Time: 5000,
+					Text: "Oh hello world",
+					Words: []models.SyncedWord{
+						{Time: 5000, Text: "Oh"},
+						{Time: 6000, Text: "hello"},
+						{Time: 6500, Text: "world"},
+					},
+				}},
+			},
+		},
+		{
+			"word tags are relative to each line timestamp",
+			"[00:10.00][01:10.00]<00:10.00>La <00:10.40>la",
+			models.SyncedLyrics{
+				Metadata: map[string]string{},
+				Lines: []models.SyncedLine{
+					{Time: 10000, Text: "La la", Words: []models.SyncedWord{{Time: 10000, Text: "La"}, {Time: 10400, Text: "la"}}},
+					{Time: 70000, Text: "La la", Words: []models.SyncedWord{{Time: 70000, Text: "La"}, {Time: 70400, Text: "la"}}},
+				},
+			},
+		},
+		{
+			"positive offset shows lines earlier and stops at zero",
+			"[00:00.20]Start\n[00:02.00]<00:02.00>Word <00:02.60>tags\n[offset:+500]",
+			models.SyncedLyrics{
+				Metadata: map[string]string{},
+				Lines: []models.SyncedLine{
+					{Time: 0, Text: "Start"},
+					{Time: 1500, Text: "Word tags", Words: []models.SyncedWord{{Time: 1500, Text: "Word"}, {Time: 2100, Text: "tags"}}},
+				},
+			},
+		},
+		{
+			"negative offset",
+			"[offset:-250]\n[00:01.00]Late",
+			models.SyncedLyrics{
+				Metadata: map[string]string{},
+				Lines:    []models.SyncedLine{{Time: 1250, Text: "Late"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLRC(tt.lrc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLRC = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLRCErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		lrc    string
+		fields []string
+	}{
+		{"empty", "", []string{"lrc"}},
+		{"only metadata and pauses", "[ar:Queen]\n[00:01.00]", []string{"lrc"}},
+		{"missing timestamp", "[00:01.00]One\nplain line\n[00:03.00]Three", []string{"line 2"}},
+		{"seconds out of range", "[00:61.00]One", []string{"line 1"}},
+		{"invalid offset", "[offset:soon]\n[00:01.00]One", []string{"line 1"}},
+		{"invalid word timestamp", "[00:01.00]<00:01.00>One <00:75.00>two", []string{"line 1"}},
+		{"malformed word tag", "[00:01.00]<00:01.00>One <0:x>two", []string{"line 1"}},
+		{"several broken lines", "nope\n[00:99.00]x\n[00:03.00]Three", []string{"line 1", "line 2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseLRC(tt.lrc)
+			var validationErr *models.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("error = %v, want *models.ValidationError", err)
+			}
+			if len(validationErr.Fields) != len(tt.fields) {
+				t.Errorf("fields = %v, want %v", validationErr.Fields, tt.fields)
+			}
+			for _, field := range tt.fields {
+				if _, ok := validationErr.Fields[field]; !ok {
+					t.Errorf("fields = %v, want %q", validationErr.Fields, field)
+				}
+			}
+		})
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	synced, err := ParseLRC("[00:00.50]\n[00:01.00]One\n[00:02.00]Two\n[00:03.00]\n[00:03.50]\n[00:04.00]Three\n[00:05.00]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := PlainText(synced), "One\nTwo\n\nThree"; got != want {
+		t.Errorf("PlainText = %q, want %q", got, want)
+	}
+}
+
+func TestFormatLRC(t *testing.T) {
+	synced, err := ParseLRC("[ti:Song]\n[ar:Band]\n[offset:7]\n[00:01.00]Plain & <simple>\n[01:02.50]<01:02.50>Word <01:03.00>tags\n[01:05.00]")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lrc := FormatLRC(synced)
+	want := "[ar:Band]\n[ti:Song]\n[00:00.993]Plain & <simple>\n[01:02.493]<01:02.493>Word <01:02.993>tags\n[01:04.993]\n"
+	if lrc != want {
+		t.Errorf("FormatLRC = %q, want %q", lrc, want)
+	}
+	again, err := ParseLRC(lrc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, synced) {
+		t.Errorf("round trip = %+v, want %+v", again, synced)
+	}
+}
+
+func TestFormatSubtitles(t *testing.T) {
+	synced, err := ParseLRC("[00:01.00]<00:01.00>One <00:01.50>two\n[00:01.00]Same time\n[00:03.00]\n[60:00.00]Hour <b>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantSRT := "1\n00:00:01,000 --> 00:00:03,000\nOne two\n\n" +
+		"2\n00:00:01,000 --> 00:00:03,000\nSame time\n\n" +
+		"3\n01:00:00,000 --> 01:00:04,000\nHour <b>\n\n"
+	if got := FormatSRT(synced); got != wantSRT {
+		t.Errorf("FormatSRT = %q, want %q", got, wantSRT)
+	}
+
+	wantVTT := "WEBVTT\n\n" +
+		"00:00:01.000 --> 00:00:03.000\nOne <00:00:01.500>two\n\n" +
+		"00:00:01.000 --> 00:00:03.000\nSame time\n\n" +
+		"01:00:00.000 --> 01:00:04.000\nHour &lt;b&gt;\n\n"
+	if got := FormatVTT(synced); got != wantVTT {
+		t.Errorf("FormatVTT = %q, want %q", got, wantVTT)
+	}
+}
@@ -0,0 +1,149 @@
+package lyrics
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math/bits"
+	"strings"
+)
+
+// Параметры MinHash: длина сигнатуры, число полос LSH (по SignatureSize/Bands значений в полосе)
+// и число слов в шингле
+const (
+	SignatureSize = 128
+	Bands         = 32
+	shingleSize   = 3
+)
+
+// Простое число Мерсенна 2^61-1 для хэш-функций вида (a*x + b) mod p
+const mersenne61 = 1<<61 - 1
+
+// Коэффициенты хэш-функций MinHash; постоянны, чтобы сигнатуры в БД оставались сравнимыми
+var minhashA, minhashB = minhashCoefficients()
+
+func minhashCoefficients() ([SignatureSize]uint64, [SignatureSize]uint64) {
+	var a, b [SignatureSize]uint64
+	// splitmix64 с фиксированным начальным значением
+	state := uint64(0x9e3779b97f4a7c15)
+	next := func() uint64 {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		return z ^ (z >> 31)
+	}
+	for i := range a {
+		a[i] = next()%(mersenne61-1) + 1
+		b[i] = next() % mersenne61
+	}
+	return a, b
+}
+
+// Шинглы текста: последовательности из трёх подряд идущих слов нормализованного текста
+// (без меток частей, регистра и пунктуации). Текст короче шингла даёт один шингл из всех слов
+func Shingles(text string) map[string]bool {
+	var words []string
+	for _, line := range Lines(Parse(text)) {
+		words = append(words, Words(line.Text)...)
+	}
+
+	shingles := map[string]bool{}
+	if len(words) > 0 && len(words) < shingleSize {
+		shingles[strings.Join(words, " ")] = true
+	}
+	for i := 0; i+shingleSize <= len(words); i++ {
+		shingles[strings.Join(words[i:i+shingleSize], " ")] = true
+	}
+	return shingles
+}
+
+// Сигнатура MinHash текста; nil, если в тексте нет слов
+func Signature(text string) []uint32 {
+	shingles := Shingles(text)
+	if len(shingles) == 0 {
+		return nil
+	}
+
+	signature := make([]uint32, SignatureSize)
+	for i := range signature {
+		signature[i] = ^uint32(0)
+	}
+	for shingle := range shingles {
+		h := fnv.New64a()
+		h.Write([]byte(shingle))
+		x := h.Sum64() % mersenne61
+		for i := range signature {
+			v := uint32(mulAddMod61(minhashA[i], x, minhashB[i]))
+			if v < signature[i] {
+				signature[i] = v
+			}
+		}
+	}
+	return signature
+}
+
+// (a*x + b) mod 2^61-1 без переполнения; a, x и b меньше 2^61-1
+func mulAddMod61(a, x, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, x)
+	// 2^64 ≡ 2^3 (mod 2^61-1)
+	r := (lo & mersenne61) + (lo >> 61) + (hi << 3)
+	r = (r & mersenne61) + (r >> 61) + b
+	r = (r & mersenne61) + (r >> 61)
+	if r >= mersenne61 {
+		r -= mersenne61
+	}
+	return r
+}
+
+// Оценка сходства Жаккара двух текстов по их сигнатурам: доля совпадающих значений
+func Similarity(a, b []uint32) float64 {
+	if len(a) != SignatureSize || len(b) != SignatureSize {
+		return 0
+	}
+	same := 0
+	for i := range a {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+	return float64(same) / SignatureSize
+}
+
+// Корзины LSH сигнатуры: хэш значений каждой полосы вместе с её номером. Тексты, попавшие хотя бы
+// в одну общую корзину, — кандидаты в похожие
+func Buckets(signature []uint32) []int64 {
+	if len(signature) != SignatureSize {
+		return nil
+	}
+	rows := SignatureSize / Bands
+	buckets := make([]int64, Bands)
+	buf := make([]byte, 4)
+	for band := range buckets {
+		h := fnv.New64a()
+		binary.BigEndian.PutUint32(buf, uint32(band))
+		h.Write(buf)
+		for _, v := range signature[band*rows : (band+1)*rows] {
+			binary.BigEndian.PutUint32(buf, v)
+			h.Write(buf)
+		}
+		buckets[band] = int64(h.Sum64())
+	}
+	return buckets
+}
+
+// Сигнатура в виде байтов для хранения и обратно
+func EncodeSignature(signature []uint32) []byte {
+	data := make([]byte, 4*len(signature))
+	for i, v := range signature {
+		binary.BigEndian.PutUint32(data[4*i:], v)
+	}
+	return data
+}
+
+func DecodeSignature(data []byte) []uint32 {
+	signature := make([]uint32, len(data)/4)
+	for i := range signature {
+		signature[i] = binary.BigEndian.Uint32(data[4*i:])
+	}
+	return signature
+}
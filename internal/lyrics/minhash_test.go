@@ -0,0 +1,166 @@
+package lyrics
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Текст из lines строк по шесть слов; слова всех строк различны
+func generatedLyrics(seed, lines int) string {
+	var b strings.Builder
+	for i := 0; i < lines; i++ {
+		for j := 0; j < 6; j++ {
+			fmt.Fprintf(&b, "w%dx%dy%d ", seed, i, j)
+		}
+		b.WriteByte('\n')
+		if i%4 == 3 {
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// Точное сходство Жаккара множеств шинглов двух текстов
+func jaccard(a, b string) float64 {
+	sa, sb := Shingles(a), Shingles(b)
+	common := 0
+	for s := range sa {
+		if sb[s] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(sa)+len(sb)-common)
+}
+
+func TestShingles(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"empty", "", nil},
+		{"punctuation only", "... !!!", nil},
+		{"short text", "Hello, World", []string{"hello world"}},
+		{"labels, case and punctuation", "[Chorus]\nOh, Oh — yeah!\nOH\n\n[Chorus]", []string{
+			"oh oh yeah", "oh yeah oh", "yeah oh oh", "oh oh oh",
+		}},
+		{"words across lines", "Ёлка и\nзвезда", []string{"елка и звезда"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for s := range Shingles(tt.text) {
+				got = append(got, s)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Shingles = %q, want %q", got, tt.want)
+			}
+			for _, s := range tt.want {
+				if !Shingles(tt.text)[s] {
+					t.Errorf("Shingles = %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	base := generatedLyrics(1, 40)
+	// Одно слово изменено, добавлена строка, изменён регистр
+	nearDuplicate := strings.Replace(strings.ToUpper(base), "W1X7Y2", "другое", 1) + "\nnew closing line here!"
+	edited := strings.Replace(base, "w1x20y0", "changed", 1)
+	for line := 0; line < 40; line += 4 {
+		edited = strings.Replace(edited, fmt.Sprintf("w1x%dy3", line), "changed", 1)
+	}
+	distinct := generatedLyrics(2, 40)
+
+	tests := []struct {
+		name     string
+		a, b     string
+		min, max float64
+		bucket   bool
+	}{
+		{"identical", base, base, 1, 1, true},
+		{"near duplicate", base, nearDuplicate, 0.9, 1, true},
+		{"edited in several places", base, edited, 0.6, 0.95, true},
+		{"distinct", base, distinct, 0, 0.05, false},
+		{"unrelated short texts", "Yesterday all my troubles", "Let it be let it be", 0, 0.05, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := Signature(tt.a), Signature(tt.b)
+			got := Similarity(a, b)
+			if got < tt.min || got > tt.max {
+				t.Errorf("Similarity = %.3f, want from %.2f to %.2f", got, tt.min, tt.max)
+			}
+			// Оценка по 128 значениям отличается от точного сходства не больше чем на ~3 стандартных отклонения
+			if exact := jaccard(tt.a, tt.b); got < exact-0.15 || got > exact+0.15 {
+				t.Errorf("Similarity = %.3f, exact Jaccard %.3f", got, exact)
+			}
+
+			shared := false
+			bucketsA, bucketsB := Buckets(a), Buckets(b)
+			for i := range bucketsA {
+				shared = shared || bucketsA[i] == bucketsB[i]
+			}
+			if shared != tt.bucket {
+				t.Errorf("shared LSH bucket = %v, want %v", shared, tt.bucket)
+			}
+		})
+	}
+}
+
+func TestSignature(t *testing.T) {
+	if s := Signature(" \n[Chorus]\n"); s != nil {
+		t.Errorf("Signature of text without words = %v, want nil", s)
+	}
+	if Similarity(nil, Signature("one two three")) != 0 {
+		t.Error("Similarity with an empty signature is not 0")
+	}
+
+	// Сигнатуры хранятся в БД: коэффициенты и хэши не должны меняться между версиями
+	if minhashA[0] != 1042757494553273851 || minhashB[0] != 487617019471545679 {
+		t.Errorf("coefficients = %d, %d", minhashA[0], minhashB[0])
+	}
+	got := Signature("Yesterday, all my troubles seemed so far away")[:4]
+	want := []uint32{1029810736, 52587538, 654469878, 1562359056}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Signature = %v, want %v", got, want)
+	}
+
+	signature := Signature(generatedLyrics(3, 8))
+	if decoded := DecodeSignature(EncodeSignature(signature)); !reflect.DeepEqual(decoded, signature) {
+		t.Errorf("decoded signature = %v, want %v", decoded, signature)
+	}
+	if buckets := Buckets(signature); len(buckets) != Bands {
+		t.Errorf("buckets = %d, want %d", len(buckets), Bands)
+	}
+	if Buckets(signature[:10]) != nil {
+		t.Error("Buckets of a short signature is not nil")
+	}
+}
+
+func TestMulAddMod61(t *testing.T) {
+	p := new(big.Int).SetUint64(mersenne61)
+	rng := rand.New(rand.NewSource(1))
+	cases := [][3]uint64{
+		{0, 0, 0},
+		{mersenne61 - 1, mersenne61 - 1, mersenne61 - 1},
+		{mersenne61 - 1, mersenne61 - 1, 0},
+		{1, mersenne61 - 1, 1},
+	}
+	for i := 0; i < 1000; i++ {
+		cases = append(cases, [3]uint64{rng.Uint64() % mersenne61, rng.Uint64() % mersenne61, rng.Uint64() % mersenne61})
+	}
+	for _, c := range cases {
+		want := new(big.Int).Mul(new(big.Int).SetUint64(c[0]), new(big.Int).SetUint64(c[1]))
+		want.Add(want, new(big.Int).SetUint64(c[2])).Mod(want, p)
+		if got := mulAddMod61(c[0], c[1], c[2]); got != want.Uint64() {
+			t.Fatalf("mulAddMod61(%d, %d, %d) = %d, want %d", c[0], c[1], c[2], got, want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package lyrics
+
+import "testing"
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"", ""},
+		{"Hysteria", "hysteria"},
+		{"  Supermassive\tBlack   Hole ", "supermassive black hole"},
+		{"ГРУППА  Крови", "группа крови"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeName(tt.name); got != tt.want {
+				t.Errorf("NormalizeName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"ёж", "еж", 1},
+		{"гусь", "гусли", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			if got := levenshtein([]rune(tt.a), []rune(tt.b)); got != tt.want {
+				t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNameSimilarity(t *testing.T) {
+	// Порог по умолчанию для DUPLICATE_SONG_SIMILARITY=80
+	const threshold = 0.8
+	tests := []struct {
+		a, b  string
+		want  float64
+		match bool
+	}{
+		{"", "", 1, true},
+		{"Hysteria", " HYSTERIA ", 1, true},
+		{"Hysteria", "", 0, false},
+		{"Supermassive Black Hole", "Supermasive Black Hole", 1 - 1.0/23, true},
+		{"Uprising", "Uprisings", 1 - 1.0/9, true},
+		// Одна замена в названии из пяти букв — ровно на пороге
+		{"Bones", "Banes", 0.8, true},
+		{"Кино", "Кина", 0.75, false},
+		{"Hysteria", "Hysteria (Live)", 1 - 7.0/15, false},
+		{"Madness", "Uprising", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			got := NameSimilarity(tt.a, tt.b)
+			if got != tt.want {
+				t.Errorf("NameSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			if got != NameSimilarity(tt.b, tt.a) {
+				t.Errorf("NameSimilarity is not symmetric for %q, %q", tt.a, tt.b)
+			}
+			if (got >= threshold) != tt.match {
+				t.Errorf("NameSimilarity(%q, %q) = %v, match at %v: %v", tt.a, tt.b, got, threshold, tt.match)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+// Пакет lyrics разбирает текст песни на части (куплеты, припевы и т.д.) и строки
+package lyrics
+
+import (
+	"Anastasia/songs/internal/models"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Версия разбора. Увеличивается при изменении правил, чтобы сохранённые результаты разбирались заново
+const Version = 1
+
+// Указание количества повторов в метке: "x2", "×2", "2x"
+var repeatRe = regexp.MustCompile(`^(?:[xх×]\d+|\d+[xх×]|[xх×])$`)
+
+// Названия частей в метках
+var markerTypes = map[string]string{
+	"verse":      models.SectionVerse,
+	"куплет":     models.SectionVerse,
+	"chorus":     models.SectionChorus,
+	"refrain":    models.SectionChorus,
+	"hook":       models.SectionChorus,
+	"припев":     models.SectionChorus,
+	"pre-chorus": models.SectionPreChorus,
+	"prechorus":  models.SectionPreChorus,
+	"pre chorus": models.SectionPreChorus,
+	"предприпев": models.SectionPreChorus,
+	"bridge":     models.SectionBridge,
+	"бридж":      models.SectionBridge,
+	"intro":      models.SectionIntro,
+	"вступление": models.SectionIntro,
+	"интро":      models.SectionIntro,
+	"outro":      models.SectionOutro,
+	"аутро":      models.SectionOutro,
+	"кода":       models.SectionOutro,
+	"концовка":   models.SectionOutro,
+}
+
+// Приведение текста к единому виду: переводы строк \n, без пробелов в конце строк
+// и без пустых строк в начале и в конце
+func Normalize(text string) string {
+	text = strings.TrimPrefix(text, "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRightFunc(line, unicode.IsSpace)
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// Разбиение текста на блоки, разделённые одной или несколькими пустыми строками
+func Blocks(text string) []string {
+	var blocks []string
+	var current []string
+	for _, line := range strings.Split(Normalize(text), "\n") {
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				blocks = append(blocks, strings.Join(current, "\n"))
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, strings.Join(current, "\n"))
+	}
+	return blocks
+}
+
+// Разбор текста на части. Тип части берётся из метки вида "[Chorus]" в первой строке блока;
+// неотмеченный блок, который повторяет отмеченную часть, получает её тип, а повторяющийся
+// несколько раз неотмеченный блок считается припевом. Остальные блоки — куплеты
+func Parse(text string) models.Lyrics {
+	result := models.Lyrics{Version: Version, Sections: []models.LyricsSection{}}
+
+	var sections []models.LyricsSection
+	for _, block := range Blocks(text) {
+		lines := strings.Split(block, "\n")
+		section := models.LyricsSection{}
+		// Метка может стоять отдельной строкой перед строками части или без них (повтор части)
+		for len(lines) > 0 {
+			typ, ok := marker(lines[0])
+			if !ok {
+				break
+			}
+			if section.Label != "" {
+				section.Lines = []string{}
+				sections = append(sections, section)
+			}
+			section = models.LyricsSection{Type: typ, Label: strings.TrimSpace(lines[0])}
+			lines = lines[1:]
+		}
+		section.Lines = lines
+		sections = append(sections, section)
+	}
+
+	// Типы отмеченных частей по их содержимому и количество повторов неотмеченных блоков
+	knownTypes := map[string]string{}
+	repeats := map[string]int{}
+	for _, s := range sections {
+		if len(s.Lines) == 0 {
+			continue
+		}
+		key := contentKey(s.Lines)
+		if s.Type != "" {
+			if _, ok := knownTypes[key]; !ok {
+				knownTypes[key] = s.Type
+			}
+		} else {
+			repeats[key]++
+		}
+	}
+
+	counters := map[string]int{}
+	lastByType := map[string][]string{}
+	for _, s := range sections {
+		key := contentKey(s.Lines)
+		if s.Type == "" {
+			switch {
+			case knownTypes[key] != "":
+				s.Type = knownTypes[key]
+			case repeats[key] > 1:
+				s.Type = models.SectionChorus
+			default:
+				s.Type = models.SectionVerse
+			}
+		}
+		if prev, ok := lastByType[s.Type]; ok && len(s.Lines) == 0 {
+			s.Lines = prev
+			s.Repeat = true
+		}
+		if len(s.Lines) > 0 && !s.Repeat {
+			lastByType[s.Type] = s.Lines
+		}
+		counters[s.Type]++
+		s.Number = counters[s.Type]
+		result.Sections = append(result.Sections, s)
+	}
+	return result
+}
+
+// Тип части по строке-метке: "[Chorus]", "Chorus:", "(Куплет 2)", "[Припев x2]"
+func marker(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	line = strings.TrimSuffix(line, ":")
+	switch {
+	case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"),
+		strings.HasPrefix(line, "(") && strings.HasSuffix(line, ")"):
+		line = line[1 : len(line)-1]
+	}
+
+	fields := strings.Fields(strings.ToLower(strings.TrimSuffix(strings.TrimSpace(line), ":")))
+	// Номер части и количество повторов после названия
+	for len(fields) > 1 && (repeatRe.MatchString(fields[len(fields)-1]) || isNumber(fields[len(fields)-1])) {
+		fields = fields[:len(fields)-1]
+	}
+	if len(fields) == 0 || len(fields) > 2 {
+		return "", false
+	}
+	typ, ok := markerTypes[strings.Join(fields, " ")]
+	return typ, ok
+}
+
+// Совпадают ли строки двух текстов с учётом повторов частей, без меток и разбиения на части.
+// Так сравниваются тексты, полученные из разных форматов (LRC, ChordPro)
+func SameLyrics(a, b string) bool {
+	return slices.Equal(lyricLines(a), lyricLines(b))
+}
+
+func lyricLines(text string) []string {
+	var lines []string
+	for _, s := range Parse(text).Sections {
+		for _, line := range s.Lines {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+	return lines
+}
+
+func isNumber(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// Ключ для сравнения частей: строки без регистра, пунктуации и лишних пробелов
+func contentKey(lines []string) string {
+	var b strings.Builder
+	for _, line := range lines {
+		for _, word := range strings.FieldsFunc(strings.ToLower(line), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			b.WriteString(word)
+			b.WriteByte(' ')
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,153 @@
+package lyrics
+
+import (
+	"Anastasia/songs/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name, text, want string
+	}{
+		{"empty", "", ""},
+		{"line endings", "one\r\ntwo\rthree\n", "one\ntwo\nthree"},
+		{"bom and trailing spaces", "\ufeff\n\none  \t\ntwo \n\n", "one\ntwo"},
+		{"inner blank lines are kept", "one\n \n\ntwo", "one\n\n\ntwo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.text); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	got := Blocks("\r\none\r\ntwo\r\n \r\n\r\nthree\n")
+	want := []string{"one\ntwo", "three"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Blocks = %q, want %q", got, want)
+	}
+	if got := Blocks(" \n\n"); got != nil {
+		t.Errorf("Blocks of blank text = %q, want nil", got)
+	}
+}
+
+func TestMarker(t *testing.T) {
+	tests := []struct {
+		line string
+		typ  string
+		ok   bool
+	}{
+		{"[Chorus]", models.SectionChorus, true},
+		{"Chorus:", models.SectionChorus, true},
+		{"(Куплет 2)", models.SectionVerse, true},
+		{"[Припев x2]", models.SectionChorus, true},
+		{"[Hook ×3]", models.SectionChorus, true},
+		{"[Verse 2x]", models.SectionVerse, true},
+		{"  [Pre-Chorus]  ", models.SectionPreChorus, true},
+		{"[Pre Chorus 1]", models.SectionPreChorus, true},
+		{"[Bridge:]", models.SectionBridge, true},
+		{"Intro", models.SectionIntro, true},
+		{"[Кода]", models.SectionOutro, true},
+		{"(x2)", "", false},
+		{"[Guitar Solo]", "", false},
+		{"(Oh yeah)", "", false},
+		{"Chorus of angels sings", "", false},
+		{"[]", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			typ, ok := marker(tt.line)
+			if typ != tt.typ || ok != tt.ok {
+				t.Errorf("marker(%q) = %q, %v, want %q, %v", tt.line, typ, ok, tt.typ, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []models.LyricsSection
+	}{
+		{
+			"empty",
+			"",
+			[]models.LyricsSection{},
+		},
+		{
+			"labelled sections and repeat by label",
+			"[Verse 1]\nOne\nTwo\n\n[Chorus]\nLa la\nLa la la\n\n[Verse 2]\nThree\n\n[Chorus]",
+			[]models.LyricsSection{
+				{Type: models.SectionVerse, Number: 1, Label: "[Verse 1]", Lines: []string{"One", "Two"}},
+				{Type: models.SectionChorus, Number: 1, Label: "[Chorus]", Lines: []string{"La la", "La la la"}},
+				{Type: models.SectionVerse, Number: 2, Label: "[Verse 2]", Lines: []string{"Three"}},
+				{Type: models.SectionChorus, Number: 2, Label: "[Chorus]", Lines: []string{"La la", "La la la"}, Repeat: true},
+			},
+		},
+		{
+			"unlabelled repeat of a labelled section",
+			"[Bridge]\nOh, oh\n\nVerse line\n\noh oh!",
+			[]models.LyricsSection{
+				{Type: models.SectionBridge, Number: 1, Label: "[Bridge]", Lines: []string{"Oh, oh"}},
+				{Type: models.SectionVerse, Number: 1, Lines: []string{"Verse line"}},
+				{Type: models.SectionBridge, Number: 2, Lines: []string{"oh oh!"}},
+			},
+		},
+		{
+			"repeated unlabelled block is a chorus",
+			"First\n\nRefrain line\n\nSecond\n\nRefrain  line.",
+			[]models.LyricsSection{
+				{Type: models.SectionVerse, Number: 1, Lines: []string{"First"}},
+				{Type: models.SectionChorus, Number: 1, Lines: []string{"Refrain line"}},
+				{Type: models.SectionVerse, Number: 2, Lines: []string{"Second"}},
+				{Type: models.SectionChorus, Number: 2, Lines: []string{"Refrain  line."}},
+			},
+		},
+		{
+			"several labels in one block",
+			"[Intro]\n[Verse]\nWords",
+			[]models.LyricsSection{
+				{Type: models.SectionIntro, Number: 1, Label: "[Intro]", Lines: []string{}},
+				{Type: models.SectionVerse, Number: 1, Label: "[Verse]", Lines: []string{"Words"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.text)
+			if got.Version != Version {
+				t.Errorf("version = %d, want %d", got.Version, Version)
+			}
+			if !reflect.DeepEqual(got.Sections, tt.want) {
+				t.Errorf("sections = %+v, want %+v", got.Sections, tt.want)
+			}
+		})
+	}
+}
+
+func TestSameLyrics(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{"identical", "One\nTwo", "One\nTwo", true},
+		{"labels and blank lines", "[Verse]\nOne\nTwo\n\n[Chorus]\nLa", "One\n  Two\nLa  ", true},
+		{"repeated chorus by label", "[Chorus]\nLa\n\n[Verse]\nOne\n\n[Chorus]", "La\n\nOne\n\nLa", true},
+		{"changed line", "One\nTwo", "One\nTwo!", false},
+		{"missing repeat", "[Chorus]\nLa\n\nOne\n\n[Chorus]", "La\n\nOne", false},
+		{"empty", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SameLyrics(tt.a, tt.b); got != tt.same {
+				t.Errorf("SameLyrics(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.same)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package lyrics
+
+import (
+	"Anastasia/songs/internal/models"
+	"fmt"
+	"strings"
+)
+
+// Нумерация всех строк текста подряд по частям
+func Lines(l models.Lyrics) []models.LyricsLine {
+	var lines []models.LyricsLine
+	for _, s := range l.Sections {
+		for _, text := range s.Lines {
+			lines = append(lines, models.LyricsLine{
+				Number:        len(lines) + 1,
+				Text:          text,
+				Section:       s.Type,
+				SectionNumber: s.Number,
+			})
+		}
+	}
+	return lines
+}
+
+// Выбор фрагмента текста. Возвращает *models.ValidationError при некорректном выборе
+// и models.ErrNotFound, если часть или строка не найдены
+func Select(l models.Lyrics, sel models.LyricsSelection) (models.LyricsExcerpt, error) {
+	lines := Lines(l)
+	excerpt := models.LyricsExcerpt{TotalLines: len(lines), Lines: []models.LyricsLine{}}
+
+	switch {
+	case sel.Query != "":
+		matches := search(lines, sel.Query)
+		if len(matches) == 0 {
+			return models.LyricsExcerpt{}, fmt.Errorf("no lines matching %q: %w", sel.Query, models.ErrNotFound)
+		}
+		hit := sel.Hit
+		if hit == 0 {
+			hit = 1
+		}
+		if hit < 0 || hit > len(matches) {
+			return models.LyricsExcerpt{}, invalid("hit", fmt.Sprintf("must be between 1 and %d", len(matches)))
+		}
+		if sel.Window < 0 {
+			return models.LyricsExcerpt{}, invalid("window", "must not be negative")
+		}
+		center := matches[hit-1]
+		excerpt.Lines = lineRange(lines, center-sel.Window, center+sel.Window)
+		excerpt.Matches = matches
+
+	case sel.Section != "":
+		number := sel.SectionNumber
+		if number == 0 {
+			number = 1
+		}
+		found := false
+		for _, line := range lines {
+			if line.Section == sel.Section && line.SectionNumber == number {
+				excerpt.Lines = append(excerpt.Lines, line)
+				found = true
+			}
+		}
+		if !found {
+			return models.LyricsExcerpt{}, fmt.Errorf("section %s %d: %w", sel.Section, number, models.ErrNotFound)
+		}
+
+	default:
+		from, to := sel.From, sel.To
+		if to == 0 {
+			to = len(lines)
+		}
+		if from > len(lines) {
+			return models.LyricsExcerpt{}, invalid("lines", fmt.Sprintf("song has only %d lines", len(lines)))
+		}
+		if from < 1 || to < from {
+			return models.LyricsExcerpt{}, invalid("lines", "must be a range like 5-12 with 1 <= start <= end")
+		}
+		excerpt.Lines = lineRange(lines, from, to)
+	}
+	return excerpt, nil
+}
+
+// Строки с номерами from–to, ограниченные границами текста
+func lineRange(lines []models.LyricsLine, from, to int) []models.LyricsLine {
+	from = max(from, 1)
+	to = min(to, len(lines))
+	if from > to {
+		return []models.LyricsLine{}
+	}
+	return lines[from-1 : to]
+}
+
+// Номера строк, содержащих запрос, без учёта регистра и пунктуации
+func search(lines []models.LyricsLine, query string) []int {
+	q := strings.TrimSpace(contentKey([]string{query}))
+	if q == "" {
+		return nil
+	}
+	var matches []int
+	for _, line := range lines {
+		if strings.Contains(contentKey([]string{line.Text}), q) {
+			matches = append(matches, line.Number)
+		}
+	}
+	return matches
+}
+
+func invalid(field, problem string) error {
+	return &models.ValidationError{Fields: map[string]string{field: problem}}
+}
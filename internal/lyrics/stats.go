@@ -0,0 +1,127 @@
+package lyrics
+
+import (
+	"Anastasia/songs/internal/models"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Версия подсчёта; подсчёты старой версии в кэше выполняются заново
+const StatsVersion = 1
+
+// Подсчёт слов и строк структурированного текста. Строки частей, повторённых меткой
+// (повтор припева), учитываются как пропетые
+func Tally(l models.Lyrics) models.LyricsTally {
+	tally := models.LyricsTally{Version: StatsVersion, Words: map[string]int{}}
+	seen := map[string]bool{}
+	for _, s := range l.Sections {
+		for _, line := range s.Lines {
+			ws := Words(line)
+			if len(ws) == 0 {
+				continue
+			}
+			for _, w := range ws {
+				tally.Words[w]++
+			}
+			tally.Lines++
+			tally.LineChars += utf8.RuneCountInString(strings.TrimSpace(line))
+
+			key := strings.Join(ws, " ")
+			if seen[key] {
+				tally.RepeatedLines++
+			}
+			seen[key] = true
+		}
+	}
+	return tally
+}
+
+// Объединение подсчётов нескольких песен. Повторы считаются внутри каждой песни
+func Merge(tallies ...models.LyricsTally) models.LyricsTally {
+	merged := models.LyricsTally{Version: StatsVersion, Words: map[string]int{}}
+	for _, t := range tallies {
+		for w, n := range t.Words {
+			merged.Words[w] += n
+		}
+		merged.Lines += t.Lines
+		merged.RepeatedLines += t.RepeatedLines
+		merged.LineChars += t.LineChars
+	}
+	return merged
+}
+
+// Метрики по подсчёту; top — количество самых частых слов
+func Stats(t models.LyricsTally, top int) models.LyricsStats {
+	stats := models.LyricsStats{
+		UniqueWords: len(t.Words),
+		Lines:       t.Lines,
+		UniqueLines: t.Lines - t.RepeatedLines,
+		TopWords:    []models.WordCount{},
+	}
+	for w, n := range t.Words {
+		stats.Words += n
+		if !stopwords[w] && !isNumber(w) && utf8.RuneCountInString(w) > 1 {
+			stats.TopWords = append(stats.TopWords, models.WordCount{Word: w, Count: n})
+		}
+	}
+	if stats.Words > 0 {
+		stats.LexicalDiversity = ratio(stats.UniqueWords, stats.Words)
+	}
+	if t.Lines > 0 {
+		stats.RepetitionRatio = ratio(t.RepeatedLines, t.Lines)
+		stats.AverageLineWords = ratio(stats.Words, t.Lines)
+		stats.AverageLineChars = ratio(t.LineChars, t.Lines)
+	}
+
+	sort.Slice(stats.TopWords, func(i, j int) bool {
+		if stats.TopWords[i].Count != stats.TopWords[j].Count {
+			return stats.TopWords[i].Count > stats.TopWords[j].Count
+		}
+		return stats.TopWords[i].Word < stats.TopWords[j].Word
+	})
+	if len(stats.TopWords) > top {
+		stats.TopWords = stats.TopWords[:top]
+	}
+	return stats
+}
+
+// Отношение, округлённое до тысячных
+func ratio(a, b int) float64 {
+	return math.Round(float64(a)/float64(b)*1000) / 1000
+}
+
+// Слова строки в нижнем регистре, ё заменяется на е. Апострофы и дефисы внутри слова
+// сохраняются (don't, кто-то)
+func Words(line string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(strings.ToLower(line))
+	for i, r := range runes {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if r == 'ё' {
+				r = 'е'
+			}
+			word = append(word, r)
+			continue
+		case (r == '\'' || r == '’' || r == '-') && len(word) > 0 && i+1 < len(runes) &&
+			(unicode.IsLetter(runes[i+1]) || unicode.IsDigit(runes[i+1])):
+			if r == '’' {
+				r = '\''
+			}
+			word = append(word, r)
+			continue
+		}
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = word[:0]
+		}
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
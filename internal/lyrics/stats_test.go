@@ -0,0 +1,158 @@
+package lyrics
+
+import (
+	"Anastasia/songs/internal/models"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", nil},
+		{" ... — !", nil},
+		{"Hello, World!", []string{"hello", "world"}},
+		{"Ёлка, ещё ЁЖ", []string{"елка", "еще", "еж"}},
+		{"Don't stop, rock'n'roll", []string{"don't", "stop", "rock'n'roll"}},
+		{"I’m here", []string{"i'm", "here"}},
+		{"кто-то где-нибудь", []string{"кто-то", "где-нибудь"}},
+		{"'quoted' -dash- end- 'tis", []string{"quoted", "dash", "end", "tis"}},
+		{"rock - n - roll", []string{"rock", "n", "roll"}},
+		{"1999 year2k", []string{"1999", "year2k"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			if got := Words(tt.line); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Words(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTally(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want models.LyricsTally
+	}{
+		{
+			"no lines",
+			"",
+			models.LyricsTally{Version: StatsVersion, Words: map[string]int{}},
+		},
+		{
+			"only labels and punctuation",
+			"[Chorus]\n...\n\n[Verse]",
+			models.LyricsTally{Version: StatsVersion, Words: map[string]int{}},
+		},
+		{
+			"repeated chorus by label",
+			"[Chorus]\nLa la\nЁж\n\n[Verse]\nOne ёж\n\n[Chorus]",
+			models.LyricsTally{
+				Version:       StatsVersion,
+				Words:         map[string]int{"la": 4, "еж": 3, "one": 1},
+				Lines:         5,
+				RepeatedLines: 2,
+				LineChars:     5 + 2 + 6 + 5 + 2,
+			},
+		},
+		{
+			"repeats differ only by case and punctuation",
+			"Hey, you!\nhey you\nHEY YOU...",
+			models.LyricsTally{
+				Version:       StatsVersion,
+				Words:         map[string]int{"hey": 3, "you": 3},
+				Lines:         3,
+				RepeatedLines: 2,
+				LineChars:     9 + 7 + 10,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tally(Parse(tt.text)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tally = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a := Tally(Parse("La la\nLa la"))
+	b := Tally(Parse("La la\nOne"))
+	want := models.LyricsTally{
+		Version:       StatsVersion,
+		Words:         map[string]int{"la": 6, "one": 1},
+		Lines:         4,
+		RepeatedLines: 1,
+		LineChars:     18,
+	}
+	if got := Merge(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge = %+v, want %+v", got, want)
+	}
+	if got := Merge(); got.Lines != 0 || len(got.Words) != 0 || got.Words == nil {
+		t.Errorf("Merge() = %+v, want an empty tally", got)
+	}
+}
+
+func TestStats(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		top  int
+		want models.LyricsStats
+	}{
+		{
+			"no lines",
+			"",
+			5,
+			models.LyricsStats{TopWords: []models.WordCount{}},
+		},
+		{
+			"stopwords, numbers and single letters are not top words",
+			"[Chorus]\nOh yeah, я люблю тебя\nLove me 2 times, I do\n\n[Verse]\nЛюблю любовь\n\n[Chorus]",
+			3,
+			models.LyricsStats{
+				Words:            24,
+				UniqueWords:      12,
+				LexicalDiversity: 0.5,
+				Lines:            5,
+				UniqueLines:      3,
+				RepetitionRatio:  0.4,
+				AverageLineWords: 4.8,
+				AverageLineChars: 19.2,
+				TopWords: []models.WordCount{
+					{Word: "люблю", Count: 3},
+					{Word: "love", Count: 2},
+					{Word: "times", Count: 2},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Stats(Tally(Parse(tt.text)), tt.top); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Stats = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStopwords(t *testing.T) {
+	for _, list := range []string{russianStopwords, englishStopwords} {
+		seen := map[string]bool{}
+		for _, w := range strings.Fields(list) {
+			if seen[w] {
+				t.Errorf("stopword %q is listed twice", w)
+			}
+			seen[w] = true
+			// Стоп-слова сравниваются со словами, выделенными Words
+			if words := Words(w); len(words) != 1 || words[0] != w {
+				t.Errorf("stopword %q is not a word: %q", w, words)
+			}
+		}
+	}
+}
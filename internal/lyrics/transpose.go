@@ -0,0 +1,210 @@
+package lyrics
+
+import (
+	"Anastasia/songs/internal/models"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Наибольший лад каподастра и наибольший сдвиг тональности в полутонах
+const (
+	maxCapo      = 12
+	maxTranspose = 12
+)
+
+// Названия нот с диезами и с бемолями по номеру полутона от C
+var (
+	sharpNotes = [12]string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
+	flatNotes  = [12]string{"C", "Db", "D", "Eb", "E", "F", "Gb", "G", "Ab", "A", "Bb", "B"}
+	// Номера полутонов нот без знаков
+	naturalNotes = map[byte]int{'C': 0, 'D': 2, 'E': 4, 'F': 5, 'G': 7, 'A': 9, 'B': 11}
+)
+
+// Тональности, которые записываются с бемолями: F, Bb, Eb, Ab, Db мажор и Dm, Gm, Cm, Fm, Bbm, Ebm
+var (
+	flatMajorKeys = map[int]bool{5: true, 10: true, 3: true, 8: true, 1: true}
+	flatMinorKeys = map[int]bool{2: true, 7: true, 0: true, 5: true, 10: true, 3: true}
+)
+
+var (
+	// Основной тон аккорда со знаком, остаток — качество и бас
+	chordRe = regexp.MustCompile(`^([A-G])(#|b|♯|♭)?(.*)$`)
+	// Качество аккорда: m, maj7, sus4, add9, dim, aug, 7(b9), 6/9 и т.п.
+	qualityRe = regexp.MustCompile(`^(maj|min|mi|dim|aug|sus|add|alt|no|omit|m|M|o|[0-9]|[-+#b♯♭°øΔ^(),/])*$`)
+	// Обозначения без тона: нет аккорда, повтор такта
+	noChords = map[string]bool{"N.C.": true, "N.C": true, "NC": true, "x": true, "%": true, "-": true}
+)
+
+// Разобранный аккорд: основной тон, качество и бас (для аккордов с басом C/G)
+type chord struct {
+	root, bass       int
+	quality          string
+	hasBass, special bool
+	text             string
+}
+
+// Разбор названия аккорда. Аннотации вида [*Riff] и обозначения без тона (N.C.) не транспонируются
+func parseChord(name string) (chord, error) {
+	if name == "" {
+		return chord{}, fmt.Errorf("empty chord []")
+	}
+	if strings.HasPrefix(name, "*") || noChords[name] {
+		return chord{special: true, text: name}, nil
+	}
+
+	m := chordRe.FindStringSubmatch(name)
+	if m == nil {
+		return chord{}, fmt.Errorf("unknown chord %q", name)
+	}
+	c := chord{root: note(m[1], m[2]), quality: m[3]}
+
+	// Бас после последней косой черты, если это нота (в 6/9 после черты число)
+	if slash := strings.LastIndexByte(c.quality, '/'); slash >= 0 {
+		if bm := chordRe.FindStringSubmatch(c.quality[slash+1:]); bm != nil && bm[3] == "" {
+			c.bass, c.hasBass = note(bm[1], bm[2]), true
+			c.quality = c.quality[:slash]
+		}
+	}
+	if !qualityRe.MatchString(c.quality) {
+		return chord{}, fmt.Errorf("unknown chord %q", name)
+	}
+	return c, nil
+}
+
+func note(letter, accidental string) int {
+	n := naturalNotes[letter[0]]
+	switch accidental {
+	case "#", "♯":
+		n++
+	case "b", "♭":
+		n--
+	}
+	return (n + 12) % 12
+}
+
+// Тональность: основной тон и признак минора (Am, F#m, Ebmin)
+func parseKey(key string) (int, bool, error) {
+	c, err := parseChord(key)
+	if err != nil || c.special || c.hasBass {
+		return 0, false, fmt.Errorf("invalid key %q", key)
+	}
+	switch c.quality {
+	case "", "maj", "M":
+		return c.root, false, nil
+	case "m", "min", "mi":
+		return c.root, true, nil
+	}
+	return 0, false, fmt.Errorf("invalid key %q", key)
+}
+
+// Запись аккорда со сдвигом на semitones полутонов
+func (c chord) transpose(semitones int, flats bool) string {
+	if c.special {
+		return c.text
+	}
+	names := sharpNotes
+	if flats {
+		names = flatNotes
+	}
+	s := names[(c.root+semitones%12+12)%12] + c.quality
+	if c.hasBass {
+		s += "/" + names[(c.bass+semitones%12+12)%12]
+	}
+	return s
+}
+
+// Подготовка текста с аккордами к выдаче: сдвиг тональности на opts.Transpose полутонов и пересчёт
+// аппликатур под каподастр opts.Capo. Знаки выбираются по opts.Accidentals или по тональности,
+// в которой записаны аппликатуры; без сдвига и явного выбора аккорды не меняются
+func Arrange(sheet models.ChordSheet, opts models.ChordOptions) (models.ChordSheet, error) {
+	problems := map[string]string{}
+	if opts.Transpose < -maxTranspose || opts.Transpose > maxTranspose {
+		problems["transpose"] = fmt.Sprintf("must be from -%d to +%d semitones", maxTranspose, maxTranspose)
+	}
+	capo := sheet.Capo
+	if opts.Capo != nil {
+		capo = *opts.Capo
+		if capo < 0 || capo > maxCapo {
+			problems["capo"] = fmt.Sprintf("must be a fret number from 0 to %d", maxCapo)
+		}
+	}
+	switch opts.Accidentals {
+	case "", models.AccidentalsSharp, models.AccidentalsFlat:
+	default:
+		problems["accidentals"] = "must be sharp or flat"
+	}
+	if len(problems) > 0 {
+		return models.ChordSheet{}, &models.ValidationError{Fields: problems}
+	}
+
+	// Аппликатуры сдвигаются на изменение тональности и разницу положений каподастра
+	shift := opts.Transpose + sheet.Capo - capo
+	result := sheet
+	result.Capo = capo
+	if shift%12 == 0 && opts.Transpose%12 == 0 && opts.Accidentals == "" {
+		return result, nil
+	}
+
+	if sheet.Key != "" {
+		root, minor, _ := parseKey(sheet.Key)
+		key, _ := parseChord(sheet.Key)
+		result.Key = key.transpose(opts.Transpose, preferFlats(opts.Accidentals, root+opts.Transpose, minor))
+	}
+	root, minor := shapeKey(sheet, shift)
+	flats := preferFlats(opts.Accidentals, root, minor)
+
+	result.Sections = make([]models.ChordSection, len(sheet.Sections))
+	for i, s := range sheet.Sections {
+		section := s
+		section.Lines = make([]models.ChordLine, len(s.Lines))
+		for j, line := range s.Lines {
+			transposed := line
+			if len(line.Chords) > 0 {
+				transposed.Chords = make([]models.ChordPosition, len(line.Chords))
+				for k, cp := range line.Chords {
+					// Аккорды проверены при разборе
+					c, _ := parseChord(cp.Chord)
+					transposed.Chords[k] = models.ChordPosition{Chord: c.transpose(shift, flats), Position: cp.Position}
+				}
+			}
+			section.Lines[j] = transposed
+		}
+		result.Sections[i] = section
+	}
+	return result, nil
+}
+
+// Тональность аппликатур после сдвига: по директиве key или по первому аккорду
+func shapeKey(sheet models.ChordSheet, shift int) (int, bool) {
+	if sheet.Key != "" {
+		root, minor, _ := parseKey(sheet.Key)
+		return root - sheet.Capo + shift, minor
+	}
+	for _, s := range sheet.Sections {
+		for _, line := range s.Lines {
+			for _, cp := range line.Chords {
+				if c, err := parseChord(cp.Chord); err == nil && !c.special {
+					minor := strings.HasPrefix(c.quality, "m") && !strings.HasPrefix(c.quality, "maj")
+					return c.root + shift, minor
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+// Запись с бемолями: по явному выбору или по тональности
+func preferFlats(accidentals string, root int, minor bool) bool {
+	switch accidentals {
+	case models.AccidentalsFlat:
+		return true
+	case models.AccidentalsSharp:
+		return false
+	}
+	root = (root%12 + 12) % 12
+	if minor {
+		return flatMinorKeys[root]
+	}
+	return flatMajorKeys[root]
+}
@@ -0,0 +1,165 @@
+package lyrics
+
+import (
+	"Anastasia/songs/internal/models"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseChord(t *testing.T) {
+	tests := []struct {
+		name    string
+		root    int
+		quality string
+		bass    int
+		hasBass bool
+	}{
+		{"C", 0, "", 0, false},
+		{"C#m7b5", 1, "m7b5", 0, false},
+		{"Ebsus4", 3, "sus4", 0, false},
+		{"A♭m", 8, "m", 0, false},
+		{"Cb", 11, "", 0, false},
+		{"G7(b9)", 7, "7(b9)", 0, false},
+		{"Bø7", 11, "ø7", 0, false},
+		{"Cmaj7", 0, "maj7", 0, false},
+		{"D/F#", 2, "", 6, true},
+		{"Bbm/Ab", 10, "m", 8, true},
+		{"C6/9", 0, "6/9", 0, false},
+		{"Am7/G", 9, "m7", 7, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := parseChord(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.root != tt.root || c.quality != tt.quality || c.bass != tt.bass || c.hasBass != tt.hasBass {
+				t.Errorf("parseChord(%q) = %+v", tt.name, c)
+			}
+		})
+	}
+
+	for _, name := range []string{"N.C.", "%", "*Riff"} {
+		if c, err := parseChord(name); err != nil || !c.special {
+			t.Errorf("parseChord(%q) = %+v, %v, want a special chord", name, c, err)
+		}
+	}
+	for _, name := range []string{"", "H", "c", "Chorus", "C/H", "Cx"} {
+		if _, err := parseChord(name); err == nil {
+			t.Errorf("parseChord(%q) succeeded, want an error", name)
+		}
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		key   string
+		root  int
+		minor bool
+		ok    bool
+	}{
+		{"C", 0, false, true},
+		{"F#", 6, false, true},
+		{"Ebmin", 3, true, true},
+		{"Am", 9, true, true},
+		{"BbM", 10, false, true},
+		{"G7", 0, false, false},
+		{"C/E", 0, false, false},
+		{"N.C.", 0, false, false},
+	}
+	for _, tt := range tests {
+		root, minor, err := parseKey(tt.key)
+		if (err == nil) != tt.ok || root != tt.root || minor != tt.minor {
+			t.Errorf("parseKey(%q) = %d, %v, %v", tt.key, root, minor, err)
+		}
+	}
+}
+
+// Аккорды всех строк листа по порядку
+func sheetChords(sheet models.ChordSheet) string {
+	var chords []string
+	for _, s := range sheet.Sections {
+		for _, line := range s.Lines {
+			for _, c := range line.Chords {
+				chords = append(chords, c.Chord)
+			}
+		}
+	}
+	return strings.Join(chords, " ")
+}
+
+func TestArrange(t *testing.T) {
+	capo := func(fret int) *int { return &fret }
+	tests := []struct {
+		name     string
+		chordpro string
+		opts     models.ChordOptions
+		key      string
+		capo     int
+		chords   string
+	}{
+		{"no changes", "{key: C}\n[C]La [A#]la", models.ChordOptions{}, "C", 0, "C A#"},
+		{"up to a flat major key", "{key: C}\n[C]La [G]la [Am]la [F#dim]la", models.ChordOptions{Transpose: 1}, "Db", 0, "Db Ab Bbm Gdim"},
+		{"up to a sharp major key", "{key: C}\n[C]La [G]la [Bb]la", models.ChordOptions{Transpose: 6}, "F#", 0, "F# C# E"},
+		{"down an octave keeps chords", "{key: D}\n[D]La [Bb]la", models.ChordOptions{Transpose: -12}, "D", 0, "D Bb"},
+		{"to a flat minor key", "{key: Am}\n[Am]La [E7]la [G]la", models.ChordOptions{Transpose: -2}, "Gm", 0, "Gm D7 F"},
+		{"to a sharp minor key", "{key: Em}\n[Em]La [C]la [B7]la", models.ChordOptions{Transpose: 2}, "F#m", 0, "F#m D C#7"},
+		{"slash chords", "{key: C}\n[C/E]La [G/B]la [Am7/G]la [C6/9]la", models.ChordOptions{Transpose: 3}, "Eb", 0, "Eb/G Bb/D Cm7/Bb Eb6/9"},
+		{"slash chords to sharps", "{key: F}\n[Bb/D]La [F/C]la", models.ChordOptions{Transpose: 2}, "G", 0, "C/E G/D"},
+		{"special chords", "{key: C}\n[N.C.]La [*Riff]la [C]la", models.ChordOptions{Transpose: 2}, "D", 0, "N.C. *Riff D"},
+		{"explicit sharps", "{key: C}\n[C]La [F]la", models.ChordOptions{Transpose: 3, Accidentals: models.AccidentalsSharp}, "D#", 0, "D# G#"},
+		{"explicit flats without transposing", "{key: E}\n[E]La [G#m]la [C#m]la", models.ChordOptions{Accidentals: models.AccidentalsFlat}, "E", 0, "E Abm Dbm"},
+		{"key from the first chord", "[Em]La [G]la [D]la", models.ChordOptions{Transpose: 3}, "", 0, "Gm Bb F"},
+		{"capo added", "{key: G}\n[G]La [D]la [Em]la [C]la", models.ChordOptions{Capo: capo(3)}, "G", 3, "E B C#m A"},
+		{"capo moved to flat shapes", "{key: G}\n[G]La [D]la [C]la", models.ChordOptions{Capo: capo(2)}, "G", 2, "F C Bb"},
+		{"capo removed", "{key: A}\n{capo: 2}\n[G]La [D]la [Em]la", models.ChordOptions{Capo: capo(0)}, "A", 0, "A E F#m"},
+		{"transpose with capo", "{key: A}\n{capo: 2}\n[G]La [C]la", models.ChordOptions{Transpose: 1}, "Bb", 2, "Ab Db"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheet, err := ParseChordPro(tt.chordpro)
+			if err != nil {
+				t.Fatal(err)
+			}
+			original := sheetChords(sheet)
+			got, err := Arrange(sheet, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Key != tt.key || got.Capo != tt.capo {
+				t.Errorf("key, capo = %q, %d, want %q, %d", got.Key, got.Capo, tt.key, tt.capo)
+			}
+			if chords := sheetChords(got); chords != tt.chords {
+				t.Errorf("chords = %q, want %q", chords, tt.chords)
+			}
+			if chords := sheetChords(sheet); chords != original {
+				t.Errorf("source chords = %q, want %q unchanged", chords, original)
+			}
+		})
+	}
+}
+
+func TestArrangeValidation(t *testing.T) {
+	sheet, err := ParseChordPro("[C]La")
+	if err != nil {
+		t.Fatal(err)
+	}
+	capo := -1
+	_, err = Arrange(sheet, models.ChordOptions{Transpose: 13, Capo: &capo, Accidentals: "both"})
+	var validationErr *models.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("error = %v, want *models.ValidationError", err)
+	}
+	want := []string{"accidentals", "capo", "transpose"}
+	var fields []string
+	for _, field := range want {
+		if _, ok := validationErr.Fields[field]; ok {
+			fields = append(fields, field)
+		}
+	}
+	if !reflect.DeepEqual(fields, want) || len(validationErr.Fields) != len(want) {
+		t.Errorf("fields = %v, want %v", validationErr.Fields, want)
+	}
+}
@@ -0,0 +1,231 @@
+// Пакет mockinfo реализует заглушку внешнего сервиса с информацией о песнях (GET /info?group=&song=)
+// для локальной разработки и тестов: ответы берутся из файлов-фикстур, задержка и ошибки настраиваются
+package mockinfo
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+//go:embed fixtures/*.json
+var embedded embed.FS
+
+// Заголовок, которым клиент может запросить у заглушки конкретный статус ответа
+const StatusHeader = "X-Mock-Status"
+
+// Фикстура: данные одной песни. Поиск выполняется по group и song без учёта регистра и лишних пробелов
+type Fixture struct {
+	Group       string `json:"group"`
+	Song        string `json:"song"`
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// Ответ метода /info по контракту внешнего сервиса
+type SongDetail struct {
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// Настройки заглушки
+type Options struct {
+	// Фикстуры. Если не заданы, используются встроенные (DefaultFixtures)
+	Fixtures []Fixture
+	// Задержка перед ответом и её случайный разброс
+	Latency time.Duration
+	Jitter  time.Duration
+	// Доля запросов (от 0 до 1), на которые возвращается ErrorStatus
+	ErrorRate   float64
+	ErrorStatus int
+	// Количество первых запросов, на которые возвращается ErrorStatus (для проверки повторов)
+	FailFirst int
+}
+
+type Server struct {
+	opts     Options
+	fixtures map[string]Fixture
+	requests atomic.Int64
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// Создаёт заглушку внешнего сервиса
+func New(opts Options) *Server {
+	if opts.Fixtures == nil {
+		opts.Fixtures = DefaultFixtures()
+	}
+	if opts.ErrorStatus == 0 {
+		opts.ErrorStatus = http.StatusInternalServerError
+	}
+
+	s := &Server{
+		opts:     opts,
+		fixtures: make(map[string]Fixture, len(opts.Fixtures)),
+		rnd:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, f := range opts.Fixtures {
+		s.fixtures[key(f.Group, f.Song)] = f
+	}
+	return s
+}
+
+// Запускает заглушку на httptest-сервере. Адрес метода /info — URL() + "/info".
+// Сервер нужно остановить вызовом Close
+func NewTestServer(opts Options) *httptest.Server {
+	return httptest.NewServer(New(opts))
+}
+
+// Количество обработанных запросов
+func (s *Server) Requests() int {
+	return int(s.requests.Load())
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/info" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	n := int(s.requests.Add(1))
+	group, song := r.URL.Query().Get("group"), r.URL.Query().Get("song")
+	log := logrus.WithFields(logrus.Fields{
+		"group":   group,
+		"song":    song,
+		"request": n,
+	})
+
+	if err := s.delay(r); err != nil {
+		return
+	}
+
+	if status, err := strconv.Atoi(r.Header.Get(StatusHeader)); err == nil {
+		log.WithField("status", status).Info("Returning requested status")
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	if n <= s.opts.FailFirst || s.injectError() {
+		log.WithField("status", s.opts.ErrorStatus).Info("Injecting error")
+		http.Error(w, http.StatusText(s.opts.ErrorStatus), s.opts.ErrorStatus)
+		return
+	}
+
+	if group == "" || song == "" {
+		http.Error(w, "group and song are required", http.StatusBadRequest)
+		return
+	}
+
+	f, ok := s.fixtures[key(group, song)]
+	if !ok {
+		log.Info("Song not found")
+		http.NotFound(w, r)
+		return
+	}
+
+	log.Info("Returning song detail")
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(SongDetail{
+		ReleaseDate: f.ReleaseDate,
+		Text:        f.Text,
+		Link:        f.Link,
+	})
+	if err != nil {
+		log.WithError(err).Error("Failed to encode song detail")
+	}
+}
+
+func (s *Server) delay(r *http.Request) error {
+	d := s.opts.Latency
+	if s.opts.Jitter > 0 {
+		s.mu.Lock()
+		d += time.Duration(s.rnd.Int63n(int64(s.opts.Jitter)))
+		s.mu.Unlock()
+	}
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-r.Context().Done():
+		return r.Context().Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (s *Server) injectError() bool {
+	if s.opts.ErrorRate <= 0 {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rnd.Float64() < s.opts.ErrorRate
+}
+
+// Встроенные фикстуры
+func DefaultFixtures() []Fixture {
+	fixtures, err := LoadFixtures(embedded, "fixtures")
+	if err != nil {
+		panic(err)
+	}
+	return fixtures
+}
+
+// Загрузка фикстур из всех файлов *.json каталога dir. Файл может содержать
+// одну фикстуру или массив фикстур
+func LoadFixtures(fsys fs.FS, dir string) ([]Fixture, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var fixtures []Fixture
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		var list []Fixture
+		if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+			err = json.Unmarshal(data, &list)
+		} else {
+			var f Fixture
+			err = json.Unmarshal(data, &f)
+			list = append(list, f)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		fixtures = append(fixtures, list...)
+	}
+	return fixtures, nil
+}
+
+func key(group, song string) string {
+	normalize := func(s string) string {
+		return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	}
+	return normalize(group) + "\x00" + normalize(song)
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Запись журнала аудита изменяющих операций
+type AuditEntry struct {
+	ID        int64           `json:"id"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Actor     string          `json:"actor"`
+	Action    string          `json:"action"`
+	Entity    string          `json:"entity"`
+	EntityID  int             `json:"entityId"`
+	Before    json.RawMessage `json:"before" swaggertype:"object"`
+	After     json.RawMessage `json:"after" swaggertype:"object"`
+	RequestID string          `json:"requestId"`
+}
+
+// Фильтры журнала аудита. Пустые поля не учитываются
+type AuditFilter struct {
+	Actor     string
+	Action    string
+	Entity    string
+	EntityID  int
+	RequestID string
+	From      time.Time
+	To        time.Time
+}
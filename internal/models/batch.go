@@ -0,0 +1,35 @@
+package models
+
+// Статусы элементов пакетного добавления песен
+const (
+	BatchCreated    = "created"
+	BatchDuplicate  = "duplicate"
+	BatchInvalid    = "invalid"
+	BatchFailed     = "failed"
+	BatchRolledBack = "rolledBack"
+)
+
+// Результат добавления одной песни пакета
+type BatchItemResult struct {
+	Index      int    `json:"index"`
+	Status     string `json:"status"`
+	ID         int    `json:"id,omitempty"`
+	ExistingID int    `json:"existingId,omitempty"`
+	// Сходство названия с уже существующей песней (1 — совпадение нормализованных названий)
+	Similarity float64 `json:"similarity,omitempty"`
+	// Индекс предыдущего элемента пакета с той же песней
+	DuplicateOf *int              `json:"duplicateOf,omitempty"`
+	Error       string            `json:"error,omitempty"`
+	Fields      map[string]string `json:"fields,omitempty"`
+}
+
+// Результат пакетного добавления песен
+type BatchResult struct {
+	Atomic     bool              `json:"atomic"`
+	Committed  bool              `json:"committed"`
+	Created    int               `json:"created"`
+	Duplicates int               `json:"duplicates"`
+	Invalid    int               `json:"invalid"`
+	Failed     int               `json:"failed"`
+	Items      []BatchItemResult `json:"items"`
+}
@@ -0,0 +1,52 @@
+package models
+
+// Предпочтение знаков альтерации при записи аккордов
+const (
+	AccidentalsSharp = "sharp"
+	AccidentalsFlat  = "flat"
+)
+
+// Аккорд над строкой текста
+type ChordPosition struct {
+	Chord string `json:"chord"`
+	// Позиция в строке в символах (не байтах), начиная с 0
+	Position int `json:"position"`
+}
+
+// Строка текста с аккордами. Строка из одних аккордов имеет пустой Text
+type ChordLine struct {
+	Text   string          `json:"text"`
+	Chords []ChordPosition `json:"chords,omitempty"`
+	// Комментарий ({comment: ...}) вместо строки текста
+	Comment string `json:"comment,omitempty"`
+}
+
+// Часть текста с аккордами. Type — verse, chorus, bridge, tab, grid или пусто для блока без окружения
+type ChordSection struct {
+	Type  string      `json:"type,omitempty"`
+	Label string      `json:"label,omitempty"`
+	Lines []ChordLine `json:"lines"`
+	// Ссылка на припев ({chorus}): припев повторяется без аккордов
+	Repeat bool `json:"repeat,omitempty"`
+}
+
+// Текст песни с аккордами, разобранный из ChordPro
+type ChordSheet struct {
+	// Директивы метаданных: title, artist, album, tempo и т.д.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Тональность звучания после транспонирования
+	Key string `json:"key,omitempty"`
+	// Лад каподастра; аккорды записаны аппликатурами относительно него
+	Capo     int            `json:"capo,omitempty"`
+	Sections []ChordSection `json:"sections"`
+}
+
+// Параметры выдачи аккордов
+type ChordOptions struct {
+	// Сдвиг тональности в полутонах
+	Transpose int
+	// Лад каподастра; nil — как в сохранённом тексте
+	Capo *int
+	// sharp, flat или пусто — по тональности
+	Accidentals string
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Задача фонового получения данных песни из внешнего сервиса
+type EnrichmentJob struct {
+	ID       int `json:"id"`
+	SongID   int `json:"songId"`
+	Attempts int `json:"attempts"`
+	// Запрос в обход кэша с обновлением полей, ранее полученных из внешних источников
+	Refresh bool `json:"refresh"`
+}
+
+// Запуск повторного получения данных для отобранных песен
+type EnrichmentRun struct {
+	ID        int                 `json:"id"`
+	CreatedAt time.Time           `json:"createdAt"`
+	CreatedBy string              `json:"createdBy"`
+	Filters   Songs               `json:"filters"`
+	Refresh   bool                `json:"refresh"`
+	Total     int                 `json:"total"`
+	Pending   int                 `json:"pending"`
+	Done      int                 `json:"done"`
+	Failed    int                 `json:"failed"`
+	Items     []EnrichmentRunItem `json:"items"`
+}
+
+// Результат повторного получения данных для одной песни
+type EnrichmentRunItem struct {
+	SongID        int       `json:"songId"`
+	Status        string    `json:"status"`
+	Attempts      int       `json:"attempts"`
+	Error         string    `json:"error,omitempty"`
+	ChangedFields []string  `json:"changedFields"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// Параметры запуска повторного получения данных
+type EnrichmentRunRequest struct {
+	Filters Songs `json:"filters"`
+	// По умолчанию данные запрашиваются в обход кэша
+	Refresh *bool `json:"refresh,omitempty"`
+}
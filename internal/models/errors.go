@@ -0,0 +1,44 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Ошибка, возвращаемая при отсутствии запрошенной записи
+var ErrNotFound = errors.New("not found")
+
+// Ошибка, возвращаемая при нехватке прав на операцию
+var ErrForbidden = errors.New("forbidden")
+
+// Ошибка проверки данных песни: описание проблемы по каждому полю
+type ValidationError struct {
+	Fields map[string]string `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, 0, len(e.Fields))
+	for field := range e.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	problems := make([]string, 0, len(fields))
+	for _, field := range fields {
+		problems = append(problems, field+": "+e.Fields[field])
+	}
+	return "invalid song: " + strings.Join(problems, "; ")
+}
+
+// Ошибка добавления песни, которая уже есть в библиотеке
+type DuplicateError struct {
+	ExistingID int
+	// Сходство названий; 1 — нормализованные названия совпадают
+	Similarity float64
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("duplicate of song %d", e.ExistingID)
+}
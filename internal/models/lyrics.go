@@ -0,0 +1,132 @@
+package models
+
+// Типы частей текста песни
+const (
+	SectionVerse     = "verse"
+	SectionChorus    = "chorus"
+	SectionPreChorus = "pre-chorus"
+	SectionBridge    = "bridge"
+	SectionIntro     = "intro"
+	SectionOutro     = "outro"
+)
+
+// Часть текста песни: куплет, припев и т.д.
+type LyricsSection struct {
+	Type string `json:"type"`
+	// Номер части среди частей того же типа (куплет 1, куплет 2), начиная с 1
+	Number int `json:"number"`
+	// Метка из текста, например "[Chorus]"; пусто, если тип определён по повторам
+	Label string   `json:"label,omitempty"`
+	Lines []string `json:"lines"`
+	// Часть отмечена в тексте только меткой и повторяет предыдущую часть того же типа
+	Repeat bool `json:"repeat,omitempty"`
+}
+
+// Структурированный текст песни
+type Lyrics struct {
+	// Версия разбора; текст, разобранный старой версией, разбирается заново
+	Version  int             `json:"version"`
+	Sections []LyricsSection `json:"sections"`
+}
+
+// Строка текста песни с её номером. Строки нумеруются с 1 подряд по всем частям
+type LyricsLine struct {
+	Number        int    `json:"number"`
+	Text          string `json:"text"`
+	Section       string `json:"section"`
+	SectionNumber int    `json:"sectionNumber"`
+}
+
+// Выбор фрагмента текста: диапазон строк, часть или окрестность найденной строки
+type LyricsSelection struct {
+	// Диапазон строк From–To включительно; To = 0 — до конца текста
+	From, To int
+	// Тип части и её номер среди частей этого типа (0 — первая)
+	Section       string
+	SectionNumber int
+	// Искомая строка, номер совпадения (с 1) и количество строк до и после него
+	Query  string
+	Hit    int
+	Window int
+}
+
+// Фрагмент текста песни
+type LyricsExcerpt struct {
+	TotalLines int          `json:"totalLines"`
+	Lines      []LyricsLine `json:"lines"`
+	// Номера строк со всеми совпадениями при поиске
+	Matches []int `json:"matches,omitempty"`
+}
+
+// Слово строки синхронизированного текста с собственной меткой времени (расширенный LRC)
+type SyncedWord struct {
+	// Время начала в миллисекундах от начала песни
+	Time int64  `json:"time"`
+	Text string `json:"text"`
+}
+
+// Строка синхронизированного текста. Пустая строка обозначает паузу (конец куплета)
+type SyncedLine struct {
+	Time  int64        `json:"time"`
+	Text  string       `json:"text"`
+	Words []SyncedWord `json:"words,omitempty"`
+}
+
+// Синхронизированный по времени текст песни
+type SyncedLyrics struct {
+	// Теги LRC: ar, ti, al, length и т.д.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Lines    []SyncedLine      `json:"lines"`
+}
+
+// Разбор строки текста: язык, число слогов и рифма
+type LineAnalysis struct {
+	Number int    `json:"number"`
+	Text   string `json:"text"`
+	// ru, en или пусто, если букв нет
+	Language  string `json:"language,omitempty"`
+	Syllables int    `json:"syllables"`
+	// Буква рифмы в схеме части: строки с одинаковой буквой рифмуются
+	Rhyme string `json:"rhyme"`
+}
+
+// Разбор части текста: схема рифмовки и слоги по строкам
+type SectionAnalysis struct {
+	Type   string `json:"type"`
+	Number int    `json:"number"`
+	// Схема рифмовки, например ABAB
+	Scheme string `json:"scheme"`
+	// Название схемы, если все четверостишия части рифмуются одинаково: couplet (AABB),
+	// alternate (ABAB), enclosed (ABBA), monorhyme (AAAA) или ballad (ABCB)
+	Pattern          string         `json:"pattern,omitempty"`
+	AverageSyllables float64        `json:"averageSyllables"`
+	Lines            []LineAnalysis `json:"lines"`
+}
+
+// Разбор рифм и слогов текста песни
+type LyricsAnalysis struct {
+	Sections []SectionAnalysis `json:"sections"`
+}
+
+// Песня с похожим текстом
+type SimilarSong struct {
+	ID    int    `json:"id"`
+	Song  string `json:"song"`
+	Group string `json:"group"`
+	// Оценка сходства Жаккара текстов по шинглам из трёх слов, от 0 до 1
+	Similarity float64 `json:"similarity"`
+}
+
+// Песня-кандидат в похожие и сигнатура MinHash её текста
+type LyricsCandidate struct {
+	ID        int
+	Song      string
+	Group     string
+	Signature []uint32
+}
+
+// Текст песни, для которого ещё не вычислена сигнатура
+type UnsignedLyrics struct {
+	ID   int
+	Text string
+}
@@ -0,0 +1,35 @@
+package models
+
+// Статусы записей импортированного плейлиста
+const (
+	// Запись сопоставлена с песней, которая уже есть в библиотеке
+	PlaylistMatched = "matched"
+	// Для записи создана новая песня; остальные данные будут запрошены из внешних источников
+	PlaylistCreated = "created"
+	// По записи не удалось определить исполнителя и название
+	PlaylistUnresolved = "unresolved"
+	PlaylistFailed     = "failed"
+)
+
+// Запись плейлиста и результат её сопоставления с библиотекой
+type PlaylistEntry struct {
+	// Номер записи в плейлисте, начиная с 1
+	Position int    `json:"position"`
+	Location string `json:"location,omitempty"`
+	Group    string `json:"group"`
+	Song     string `json:"song"`
+	// Год или дата выхода, если они есть в плейлисте
+	ReleaseDate string `json:"releaseDate,omitempty"`
+	Status      string `json:"status"`
+	ID          int    `json:"id,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Итог импорта плейлиста
+type PlaylistImport struct {
+	Matched    int             `json:"matched"`
+	Created    int             `json:"created"`
+	Unresolved int             `json:"unresolved"`
+	Failed     int             `json:"failed"`
+	Entries    []PlaylistEntry `json:"entries"`
+}
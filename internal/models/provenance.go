@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Источник значений полей, заданных пользователем через API
+const SourceUser = "user"
+
+// Источник значений полей, восстановленных откатом к ревизии
+const SourceRevert = "revert"
+
+// Источник текста, полученного из синхронизированного текста (LRC)
+const SourceSynced = "lrc"
+
+// Источник текста, полученного из текста с аккордами (ChordPro)
+const SourceChordPro = "chordpro"
+
+// Источник значений полей, перенесённых из объединённой с песней песни-дубликата
+const SourceMerge = "merge"
+
+// Источник, из которого получено текущее значение поля песни
+type FieldSource struct {
+	Field     string    `json:"field"`
+	Source    string    `json:"source"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Результат получения данных песни: итоговая песня, источники изменённых полей
+// и предупреждения (недоступные источники, отклонённые политикой значения)
+type Enrichment struct {
+	Song     Songs             `json:"song"`
+	Sources  map[string]string `json:"sources"`
+	Warnings []string          `json:"warnings"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Ревизия песни: кто и когда изменил какие поля
+type Revision struct {
+	ID           int           `json:"id"`
+	SongID       int           `json:"songId"`
+	Revision     int           `json:"revision"`
+	Author       string        `json:"author"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	Changes      []FieldChange `json:"changes"`
+	RevertedFrom *int          `json:"revertedFrom,omitempty"`
+	Snapshot     Songs         `json:"snapshot"`
+	// Ревизия перенесена из истории песни-дубликата при объединении: ID дубликата и исходный номер ревизии
+	MergedFrom     *int `json:"mergedFrom,omitempty"`
+	MergedRevision *int `json:"mergedRevision,omitempty"`
+}
+
+// Изменение одного поля песни
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Построчный дифф текста песни между двумя ревизиями
+type LyricsDiff struct {
+	SongID int        `json:"songId"`
+	From   int        `json:"from"`
+	To     int        `json:"to"`
+	Lines  []DiffLine `json:"lines"`
+}
+
+// Строка диффа: op принимает значения equal, insert, delete
+type DiffLine struct {
+	Op      string `json:"op"`
+	Text    string `json:"text"`
+	OldLine int    `json:"oldLine,omitempty"`
+	NewLine int    `json:"newLine,omitempty"`
+}
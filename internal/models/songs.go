@@ -1,10 +1,53 @@
 package models
 
 type Songs struct {
-	ID          int    `json:"id"`
-	Group       string `json:"group"`
-	Song        string `json:"song"`
-	ReleaseDate string `json:"releaseDate"`
-	Text        string `json:"text"`
-	Link        string `json:"link"`
+	ID               int    `json:"id"`
+	Group            string `json:"group"`
+	Song             string `json:"song"`
+	ReleaseDate      string `json:"releaseDate"`
+	Text             string `json:"text"`
+	Link             string `json:"link"`
+	EnrichmentStatus string `json:"enrichmentStatus,omitempty"`
+}
+
+// Статусы получения данных песни из внешнего сервиса
+const (
+	EnrichmentPending  = "pending"
+	EnrichmentEnriched = "enriched"
+	EnrichmentFailed   = "failed"
+	// Данные заданы вручную, внешние источники не запрашиваются
+	EnrichmentManual = "manual"
+)
+
+// Добавленная песня и песни, текст которых почти совпадает с её текстом
+type CreatedSong struct {
+	Songs
+	PossibleDuplicates []SimilarSong `json:"possibleDuplicates,omitempty"`
+}
+
+// Проверка дубликатов при добавлении песни
+type DuplicateCheck struct {
+	// Добавить песню, даже если такая уже есть
+	Skip bool
+	// Порог сходства названий песен одной группы (0–1); 0 — только совпадение нормализованных названий
+	MinSimilarity float64
+}
+
+// Ответ на добавление песни, которая уже есть в библиотеке
+type DuplicateSong struct {
+	Error      string  `json:"error"`
+	ExistingID int     `json:"existingId"`
+	Similarity float64 `json:"similarity"`
+}
+
+// Результат объединения песни-дубликата с основной песней
+type SongMerge struct {
+	Song     Songs `json:"song"`
+	MergedID int   `json:"mergedId"`
+	// Пустые поля основной песни, заполненные значениями дубликата
+	FilledFields []string `json:"filledFields"`
+	// Языки переводов, перенесённых с дубликата
+	MovedTranslations []string `json:"movedTranslations"`
+	// Количество ревизий дубликата, перенесённых в историю основной песни
+	MovedRevisions int `json:"movedRevisions"`
 }
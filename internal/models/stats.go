@@ -0,0 +1,61 @@
+package models
+
+// Слово и количество его употреблений
+type WordCount struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// Метрики текста песни или всех песен группы
+type LyricsStats struct {
+	Words       int `json:"words"`
+	UniqueWords int `json:"uniqueWords"`
+	// Отношение числа уникальных слов к числу слов (type-token ratio)
+	LexicalDiversity float64 `json:"lexicalDiversity"`
+	Lines            int     `json:"lines"`
+	UniqueLines      int     `json:"uniqueLines"`
+	// Доля строк, повторяющих более раннюю строку той же песни
+	RepetitionRatio float64 `json:"repetitionRatio"`
+	// Средняя длина строки в словах и в символах
+	AverageLineWords float64 `json:"averageLineWords"`
+	AverageLineChars float64 `json:"averageLineChars"`
+	// Самые частые слова без стоп-слов русского и английского языков
+	TopWords []WordCount `json:"topWords"`
+}
+
+// Статистика текста песни
+type SongLyricsStats struct {
+	ID    int    `json:"id"`
+	Song  string `json:"song"`
+	Group string `json:"group"`
+	LyricsStats
+}
+
+// Статистика текстов всех песен группы
+type GroupLyricsStats struct {
+	Group string `json:"group"`
+	Songs int    `json:"songs"`
+	LyricsStats
+}
+
+// Подсчёт слов и строк текста, из которого вычисляются метрики; хранится в кэше
+type LyricsTally struct {
+	// Хэш текста и версия подсчёта, для которых выполнен подсчёт
+	Hash    string `json:"-"`
+	Version int    `json:"-"`
+	// Количество употреблений каждого слова
+	Words         map[string]int `json:"words"`
+	Lines         int            `json:"lines"`
+	RepeatedLines int            `json:"repeatedLines"`
+	// Сумма длин строк в символах
+	LineChars int `json:"lineChars"`
+}
+
+// Текст песни и его кэшированный подсчёт (nil, если подсчёта нет)
+type LyricsStatsSource struct {
+	ID    int
+	Song  string
+	Group string
+	Text  string
+	Tally *LyricsTally
+}
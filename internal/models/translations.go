@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Перевод текста песни на другой язык, выровненный по строкам оригинала
+type Translation struct {
+	// Код языка, например "en" или "en-gb"
+	Language  string    `json:"language"`
+	Text      string    `json:"text,omitempty"`
+	Author    string    `json:"author"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	// Текст оригинала изменился после сохранения перевода
+	Outdated bool `json:"outdated"`
+	// Хэш текста оригинала на момент сохранения перевода
+	SourceHash string `json:"-"`
+}
+
+// Строка двуязычного текста
+type BilingualLine struct {
+	Number      int    `json:"number"`
+	Original    string `json:"original"`
+	Translation string `json:"translation"`
+}
+
+// Часть двуязычного текста
+type BilingualSection struct {
+	Type   string          `json:"type"`
+	Number int             `json:"number"`
+	Lines  []BilingualLine `json:"lines"`
+}
+
+// Текст песни с переводом, чередующимся по строкам
+type BilingualLyrics struct {
+	Language string             `json:"language"`
+	Outdated bool               `json:"outdated"`
+	Sections []BilingualSection `json:"sections"`
+}
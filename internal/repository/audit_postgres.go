@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/reqctx"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// Действия, записываемые в журнал аудита
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+	ActionRevert = "revert"
+	ActionMerge  = "merge"
+)
+
+// Сущности, изменения которых записываются в журнал аудита
+const (
+	EntitySong          = "song"
+	EntityEnrichmentRun = "enrichment_run"
+	EntitySyncedLyrics  = "synced_lyrics"
+	EntityTranslation   = "translation"
+	EntityChords        = "chords"
+)
+
+type AuditRepo struct {
+	db *pgxpool.Pool
+}
+
+// Создаёт новый экземпляр репозитория журнала аудита
+func NewAuditRepo(db *pgxpool.Pool) *AuditRepo {
+	return &AuditRepo{
+		db: db,
+	}
+}
+
+// Получение записей журнала аудита с фильтрацией и пагинацией
+func (a *AuditRepo) Audit(ctx context.Context, filter models.AuditFilter, page, pageSize int) ([]models.AuditEntry, error) {
+	logrus.WithFields(logrus.Fields{
+		"filter":   filter,
+		"page":     page,
+		"pageSize": pageSize,
+	}).Debug("Fetching audit log")
+
+	var conditions []string
+	var args []interface{}
+	addCondition := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(args))))
+	}
+
+	if filter.Actor != "" {
+		addCondition("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		addCondition("action = ?", filter.Action)
+	}
+	if filter.Entity != "" {
+		addCondition("entity = ?", filter.Entity)
+	}
+	if filter.EntityID != 0 {
+		addCondition("entity_id = ?", filter.EntityID)
+	}
+	if filter.RequestID != "" {
+		addCondition("request_id = ?", filter.RequestID)
+	}
+	if !filter.From.IsZero() {
+		addCondition("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		addCondition("created_at < ?", filter.To)
+	}
+
+	query := `
+		SELECT id, created_at, actor, action, entity, entity_id, before, after, request_id
+		FROM audit_log`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, pageSize, (page-1)*pageSize)
+	query += " ORDER BY id DESC LIMIT $" + strconv.Itoa(len(args)-1) + " OFFSET $" + strconv.Itoa(len(args))
+
+	rows, err := a.db.Query(ctx, query, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to query audit log")
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var entry models.AuditEntry
+		var before, after []byte
+		err := rows.Scan(
+			&entry.ID,
+			&entry.CreatedAt,
+			&entry.Actor,
+			&entry.Action,
+			&entry.Entity,
+			&entry.EntityID,
+			&before,
+			&after,
+			&entry.RequestID,
+		)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to scan audit row")
+			return nil, err
+		}
+		entry.Before = before
+		entry.After = after
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Error occurred while iterating over rows")
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Запись в журнал аудита в рамках транзакции изменяющей операции.
+// Пустой снимок (nil) сохраняется как NULL
+func insertAudit(ctx context.Context, tx pgx.Tx, action, entity string, entityID int, before, after interface{}) error {
+	beforeJSON, err := auditSnapshot(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := auditSnapshot(after)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO audit_log (actor, action, entity, entity_id, before, after, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, reqctx.User(ctx), action, entity, entityID, beforeJSON, afterJSON, reqctx.RequestID(ctx))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to write audit log")
+		return err
+	}
+	return nil
+}
+
+func auditSnapshot(v interface{}) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
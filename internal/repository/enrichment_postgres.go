@@ -0,0 +1,385 @@
+package repository
+
+import (
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/reqctx"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// Статусы задач очереди получения данных
+const (
+	jobPending = "pending"
+	jobDone    = "done"
+	jobFailed  = "failed"
+)
+
+type EnrichmentRepo struct {
+	db *pgxpool.Pool
+}
+
+// Создаёт новый экземпляр репозитория очереди получения данных
+func NewEnrichmentRepo(db *pgxpool.Pool) *EnrichmentRepo {
+	return &EnrichmentRepo{
+		db: db,
+	}
+}
+
+// Захват очередной задачи, срок выполнения которой наступил. Задача не блокируется на время
+// обработки: вместо этого её следующий запуск откладывается на lease, и если обработчик упадёт,
+// задачу подхватит другой. Параллельные обработчики пропускают уже захваченные строки (SKIP LOCKED).
+// Если задач нет, возвращается models.ErrNotFound
+func (e *EnrichmentRepo) ClaimEnrichmentJob(ctx context.Context, lease time.Duration) (models.EnrichmentJob, models.Songs, error) {
+	var job models.EnrichmentJob
+	err := e.db.QueryRow(ctx, `
+		UPDATE enrichment_jobs
+		SET attempts = attempts + 1, run_at = NOW() + make_interval(secs => $2), updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM enrichment_jobs
+			WHERE status = $1 AND run_at <= NOW()
+			ORDER BY run_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, song_id, attempts, refresh
+	`, jobPending, lease.Seconds()).Scan(&job.ID, &job.SongID, &job.Attempts, &job.Refresh)
+	if err != nil {
+		return models.EnrichmentJob{}, models.Songs{}, notFound(err)
+	}
+
+	var song models.Songs
+	err = e.db.QueryRow(ctx, `
+		SELECT s.id, s.name, g.name, s.release_date, s.text, s.link, s.enrichment_status
+		FROM songs s
+		INNER JOIN groups g ON s.group_id = g.id
+		WHERE s.id = $1
+	`, job.SongID).Scan(&song.ID, &song.Song, &song.Group, &song.ReleaseDate, &song.Text, &song.Link, &song.EnrichmentStatus)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch song for enrichment")
+		return models.EnrichmentJob{}, models.Songs{}, notFound(err)
+	}
+
+	return job, song, nil
+}
+
+// Сохранение полученных данных: песня обновляется (с ревизией, источниками полей и записью
+// в журнале аудита) и переходит в статус enriched, задача завершается
+func (e *EnrichmentRepo) CompleteEnrichmentJob(ctx context.Context, jobID int, patch models.Songs, sources map[string]string) error {
+	tx, err := e.db.Begin(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction")
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := songForUpdate(ctx, tx, patch.ID)
+	if err != nil {
+		return err
+	}
+
+	rev, err := saveSong(ctx, tx, current, applyPatch(current, patch), nil, sources)
+	if err != nil {
+		return err
+	}
+
+	err = finishEnrichment(ctx, tx, jobID, patch.ID, jobDone, models.EnrichmentEnriched, "")
+	if err != nil {
+		return err
+	}
+
+	changed := []string{}
+	for _, change := range rev.Changes {
+		changed = append(changed, change.Field)
+	}
+	changedJSON, err := json.Marshal(changed)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE enrichment_jobs SET changed_fields = $2 WHERE id = $1
+	`, jobID, changedJSON)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to save changed fields")
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction")
+		return err
+	}
+	return nil
+}
+
+// Перенос задачи на более позднее время после неудачной попытки
+func (e *EnrichmentRepo) RetryEnrichmentJob(ctx context.Context, jobID int, runAt time.Time, reason string) error {
+	_, err := e.db.Exec(ctx, `
+		UPDATE enrichment_jobs
+		SET run_at = $2, last_error = $3, updated_at = NOW()
+		WHERE id = $1
+	`, jobID, runAt, reason)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to reschedule enrichment job")
+		return err
+	}
+	return nil
+}
+
+// Окончательная неудача: задача и песня переходят в статус failed. Если песня удалена, возвращается models.ErrNotFound
+func (e *EnrichmentRepo) FailEnrichmentJob(ctx context.Context, jobID, songID int, reason string) error {
+	tx, err := e.db.Begin(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction")
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = finishEnrichment(ctx, tx, jobID, songID, jobFailed, models.EnrichmentFailed, reason)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction")
+		return err
+	}
+	return nil
+}
+
+// Запуск повторного получения данных для всех песен, подходящих под фильтры списка.
+// Песни переходят в статус pending и ставятся в очередь; песни, у которых уже есть
+// невыполненная задача, пропускаются
+func (e *EnrichmentRepo) CreateEnrichmentRun(ctx context.Context, filters models.Songs, refresh bool) (models.EnrichmentRun, error) {
+	logrus.WithFields(logrus.Fields{
+		"filters": filters,
+		"refresh": refresh,
+	}).Debug("Creating enrichment run")
+
+	filtersJSON, err := json.Marshal(filters)
+	if err != nil {
+		return models.EnrichmentRun{}, err
+	}
+
+	tx, err := e.db.Begin(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction")
+		return models.EnrichmentRun{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Запуски создаются по очереди, чтобы параллельные запуски не поставили одну песню в очередь дважды
+	_, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('enrichment_runs'))`)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to lock enrichment runs")
+		return models.EnrichmentRun{}, err
+	}
+
+	run := models.EnrichmentRun{
+		CreatedBy: reqctx.User(ctx),
+		Filters:   filters,
+		Refresh:   refresh,
+		Items:     []models.EnrichmentRunItem{},
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO enrichment_runs (created_by, filters, refresh)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`, run.CreatedBy, filtersJSON, refresh).Scan(&run.ID, &run.CreatedAt)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to insert enrichment run")
+		return models.EnrichmentRun{}, err
+	}
+
+	// Песни, созданные вручную, попадают в запуск, только если отобраны по статусу явно
+	args := append(songsFilterArgs(filters), jobPending, run.ID, refresh, models.EnrichmentManual)
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO enrichment_jobs (song_id, status, run_id, refresh)
+		SELECT s.id, $7::VARCHAR, $8::INTEGER, $9::BOOLEAN
+		FROM songs s
+		INNER JOIN groups g ON s.group_id = g.id
+		WHERE `+songsFilterSQL+` AND
+		(s.enrichment_status <> $10 OR $6 = $10) AND
+		NOT EXISTS (SELECT 1 FROM enrichment_jobs j WHERE j.song_id = s.id AND j.status = $7)`, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to enqueue enrichment jobs")
+		return models.EnrichmentRun{}, err
+	}
+	run.Total = int(tag.RowsAffected())
+	run.Pending = run.Total
+
+	_, err = tx.Exec(ctx, `
+		UPDATE songs SET enrichment_status = $2
+		WHERE id IN (SELECT song_id FROM enrichment_jobs WHERE run_id = $1)
+	`, run.ID, models.EnrichmentPending)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to update songs enrichment status")
+		return models.EnrichmentRun{}, err
+	}
+
+	err = insertAudit(ctx, tx, ActionCreate, EntityEnrichmentRun, run.ID, nil, run)
+	if err != nil {
+		return models.EnrichmentRun{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction")
+		return models.EnrichmentRun{}, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"runId": run.ID,
+		"total": run.Total,
+	}).Debug("Enrichment run created successfully")
+	return run, nil
+}
+
+// Получение запуска повторного получения данных с результатами по каждой песне
+func (e *EnrichmentRepo) EnrichmentRun(ctx context.Context, id int) (models.EnrichmentRun, error) {
+	logrus.WithField("runId", id).Debug("Fetching enrichment run")
+
+	run := models.EnrichmentRun{Items: []models.EnrichmentRunItem{}}
+	var filtersJSON []byte
+	err := e.db.QueryRow(ctx, `
+		SELECT id, created_at, created_by, filters, refresh
+		FROM enrichment_runs
+		WHERE id = $1
+	`, id).Scan(&run.ID, &run.CreatedAt, &run.CreatedBy, &filtersJSON, &run.Refresh)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch enrichment run")
+		return models.EnrichmentRun{}, notFound(err)
+	}
+	if err := json.Unmarshal(filtersJSON, &run.Filters); err != nil {
+		return models.EnrichmentRun{}, err
+	}
+
+	rows, err := e.db.Query(ctx, `
+		SELECT song_id, status, attempts, last_error, changed_fields, updated_at
+		FROM enrichment_jobs
+		WHERE run_id = $1
+		ORDER BY song_id
+	`, id)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to query enrichment run items")
+		return models.EnrichmentRun{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.EnrichmentRunItem
+		var changedJSON []byte
+		err := rows.Scan(&item.SongID, &item.Status, &item.Attempts, &item.Error, &changedJSON, &item.UpdatedAt)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to scan enrichment run item")
+			return models.EnrichmentRun{}, err
+		}
+		if err := json.Unmarshal(changedJSON, &item.ChangedFields); err != nil {
+			return models.EnrichmentRun{}, err
+		}
+
+		switch item.Status {
+		case jobDone:
+			run.Done++
+		case jobFailed:
+			run.Failed++
+		default:
+			run.Pending++
+		}
+		run.Items = append(run.Items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Error occurred while iterating over rows")
+		return models.EnrichmentRun{}, err
+	}
+
+	run.Total = len(run.Items)
+	return run, nil
+}
+
+// Получение сохранённого ответа источника. Если ответа нет или он устарел, возвращается models.ErrNotFound
+func (e *EnrichmentRepo) CachedResponse(ctx context.Context, provider, groupKey, songKey string) (models.Songs, error) {
+	var response []byte
+	err := e.db.QueryRow(ctx, `
+		SELECT response FROM enrichment_cache
+		WHERE provider = $1 AND group_key = $2 AND song_key = $3 AND expires_at > NOW()
+	`, provider, groupKey, songKey).Scan(&response)
+	if err != nil {
+		return models.Songs{}, notFound(err)
+	}
+
+	var detail models.Songs
+	if err := json.Unmarshal(response, &detail); err != nil {
+		return models.Songs{}, err
+	}
+	return detail, nil
+}
+
+// Сохранение ответа источника на время ttl
+func (e *EnrichmentRepo) SaveCachedResponse(ctx context.Context, provider, groupKey, songKey string, detail models.Songs, ttl time.Duration) error {
+	response, err := json.Marshal(detail)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.db.Exec(ctx, `
+		INSERT INTO enrichment_cache (provider, group_key, song_key, response, expires_at)
+		VALUES ($1, $2, $3, $4, NOW() + make_interval(secs => $5))
+		ON CONFLICT (provider, group_key, song_key)
+		DO UPDATE SET response = EXCLUDED.response, fetched_at = NOW(), expires_at = EXCLUDED.expires_at
+	`, provider, groupKey, songKey, response, ttl.Seconds())
+	if err != nil {
+		logrus.WithError(err).Error("Failed to save cached enrichment response")
+		return err
+	}
+	return nil
+}
+
+// Постановка песни в очередь получения данных
+func enqueueEnrichment(ctx context.Context, tx pgx.Tx, songID int) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO enrichment_jobs (song_id, status)
+		VALUES ($1, $2)
+	`, songID, jobPending)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to enqueue enrichment job")
+		return err
+	}
+	return nil
+}
+
+// Завершение задачи: песня переходит в статус songStatus, смена статуса записывается в журнал аудита
+func finishEnrichment(ctx context.Context, tx pgx.Tx, jobID, songID int, jobStatus, songStatus, reason string) error {
+	before, err := songForUpdate(ctx, tx, songID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE enrichment_jobs
+		SET status = $2, last_error = $3, updated_at = NOW()
+		WHERE id = $1
+	`, jobID, jobStatus, reason)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to update enrichment job")
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE songs SET enrichment_status = $2 WHERE id = $1
+	`, songID, songStatus)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to update song enrichment status")
+		return err
+	}
+
+	if before.EnrichmentStatus == songStatus {
+		return nil
+	}
+	after := before
+	after.EnrichmentStatus = songStatus
+	return insertAudit(ctx, tx, ActionUpdate, EntitySong, songID, before, after)
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"Anastasia/songs/internal/models"
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Количество строк, получаемых из курсора за один запрос
+const exportFetchSize = 500
+
+// Выгрузка всех песен, подходящих под фильтры списка. Строки читаются из серверного курсора
+// порциями и по одной передаются в fn, поэтому выборка целиком в памяти не хранится.
+// Ошибка fn прерывает выгрузку и возвращается как есть
+func (s *SongRepo) ExportSongs(ctx context.Context, filters models.Songs, fn func(models.Songs) error) error {
+	logrus.WithField("filters", filters).Debug("Exporting songs")
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly, IsoLevel: pgx.RepeatableRead})
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin export transaction")
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		DECLARE songs_export NO SCROLL CURSOR FOR
+		SELECT s.id, s.name, g.name, s.release_date, s.text, s.link, s.enrichment_status
+		FROM songs s
+		INNER JOIN groups g ON s.group_id = g.id
+		WHERE `+songsFilterSQL+`
+		ORDER BY s.id
+	`, songsFilterArgs(filters)...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to declare export cursor")
+		return err
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM songs_export", exportFetchSize)
+	total := 0
+	for {
+		n, err := fetchSongs(ctx, tx, fetch, fn)
+		if err != nil {
+			return err
+		}
+		total += n
+		if n < exportFetchSize {
+			break
+		}
+	}
+
+	logrus.WithField("count", total).Debug("Exported songs successfully")
+	return tx.Commit(ctx)
+}
+
+// Чтение одной порции строк из курсора; возвращает количество прочитанных строк
+func fetchSongs(ctx context.Context, tx pgx.Tx, query string, fn func(models.Songs) error) (int, error) {
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch from export cursor")
+		return 0, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		var song models.Songs
+		err := rows.Scan(
+			&song.ID,
+			&song.Song,
+			&song.Group,
+			&song.ReleaseDate,
+			&song.Text,
+			&song.Link,
+			&song.EnrichmentStatus,
+		)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to scan song row")
+			return n, err
+		}
+		n++
+		if err := fn(song); err != nil {
+			return n, err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Error occurred while iterating over rows")
+		return n, err
+	}
+	return n, nil
+}
@@ -0,0 +1,354 @@
+package repository
+
+import (
+	"Anastasia/songs/internal/lyrics"
+	"Anastasia/songs/internal/models"
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+type LyricsRepo struct {
+	db *pgxpool.Pool
+}
+
+// Создаёт новый экземпляр репозитория текстов песен
+func NewLyricsRepo(db *pgxpool.Pool) *LyricsRepo {
+	return &LyricsRepo{
+		db: db,
+	}
+}
+
+// Получение текста песни и его сохранённого разбора. Если разбора нет, Version равна 0
+func (s *LyricsRepo) Lyrics(ctx context.Context, songID int) (string, models.Lyrics, error) {
+	logrus.WithField("id", songID).Debug("Fetching song lyrics")
+
+	var text string
+	var data []byte
+	err := s.db.QueryRow(ctx, `
+		SELECT text, lyrics
+		FROM songs
+		WHERE id = $1
+	`, songID).Scan(&text, &data)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch song lyrics")
+		return "", models.Lyrics{}, notFound(err)
+	}
+
+	var parsed models.Lyrics
+	if data != nil {
+		if err := json.Unmarshal(data, &parsed); err != nil {
+			logrus.WithError(err).Warn("Failed to decode stored lyrics, ignoring")
+			parsed = models.Lyrics{}
+		}
+	}
+	return text, parsed, nil
+}
+
+// Сохранение разбора текста, выполненного заново (например, после смены версии разбора)
+func (s *LyricsRepo) SaveLyrics(ctx context.Context, songID int, text string, parsed models.Lyrics) error {
+	data, err := json.Marshal(parsed)
+	if err != nil {
+		return err
+	}
+
+	// Текст мог измениться с момента чтения — тогда разбор уже записан вместе с ним
+	_, err = s.db.Exec(ctx, `
+		UPDATE songs
+		SET lyrics = $1
+		WHERE id = $2 AND text = $3
+	`, data, songID, text)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to save song lyrics")
+	}
+	return err
+}
+
+// Разбор текста для записи в столбец lyrics вместе с текстом
+func parseLyrics(text string) []byte {
+	data, err := json.Marshal(lyrics.Parse(text))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode song lyrics")
+		return nil
+	}
+	return data
+}
+
+// Получение синхронизированного текста песни в формате LRC
+func (s *LyricsRepo) SyncedLyrics(ctx context.Context, songID int) (string, error) {
+	logrus.WithField("id", songID).Debug("Fetching synced lyrics")
+
+	var lrc *string
+	err := s.db.QueryRow(ctx, `
+		SELECT synced_lyrics
+		FROM songs
+		WHERE id = $1
+	`, songID).Scan(&lrc)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch synced lyrics")
+		return "", notFound(err)
+	}
+	if lrc == nil {
+		return "", models.ErrNotFound
+	}
+	return *lrc, nil
+}
+
+// Сохранение синхронизированного текста и полученного из него обычного текста.
+// Изменение текста записывается как обычное изменение песни (ревизия, аудит, источник lrc)
+func (s *LyricsRepo) SaveSyncedLyrics(ctx context.Context, songID int, lrc, text string) error {
+	logrus.WithField("id", songID).Debug("Saving synced lyrics")
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction")
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := songForUpdate(ctx, tx, songID)
+	if err != nil {
+		return err
+	}
+	previous, err := lockedSyncedLyrics(ctx, tx, songID)
+	if err != nil {
+		return err
+	}
+
+	// Сохраняется до изменения текста, чтобы saveSong не счёл его устаревшим
+	_, err = tx.Exec(ctx, `UPDATE songs SET synced_lyrics = $1 WHERE id = $2`, lrc, songID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to save synced lyrics")
+		return err
+	}
+
+	updated := current
+	updated.Text = text
+	_, err = saveSong(ctx, tx, current, updated, nil, map[string]string{"text": models.SourceSynced})
+	if err != nil {
+		return err
+	}
+
+	action, before := ActionCreate, interface{}(nil)
+	if previous != nil {
+		action, before = ActionUpdate, *previous
+	}
+	err = insertAudit(ctx, tx, action, EntitySyncedLyrics, songID, before, lrc)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction")
+		return err
+	}
+	return nil
+}
+
+// Удаление синхронизированного текста; обычный текст песни сохраняется
+func (s *LyricsRepo) DeleteSyncedLyrics(ctx context.Context, songID int) error {
+	logrus.WithField("id", songID).Debug("Deleting synced lyrics")
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction")
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := songForUpdate(ctx, tx, songID); err != nil {
+		return err
+	}
+	previous, err := lockedSyncedLyrics(ctx, tx, songID)
+	if err != nil {
+		return err
+	}
+	if previous == nil {
+		return models.ErrNotFound
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE songs SET synced_lyrics = NULL WHERE id = $1`, songID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to delete synced lyrics")
+		return err
+	}
+
+	err = insertAudit(ctx, tx, ActionDelete, EntitySyncedLyrics, songID, *previous, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction")
+		return err
+	}
+	return nil
+}
+
+// Текущий синхронизированный текст песни, заблокированной songForUpdate; nil, если его нет
+func lockedSyncedLyrics(ctx context.Context, tx pgx.Tx, songID int) (*string, error) {
+	var lrc *string
+	err := tx.QueryRow(ctx, `SELECT synced_lyrics FROM songs WHERE id = $1`, songID).Scan(&lrc)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch synced lyrics")
+		return nil, notFound(err)
+	}
+	return lrc, nil
+}
+
+// Удаление синхронизированного текста и текста с аккордами, которые больше не соответствуют
+// изменённому тексту песни. Каждое удаление записывается в аудит
+func resetStaleLyrics(ctx context.Context, tx pgx.Tx, songID int, text string) error {
+	for _, derived := range []struct {
+		column, entity string
+		locked         func(context.Context, pgx.Tx, int) (*string, error)
+		matches        func(string, string) bool
+	}{
+		{"synced_lyrics", EntitySyncedLyrics, lockedSyncedLyrics, lyrics.SyncedMatches},
+		{"chords", EntityChords, lockedChords, lyrics.ChordsMatch},
+	} {
+		previous, err := derived.locked(ctx, tx, songID)
+		if err != nil {
+			return err
+		}
+		if previous == nil || derived.matches(*previous, text) {
+			continue
+		}
+
+		_, err = tx.Exec(ctx, `UPDATE songs SET `+derived.column+` = NULL WHERE id = $1`, songID)
+		if err != nil {
+			logrus.WithError(err).WithField("column", derived.column).Error("Failed to reset stale lyrics")
+			return err
+		}
+		err = insertAudit(ctx, tx, ActionDelete, derived.entity, songID, *previous, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Получение текста песни с аккордами в формате ChordPro
+func (s *LyricsRepo) Chords(ctx context.Context, songID int) (string, error) {
+	logrus.WithField("id", songID).Debug("Fetching chords")
+
+	var chordpro *string
+	err := s.db.QueryRow(ctx, `
+		SELECT chords
+		FROM songs
+		WHERE id = $1
+	`, songID).Scan(&chordpro)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch chords")
+		return "", notFound(err)
+	}
+	if chordpro == nil {
+		return "", models.ErrNotFound
+	}
+	return *chordpro, nil
+}
+
+// Сохранение текста с аккордами и полученного из него обычного текста.
+// Изменение текста записывается как обычное изменение песни (ревизия, аудит, источник chordpro)
+func (s *LyricsRepo) SaveChords(ctx context.Context, songID int, chordpro, text string) error {
+	logrus.WithField("id", songID).Debug("Saving chords")
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction")
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := songForUpdate(ctx, tx, songID)
+	if err != nil {
+		return err
+	}
+	previous, err := lockedChords(ctx, tx, songID)
+	if err != nil {
+		return err
+	}
+
+	// Сохраняется до изменения текста, чтобы saveSong не счёл его устаревшим
+	_, err = tx.Exec(ctx, `UPDATE songs SET chords = $1 WHERE id = $2`, chordpro, songID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to save chords")
+		return err
+	}
+
+	updated := current
+	updated.Text = text
+	_, err = saveSong(ctx, tx, current, updated, nil, map[string]string{"text": models.SourceChordPro})
+	if err != nil {
+		return err
+	}
+
+	action, before := ActionCreate, interface{}(nil)
+	if previous != nil {
+		action, before = ActionUpdate, *previous
+	}
+	err = insertAudit(ctx, tx, action, EntityChords, songID, before, chordpro)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction")
+		return err
+	}
+	return nil
+}
+
+// Удаление текста с аккордами; обычный текст песни сохраняется
+func (s *LyricsRepo) DeleteChords(ctx context.Context, songID int) error {
+	logrus.WithField("id", songID).Debug("Deleting chords")
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction")
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := songForUpdate(ctx, tx, songID); err != nil {
+		return err
+	}
+	previous, err := lockedChords(ctx, tx, songID)
+	if err != nil {
+		return err
+	}
+	if previous == nil {
+		return models.ErrNotFound
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE songs SET chords = NULL WHERE id = $1`, songID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to delete chords")
+		return err
+	}
+
+	err = insertAudit(ctx, tx, ActionDelete, EntityChords, songID, *previous, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction")
+		return err
+	}
+	return nil
+}
+
+// Текущий текст с аккордами песни, заблокированной songForUpdate; nil, если его нет
+func lockedChords(ctx context.Context, tx pgx.Tx, songID int) (*string, error) {
+	var chordpro *string
+	err := tx.QueryRow(ctx, `SELECT chords FROM songs WHERE id = $1`, songID).Scan(&chordpro)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch chords")
+		return nil, notFound(err)
+	}
+	return chordpro, nil
+}
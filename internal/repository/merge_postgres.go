@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/reqctx"
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+type MergeRepo struct {
+	db *pgxpool.Pool
+}
+
+// Создаёт новый экземпляр репозитория объединения песен
+func NewMergeRepo(db *pgxpool.Pool) *MergeRepo {
+	return &MergeRepo{
+		db: db,
+	}
+}
+
+// Объединение песни-дубликата с основной песней. Пустые дата выхода, текст и ссылка основной
+// песни заполняются значениями дубликата, переводы на языки, которых у основной песни нет,
+// переносятся; синхронизированный текст и текст с аккордами переносятся, если тексты песен совпадают.
+// Ревизии дубликата переносятся в историю основной песни, заполненные поля сохраняют источник из дубликата.
+// Дубликат удаляется, запросы к его ID перенаправляются на основную песню
+func (m *MergeRepo) MergeSong(ctx context.Context, songID, duplicateID int) (models.SongMerge, error) {
+	logrus.WithFields(logrus.Fields{
+		"id":          songID,
+		"duplicateId": duplicateID,
+	}).Debug("Merging songs")
+
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction")
+		return models.SongMerge{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Строки блокируются в порядке ID, чтобы встречные объединения не взаимоблокировались
+	locked := map[int]models.Songs{}
+	for _, id := range []int{min(songID, duplicateID), max(songID, duplicateID)} {
+		song, err := songForUpdate(ctx, tx, id)
+		if err != nil {
+			return models.SongMerge{}, err
+		}
+		locked[id] = song
+	}
+	current, duplicate := locked[songID], locked[duplicateID]
+
+	// Ревизии дубликата переносятся до сохранения, чтобы ревизия объединения была последней
+	result := models.SongMerge{MergedID: duplicateID, FilledFields: []string{}}
+	result.MovedRevisions, err = moveRevisions(ctx, tx, songID, duplicateID)
+	if err != nil {
+		return models.SongMerge{}, err
+	}
+
+	duplicateSources, err := fieldSources(ctx, tx, duplicateID)
+	if err != nil {
+		return models.SongMerge{}, err
+	}
+
+	updated := current
+	sources := map[string]string{}
+	for _, f := range []struct {
+		name     string
+		to, from *string
+	}{
+		{"releaseDate", &updated.ReleaseDate, &duplicate.ReleaseDate},
+		{"text", &updated.Text, &duplicate.Text},
+		{"link", &updated.Link, &duplicate.Link},
+	} {
+		if *f.to == "" && *f.from != "" {
+			*f.to = *f.from
+			sources[f.name] = models.SourceMerge
+			if source, ok := duplicateSources[f.name]; ok {
+				sources[f.name] = source
+			}
+			result.FilledFields = append(result.FilledFields, f.name)
+		}
+	}
+	_, err = saveSong(ctx, tx, current, updated, nil, sources)
+	if err != nil {
+		return models.SongMerge{}, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE songs s
+		SET synced_lyrics = COALESCE(s.synced_lyrics, d.synced_lyrics), chords = COALESCE(s.chords, d.chords)
+		FROM songs d
+		WHERE s.id = $1 AND d.id = $2 AND s.text = d.text
+	`, songID, duplicateID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to move synced lyrics and chords")
+		return models.SongMerge{}, err
+	}
+
+	result.MovedTranslations, err = moveTranslations(ctx, tx, songID, duplicateID)
+	if err != nil {
+		return models.SongMerge{}, err
+	}
+
+	// Перенаправления на дубликат указывают теперь на основную песню
+	_, err = tx.Exec(ctx, `
+		UPDATE song_redirects SET song_id = $1 WHERE song_id = $2
+	`, songID, duplicateID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to update song redirects")
+		return models.SongMerge{}, err
+	}
+
+	var groupId int
+	err = tx.QueryRow(ctx, `
+		DELETE FROM songs
+		WHERE id = $1
+		RETURNING group_id
+	`, duplicateID).Scan(&groupId)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to delete duplicate song")
+		return models.SongMerge{}, notFound(err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO song_redirects (old_id, song_id, merged_by)
+		VALUES ($1, $2, $3)
+	`, duplicateID, songID, reqctx.User(ctx))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to insert song redirect")
+		return models.SongMerge{}, err
+	}
+
+	err = checkGroupUsed(ctx, tx, groupId)
+	if err != nil {
+		return models.SongMerge{}, err
+	}
+
+	result.Song, err = songForUpdate(ctx, tx, songID)
+	if err != nil {
+		return models.SongMerge{}, err
+	}
+
+	err = insertAudit(ctx, tx, ActionMerge, EntitySong, duplicateID, duplicate, result)
+	if err != nil {
+		return models.SongMerge{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction")
+		return models.SongMerge{}, err
+	}
+
+	logrus.WithField("merge", result).Debug("Songs merged successfully")
+	return result, nil
+}
+
+// Перенос переводов дубликата на языки, которых нет у основной песни. Возвращает языки перенесённых переводов
+func moveTranslations(ctx context.Context, tx pgx.Tx, songID, duplicateID int) ([]string, error) {
+	rows, err := tx.Query(ctx, `
+		UPDATE song_translations
+		SET song_id = $1
+		WHERE song_id = $2 AND language NOT IN (
+			SELECT language FROM song_translations WHERE song_id = $1
+		)
+		RETURNING language
+	`, songID, duplicateID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to move translations")
+		return nil, err
+	}
+	defer rows.Close()
+
+	languages := []string{}
+	for rows.Next() {
+		var language string
+		if err := rows.Scan(&language); err != nil {
+			logrus.WithError(err).Error("Failed to scan moved translation")
+			return nil, err
+		}
+		languages = append(languages, language)
+	}
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Failed to move translations")
+		return nil, err
+	}
+	return languages, nil
+}
+
+// Перенос ревизий дубликата в историю основной песни. Номера ревизий продолжают нумерацию
+// основной песни, исходная песня и номер сохраняются. Возвращает количество перенесённых ревизий
+func moveRevisions(ctx context.Context, tx pgx.Tx, songID, duplicateID int) (int, error) {
+	tag, err := tx.Exec(ctx, `
+		WITH last AS (
+			SELECT COALESCE(MAX(revision), 0) AS revision FROM song_revisions WHERE song_id = $1
+		)
+		UPDATE song_revisions r
+		SET song_id = $1,
+			merged_from = COALESCE(r.merged_from, $2),
+			merged_revision = COALESCE(r.merged_revision, r.revision),
+			revision = r.revision + last.revision,
+			reverted_from = r.reverted_from + last.revision
+		FROM last
+		WHERE r.song_id = $2
+	`, songID, duplicateID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to move revisions")
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// Источники текущих значений полей песни
+func fieldSources(ctx context.Context, tx pgx.Tx, songID int) (map[string]string, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT field, source FROM song_field_sources WHERE song_id = $1
+	`, songID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to query field sources")
+		return nil, err
+	}
+	defer rows.Close()
+
+	sources := map[string]string{}
+	for rows.Next() {
+		var field, source string
+		if err := rows.Scan(&field, &source); err != nil {
+			logrus.WithError(err).Error("Failed to scan field source")
+			return nil, err
+		}
+		sources[field] = source
+	}
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Failed to query field sources")
+		return nil, err
+	}
+	return sources, nil
+}
+
+// ID основной песни, с которой объединена песня oldID
+func (m *MergeRepo) Redirect(ctx context.Context, oldID int) (int, error) {
+	var songID int
+	err := m.db.QueryRow(ctx, `
+		SELECT song_id FROM song_redirects WHERE old_id = $1
+	`, oldID).Scan(&songID)
+	if err != nil {
+		return 0, notFound(err)
+	}
+	return songID, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"Anastasia/songs/internal/models"
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+type ProvenanceRepo struct {
+	db *pgxpool.Pool
+}
+
+// Создаёт новый экземпляр репозитория источников данных песен
+func NewProvenanceRepo(db *pgxpool.Pool) *ProvenanceRepo {
+	return &ProvenanceRepo{
+		db: db,
+	}
+}
+
+// Получение источников текущих значений полей песни
+func (p *ProvenanceRepo) Provenance(ctx context.Context, songID int) ([]models.FieldSource, error) {
+	logrus.WithField("songId", songID).Debug("Fetching song provenance")
+
+	var exists bool
+	err := p.db.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM songs WHERE id = $1)
+	`, songID).Scan(&exists)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to check song existence")
+		return nil, err
+	}
+	if !exists {
+		return nil, models.ErrNotFound
+	}
+
+	rows, err := p.db.Query(ctx, `
+		SELECT field, source, updated_at
+		FROM song_field_sources
+		WHERE song_id = $1
+		ORDER BY field
+	`, songID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to query song provenance")
+		return nil, err
+	}
+	defer rows.Close()
+
+	sources := []models.FieldSource{}
+	for rows.Next() {
+		var source models.FieldSource
+		if err := rows.Scan(&source.Field, &source.Source, &source.UpdatedAt); err != nil {
+			logrus.WithError(err).Error("Failed to scan provenance row")
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Error occurred while iterating over rows")
+		return nil, err
+	}
+
+	return sources, nil
+}
+
+// Запись источников значений полей песни
+func upsertSources(ctx context.Context, tx pgx.Tx, songID int, sources map[string]string) error {
+	for field, source := range sources {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO song_field_sources (song_id, field, source)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (song_id, field)
+			DO UPDATE SET source = EXCLUDED.source, updated_at = NOW()
+		`, songID, field, source)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to save field source")
+			return err
+		}
+	}
+	return nil
+}
@@ -2,25 +2,109 @@ package repository
 
 import (
 	"Anastasia/songs/internal/models"
+	"context"
+	"time"
 
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type Songs interface {
-	Songs(filters models.Songs, page, pageSize int) ([]models.Songs, error)
-	SongByID(id int) (string, error)
-	DeleteSong(id int) error
-	UpdateSong(song models.Songs) error
-	CreateSong(song models.Songs) error
+	Songs(ctx context.Context, filters models.Songs, page, pageSize int) ([]models.Songs, error)
+	SongByID(ctx context.Context, id int) (string, error)
+	Song(ctx context.Context, id int) (models.Songs, error)
+	DeleteSong(ctx context.Context, id int) error
+	UpdateSong(ctx context.Context, song models.Songs) error
+	CreateSong(ctx context.Context, song models.Songs, check models.DuplicateCheck) (int, error)
+	CreateSongs(ctx context.Context, songs []models.Songs, check models.DuplicateCheck, atomic bool) ([]models.BatchItemResult, error)
+	ExportSongs(ctx context.Context, filters models.Songs, fn func(models.Songs) error) error
+}
+
+type Revisions interface {
+	Revisions(ctx context.Context, songID int) ([]models.Revision, error)
+	Revision(ctx context.Context, songID, revision int) (models.Revision, error)
+	RevertSong(ctx context.Context, songID, revision int) (models.Revision, error)
+}
+
+type Audit interface {
+	Audit(ctx context.Context, filter models.AuditFilter, page, pageSize int) ([]models.AuditEntry, error)
+}
+
+type Enrichment interface {
+	ClaimEnrichmentJob(ctx context.Context, lease time.Duration) (models.EnrichmentJob, models.Songs, error)
+	CompleteEnrichmentJob(ctx context.Context, jobID int, patch models.Songs, sources map[string]string) error
+	RetryEnrichmentJob(ctx context.Context, jobID int, runAt time.Time, reason string) error
+	FailEnrichmentJob(ctx context.Context, jobID, songID int, reason string) error
+	CreateEnrichmentRun(ctx context.Context, filters models.Songs, refresh bool) (models.EnrichmentRun, error)
+	EnrichmentRun(ctx context.Context, id int) (models.EnrichmentRun, error)
+	CachedResponse(ctx context.Context, provider, groupKey, songKey string) (models.Songs, error)
+	SaveCachedResponse(ctx context.Context, provider, groupKey, songKey string, detail models.Songs, ttl time.Duration) error
+}
+
+type Provenance interface {
+	Provenance(ctx context.Context, songID int) ([]models.FieldSource, error)
+}
+
+type Lyrics interface {
+	Lyrics(ctx context.Context, songID int) (string, models.Lyrics, error)
+	SaveLyrics(ctx context.Context, songID int, text string, parsed models.Lyrics) error
+	SyncedLyrics(ctx context.Context, songID int) (string, error)
+	SaveSyncedLyrics(ctx context.Context, songID int, lrc, text string) error
+	DeleteSyncedLyrics(ctx context.Context, songID int) error
+	Chords(ctx context.Context, songID int) (string, error)
+	SaveChords(ctx context.Context, songID int, chordpro, text string) error
+	DeleteChords(ctx context.Context, songID int) error
+}
+
+type Translations interface {
+	Translations(ctx context.Context, songID int) ([]models.Translation, error)
+	Translation(ctx context.Context, songID int, language string) (models.Translation, error)
+	SaveTranslation(ctx context.Context, songID int, tr models.Translation) (models.Translation, error)
+	DeleteTranslation(ctx context.Context, songID int, language string) error
+}
+
+type Stats interface {
+	SongStatsSource(ctx context.Context, songID int) (models.LyricsStatsSource, error)
+	GroupStatsSources(ctx context.Context, group string) ([]models.LyricsStatsSource, error)
+	SaveLyricsTally(ctx context.Context, songID int, tally models.LyricsTally) error
+}
+
+type Similarity interface {
+	Signature(ctx context.Context, songID int) ([]uint32, string, error)
+	SaveSignature(ctx context.Context, songID int, text string) error
+	Candidates(ctx context.Context, excludeID int, buckets []int64) ([]models.LyricsCandidate, error)
+	UnsignedLyrics(ctx context.Context, limit int) ([]models.UnsignedLyrics, error)
+}
+
+type Merges interface {
+	MergeSong(ctx context.Context, songID, duplicateID int) (models.SongMerge, error)
+	Redirect(ctx context.Context, oldID int) (int, error)
 }
 
 type Repo struct {
 	Songs
+	Revisions
+	Audit
+	Enrichment
+	Provenance
+	Lyrics
+	Translations
+	Stats
+	Similarity
+	Merges
 }
 
 func NewRepo(db *pgxpool.Pool) *Repo {
 	repo := &Repo{
-		Songs: NewSongRepo(db),
+		Songs:        NewSongRepo(db),
+		Revisions:    NewRevisionRepo(db),
+		Audit:        NewAuditRepo(db),
+		Enrichment:   NewEnrichmentRepo(db),
+		Provenance:   NewProvenanceRepo(db),
+		Lyrics:       NewLyricsRepo(db),
+		Translations: NewTranslationRepo(db),
+		Stats:        NewStatsRepo(db),
+		Similarity:   NewSimilarityRepo(db),
+		Merges:       NewMergeRepo(db),
 	}
 	return repo
 }
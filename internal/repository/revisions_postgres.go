@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/reqctx"
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+type RevisionRepo struct {
+	db *pgxpool.Pool
+}
+
+// Создаёт новый экземпляр репозитория ревизий
+func NewRevisionRepo(db *pgxpool.Pool) *RevisionRepo {
+	return &RevisionRepo{
+		db: db,
+	}
+}
+
+// Получение истории изменений песни
+func (r *RevisionRepo) Revisions(ctx context.Context, songID int) ([]models.Revision, error) {
+	logrus.WithField("songId", songID).Debug("Fetching song revisions")
+
+	var exists bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM songs WHERE id = $1)
+	`, songID).Scan(&exists)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to check song existence")
+		return nil, err
+	}
+	if !exists {
+		return nil, models.ErrNotFound
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, song_id, revision, author, created_at, changes, snapshot, reverted_from, merged_from, merged_revision
+		FROM song_revisions
+		WHERE song_id = $1
+		ORDER BY revision
+	`, songID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to query song revisions")
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []models.Revision{}
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to scan revision row")
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Error occurred while iterating over rows")
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// Получение одной ревизии песни
+func (r *RevisionRepo) Revision(ctx context.Context, songID, revision int) (models.Revision, error) {
+	logrus.WithFields(logrus.Fields{
+		"songId":   songID,
+		"revision": revision,
+	}).Debug("Fetching song revision")
+
+	row := r.db.QueryRow(ctx, `
+		SELECT id, song_id, revision, author, created_at, changes, snapshot, reverted_from, merged_from, merged_revision
+		FROM song_revisions
+		WHERE song_id = $1 AND revision = $2
+	`, songID, revision)
+
+	rev, err := scanRevision(row)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch revision")
+		return models.Revision{}, notFound(err)
+	}
+	return rev, nil
+}
+
+// Откат песни к состоянию указанной ревизии. Откат сохраняется как новая ревизия
+func (r *RevisionRepo) RevertSong(ctx context.Context, songID, revision int) (models.Revision, error) {
+	logrus.WithFields(logrus.Fields{
+		"songId":   songID,
+		"revision": revision,
+	}).Debug("Reverting song")
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction")
+		return models.Revision{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := songForUpdate(ctx, tx, songID)
+	if err != nil {
+		return models.Revision{}, err
+	}
+
+	target, err := scanRevision(tx.QueryRow(ctx, `
+		SELECT id, song_id, revision, author, created_at, changes, snapshot, reverted_from, merged_from, merged_revision
+		FROM song_revisions
+		WHERE song_id = $1 AND revision = $2
+	`, songID, revision))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch revision")
+		return models.Revision{}, notFound(err)
+	}
+
+	updated := target.Snapshot
+	updated.ID = songID
+	rev, err := saveSong(ctx, tx, current, updated, &revision, nil)
+	if err != nil {
+		return models.Revision{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction")
+		return models.Revision{}, err
+	}
+
+	logrus.WithField("revision", rev.Revision).Debug("Song reverted successfully")
+	return rev, nil
+}
+
+// Сохранение ревизии с разницей между состояниями песни
+func insertRevision(ctx context.Context, tx pgx.Tx, before, after models.Songs, revertedFrom *int) (models.Revision, error) {
+	rev := models.Revision{
+		SongID:       after.ID,
+		Author:       reqctx.User(ctx),
+		Changes:      songChanges(before, after),
+		RevertedFrom: revertedFrom,
+		Snapshot:     after,
+	}
+
+	changes, err := json.Marshal(rev.Changes)
+	if err != nil {
+		return models.Revision{}, err
+	}
+	snapshot, err := json.Marshal(rev.Snapshot)
+	if err != nil {
+		return models.Revision{}, err
+	}
+
+	// Строка песни заблокирована транзакцией, поэтому номер ревизии не может быть занят параллельно
+	err = tx.QueryRow(ctx, `
+		INSERT INTO song_revisions (song_id, revision, author, changes, snapshot, reverted_from)
+		SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3, $4, $5
+		FROM song_revisions
+		WHERE song_id = $1
+		RETURNING id, revision, created_at
+	`, rev.SongID, rev.Author, changes, snapshot, revertedFrom).Scan(&rev.ID, &rev.Revision, &rev.CreatedAt)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to insert revision")
+		return models.Revision{}, err
+	}
+
+	return rev, nil
+}
+
+// Список полей, значения которых отличаются
+func songChanges(before, after models.Songs) []models.FieldChange {
+	fields := []struct {
+		name     string
+		old, new string
+	}{
+		{"song", before.Song, after.Song},
+		{"group", before.Group, after.Group},
+		{"releaseDate", before.ReleaseDate, after.ReleaseDate},
+		{"text", before.Text, after.Text},
+		{"link", before.Link, after.Link},
+	}
+
+	changes := []models.FieldChange{}
+	for _, f := range fields {
+		if f.old != f.new {
+			changes = append(changes, models.FieldChange{Field: f.name, Old: f.old, New: f.new})
+		}
+	}
+	return changes
+}
+
+func scanRevision(row pgx.Row) (models.Revision, error) {
+	var rev models.Revision
+	var changes, snapshot []byte
+	err := row.Scan(&rev.ID, &rev.SongID, &rev.Revision, &rev.Author, &rev.CreatedAt, &changes, &snapshot, &rev.RevertedFrom,
+		&rev.MergedFrom, &rev.MergedRevision)
+	if err != nil {
+		return models.Revision{}, err
+	}
+	if err := json.Unmarshal(changes, &rev.Changes); err != nil {
+		return models.Revision{}, err
+	}
+	if err := json.Unmarshal(snapshot, &rev.Snapshot); err != nil {
+		return models.Revision{}, err
+	}
+	return rev, nil
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"Anastasia/songs/internal/lyrics"
+	"Anastasia/songs/internal/models"
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+type SimilarityRepo struct {
+	db *pgxpool.Pool
+}
+
+// Создаёт новый экземпляр репозитория сигнатур текстов песен
+func NewSimilarityRepo(db *pgxpool.Pool) *SimilarityRepo {
+	return &SimilarityRepo{
+		db: db,
+	}
+}
+
+// Получение сигнатуры текста песни и самого текста. Если сигнатура ещё не вычислена, она равна nil,
+// у текста без слов она пуста
+func (s *SimilarityRepo) Signature(ctx context.Context, songID int) ([]uint32, string, error) {
+	logrus.WithField("id", songID).Debug("Fetching lyrics signature")
+
+	var text string
+	var data []byte
+	err := s.db.QueryRow(ctx, `
+		SELECT COALESCE(s.text, ''), ls.signature
+		FROM songs s
+		LEFT JOIN lyrics_signatures ls ON ls.song_id = s.id
+		WHERE s.id = $1
+	`, songID).Scan(&text, &data)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch lyrics signature")
+		return nil, "", notFound(err)
+	}
+	if data == nil {
+		return nil, text, nil
+	}
+	return lyrics.DecodeSignature(data), text, nil
+}
+
+// Вычисление и сохранение сигнатуры текста песни
+func (s *SimilarityRepo) SaveSignature(ctx context.Context, songID int, text string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction")
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := saveSignature(ctx, tx, songID, text); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction")
+		return err
+	}
+	return nil
+}
+
+// Песни, попавшие хотя бы в одну из корзин LSH, кроме песни excludeID, с их сигнатурами
+func (s *SimilarityRepo) Candidates(ctx context.Context, excludeID int, buckets []int64) ([]models.LyricsCandidate, error) {
+	logrus.WithField("id", excludeID).Debug("Fetching similar lyrics candidates")
+
+	rows, err := s.db.Query(ctx, `
+		SELECT s.id, s.name, g.name, ls.signature
+		FROM songs s
+		INNER JOIN groups g ON s.group_id = g.id
+		INNER JOIN lyrics_signatures ls ON ls.song_id = s.id
+		WHERE s.id <> $1 AND s.id IN (
+			SELECT song_id FROM lyrics_lsh_buckets WHERE bucket = ANY($2)
+		)
+	`, excludeID, buckets)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to query similar lyrics candidates")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []models.LyricsCandidate
+	for rows.Next() {
+		var c models.LyricsCandidate
+		var data []byte
+		if err := rows.Scan(&c.ID, &c.Song, &c.Group, &data); err != nil {
+			logrus.WithError(err).Error("Failed to scan candidate row")
+			return nil, err
+		}
+		c.Signature = lyrics.DecodeSignature(data)
+		candidates = append(candidates, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Error occurred while iterating over rows")
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// Песни с текстом, для которых ещё не вычислена сигнатура (например, добавленные до её появления)
+func (s *SimilarityRepo) UnsignedLyrics(ctx context.Context, limit int) ([]models.UnsignedLyrics, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT s.id, s.text
+		FROM songs s
+		WHERE COALESCE(s.text, '') <> '' AND NOT EXISTS (
+			SELECT 1 FROM lyrics_signatures ls WHERE ls.song_id = s.id
+		)
+		ORDER BY s.id
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to query songs without lyrics signature")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var songs []models.UnsignedLyrics
+	for rows.Next() {
+		var song models.UnsignedLyrics
+		if err := rows.Scan(&song.ID, &song.Text); err != nil {
+			logrus.WithError(err).Error("Failed to scan song row")
+			return nil, err
+		}
+		songs = append(songs, song)
+	}
+
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Error occurred while iterating over rows")
+		return nil, err
+	}
+	return songs, nil
+}
+
+// Замена сигнатуры и корзин LSH текста песни. Для текста без слов хранится пустая сигнатура
+// без корзин, чтобы он не вычислялся повторно
+func saveSignature(ctx context.Context, tx pgx.Tx, songID int, text string) error {
+	_, err := tx.Exec(ctx, `DELETE FROM lyrics_lsh_buckets WHERE song_id = $1`, songID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to delete lyrics buckets")
+		return err
+	}
+	_, err = tx.Exec(ctx, `DELETE FROM lyrics_signatures WHERE song_id = $1`, songID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to delete lyrics signature")
+		return err
+	}
+
+	signature := lyrics.Signature(text)
+	_, err = tx.Exec(ctx, `
+		INSERT INTO lyrics_signatures (song_id, signature)
+		VALUES ($1, $2)
+	`, songID, lyrics.EncodeSignature(signature))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to insert lyrics signature")
+		return err
+	}
+
+	if signature == nil {
+		return nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO lyrics_lsh_buckets (song_id, bucket)
+		SELECT $1, unnest($2::BIGINT[])
+		ON CONFLICT DO NOTHING
+	`, songID, lyrics.Buckets(signature))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to insert lyrics buckets")
+		return err
+	}
+	return nil
+}
@@ -1,10 +1,13 @@
 package repository
 
 import (
+	"Anastasia/songs/internal/lyrics"
 	"Anastasia/songs/internal/models"
 	"context"
-	"strconv"
+	"errors"
+	"math"
 
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/sirupsen/logrus"
 )
@@ -21,26 +24,22 @@ func NewSongRepo(db *pgxpool.Pool) *SongRepo {
 }
 
 // Получение данных библиотеки с фильтрацией по всем полям и пагинацией
-func (s *SongRepo) Songs(filters models.Songs, page, pageSize int) ([]models.Songs, error) {
+func (s *SongRepo) Songs(ctx context.Context, filters models.Songs, page, pageSize int) ([]models.Songs, error) {
 	logrus.WithFields(logrus.Fields{
 		"filters":  filters,
 		"page":     page,
 		"pageSize": pageSize,
 	}).Debug("Fetching songs with filters")
 
-	rows, err := s.db.Query(context.Background(), `
-		SELECT s.id, s.name, g.name, s.release_date, s.text, s.link
+	args := append(songsFilterArgs(filters), pageSize, (page-1)*pageSize)
+	rows, err := s.db.Query(ctx, `
+		SELECT s.id, s.name, g.name, s.release_date, s.text, s.link, s.enrichment_status
 		FROM songs s
 		INNER JOIN groups g ON s.group_id = g.id
-		WHERE s.name ILIKE $1 AND
-		g.name ILIKE $2 AND
-		s.release_date ILIKE $3 AND
-		s.text ILIKE $4 AND
-		s.link ILIKE $5
-		LIMIT $6
-		OFFSET $7
-	`, "%"+filters.Song+"%", "%"+filters.Group+"%", "%"+filters.ReleaseDate+"%", "%"+filters.Text+"%", "%"+filters.Link+"%",
-		pageSize, (page-1)*pageSize)
+		WHERE `+songsFilterSQL+`
+		LIMIT $7
+		OFFSET $8
+	`, args...)
 
 	if err != nil {
 		logrus.WithError(err).Error("Failed to query songs")
@@ -58,6 +57,7 @@ func (s *SongRepo) Songs(filters models.Songs, page, pageSize int) ([]models.Son
 			&song.ReleaseDate,
 			&song.Text,
 			&song.Link,
+			&song.EnrichmentStatus,
 		)
 		if err != nil {
 			logrus.WithError(err).Error("Failed to scan song row")
@@ -75,11 +75,32 @@ func (s *SongRepo) Songs(filters models.Songs, page, pageSize int) ([]models.Son
 	return songs, nil
 }
 
+// Условие отбора песен по фильтрам списка (таблицы songs s и groups g).
+// Значения фильтров передаются в параметрах $1–$6, см. songsFilterArgs
+const songsFilterSQL = `s.name ILIKE $1 AND
+		g.name ILIKE $2 AND
+		s.release_date ILIKE $3 AND
+		s.text ILIKE $4 AND
+		s.link ILIKE $5 AND
+		($6 = '' OR s.enrichment_status = $6)`
+
+// Параметры для songsFilterSQL
+func songsFilterArgs(filters models.Songs) []interface{} {
+	return []interface{}{
+		"%" + filters.Song + "%",
+		"%" + filters.Group + "%",
+		"%" + filters.ReleaseDate + "%",
+		"%" + filters.Text + "%",
+		"%" + filters.Link + "%",
+		filters.EnrichmentStatus,
+	}
+}
+
 // Получение текста песни с пагинацией по куплетам
-func (s *SongRepo) SongByID(id int) (string, error) {
+func (s *SongRepo) SongByID(ctx context.Context, id int) (string, error) {
 	logrus.WithField("id", id).Debug("Fetching song by ID")
 
-	row := s.db.QueryRow(context.Background(), `
+	row := s.db.QueryRow(ctx, `
 		SELECT s.text
 		FROM songs s
 		INNER JOIN groups g ON s.group_id = g.id
@@ -90,151 +111,502 @@ func (s *SongRepo) SongByID(id int) (string, error) {
 	err := row.Scan(&lyrics)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to scan lyrics")
-		return "", err
+		return "", notFound(err)
 	}
 
 	logrus.WithField("lyrics", lyrics).Debug("Fetched lyrics successfully")
 	return lyrics, nil
 }
 
+// Получение всех данных песни
+func (s *SongRepo) Song(ctx context.Context, id int) (models.Songs, error) {
+	logrus.WithField("id", id).Debug("Fetching song")
+
+	var song models.Songs
+	err := s.db.QueryRow(ctx, `
+		SELECT s.id, s.name, g.name, s.release_date, s.text, s.link, s.enrichment_status
+		FROM songs s
+		INNER JOIN groups g ON s.group_id = g.id
+		WHERE s.id = $1
+	`, id).Scan(&song.ID, &song.Song, &song.Group, &song.ReleaseDate, &song.Text, &song.Link, &song.EnrichmentStatus)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch song")
+		return models.Songs{}, notFound(err)
+	}
+
+	return song, nil
+}
+
 // Удаление песни
-func (s *SongRepo) DeleteSong(id int) error {
+func (s *SongRepo) DeleteSong(ctx context.Context, id int) error {
 	logrus.WithField("id", id).Debug("Deleting song")
 
-	row := s.db.QueryRow(context.Background(), `
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction")
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := songForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	row := tx.QueryRow(ctx, `
 		DELETE FROM songs
 		WHERE id = $1
 		RETURNING group_id;
 	`, id)
 
 	var groupId int
-	err := row.Scan(&groupId)
+	err = row.Scan(&groupId)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to delete song")
+		return notFound(err)
+	}
+
+	err = checkGroupUsed(ctx, tx, groupId)
+	if err != nil {
 		return err
 	}
 
-	err = s.checkGroupUsed(groupId)
+	err = insertAudit(ctx, tx, ActionDelete, EntitySong, id, current, nil)
 	if err != nil {
 		return err
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction")
+		return err
+	}
+
 	logrus.WithField("groupId", groupId).Debug("Song deleted successfully")
 	return nil
 }
 
-// Изменение данных песни
-func (s *SongRepo) UpdateSong(song models.Songs) error {
+// Изменение данных песни. Каждое изменение сохраняется как ревизия
+func (s *SongRepo) UpdateSong(ctx context.Context, song models.Songs) error {
 	logrus.WithField("song", song).Debug("Updating song")
 
-	var groupId int
-	var err error
-	if song.Group != "" {
-		groupId, err = s.checkGroupExists(song.Group)
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction")
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := songForUpdate(ctx, tx, song.ID)
+	if err != nil {
+		return err
+	}
+
+	updated := applyPatch(current, song)
+	_, err = saveSong(ctx, tx, current, updated, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction")
+		return err
+	}
+
+	logrus.WithField("song", updated).Debug("Song updated successfully")
+	return nil
+}
+
+// Добавление новой песни. Если такая песня уже есть (см. models.DuplicateCheck),
+// возвращает *models.DuplicateError
+func (s *SongRepo) CreateSong(ctx context.Context, song models.Songs, check models.DuplicateCheck) (int, error) {
+	logrus.WithField("song", song).Debug("Creating song")
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction")
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if !check.Skip {
+		err = checkDuplicate(ctx, tx, song, check.MinSimilarity)
 		if err != nil {
-			return err
+			return 0, err
 		}
 	}
 
-	row := s.db.QueryRow(context.Background(), `
-	SELECT group_id FROM songs
-	WHERE id = $1
-	`, song.ID)
+	id, err := createSong(ctx, tx, song)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction")
+		return 0, err
+	}
+
+	logrus.WithField("id", id).Debug("Song created successfully")
+	return id, nil
+}
+
+// Пакетное добавление песен в одной транзакции. Песни, которые уже есть в библиотеке
+// (см. models.DuplicateCheck), не добавляются. Ошибка отдельной песни не прерывает
+// пакет, если не задан atomic: тогда при любой неудаче транзакция откатывается целиком
+func (s *SongRepo) CreateSongs(ctx context.Context, songs []models.Songs, check models.DuplicateCheck, atomic bool) ([]models.BatchItemResult, error) {
+	logrus.WithFields(logrus.Fields{
+		"count":  len(songs),
+		"atomic": atomic,
+	}).Debug("Creating songs batch")
 
-	query := "UPDATE songs SET "
-	var args []interface{}
-	argIndex := 1
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction")
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
 
-	if song.Song != "" {
-		query += "name = $" + strconv.Itoa(argIndex)
-		args = append(args, song.Song)
-		argIndex++
+	groups := make([]string, len(songs))
+	for i, song := range songs {
+		groups[i] = song.Group
+	}
+	err = lockGroups(ctx, tx, groups...)
+	if err != nil {
+		return nil, err
 	}
 
-	if groupId != 0 {
-		if argIndex > 1 {
-			query += ", "
+	results := make([]models.BatchItemResult, len(songs))
+	ok := true
+	for i, song := range songs {
+		results[i] = createSongSavepoint(ctx, tx, song, check)
+		if results[i].Status != models.BatchCreated {
+			ok = false
 		}
-		query += "group_id = $" + strconv.Itoa(argIndex)
-		args = append(args, groupId)
-		argIndex++
 	}
 
-	if song.ReleaseDate != "" {
-		if argIndex > 1 {
-			query += ", "
+	if atomic && !ok {
+		for i := range results {
+			if results[i].Status == models.BatchCreated {
+				results[i] = models.BatchItemResult{Status: models.BatchRolledBack}
+			}
 		}
-		query += "release_date = $" + strconv.Itoa(argIndex)
-		args = append(args, song.ReleaseDate)
-		argIndex++
+		return results, nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction")
+		return nil, err
 	}
 
-	if song.Text != "" {
-		if argIndex > 1 {
-			query += ", "
+	logrus.WithField("count", len(songs)).Debug("Songs batch processed successfully")
+	return results, nil
+}
+
+// Добавление одной песни пакета в точке сохранения, чтобы её ошибка не прерывала транзакцию
+func createSongSavepoint(ctx context.Context, tx pgx.Tx, song models.Songs, check models.DuplicateCheck) models.BatchItemResult {
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return models.BatchItemResult{Status: models.BatchFailed, Error: err.Error()}
+	}
+	defer sp.Rollback(ctx)
+
+	if !check.Skip {
+		err = checkDuplicate(ctx, sp, song, check.MinSimilarity)
+		var duplicateErr *models.DuplicateError
+		if errors.As(err, &duplicateErr) {
+			return models.BatchItemResult{
+				Status:     models.BatchDuplicate,
+				ExistingID: duplicateErr.ExistingID,
+				Similarity: duplicateErr.Similarity,
+			}
 		}
-		query += "text = $" + strconv.Itoa(argIndex)
-		args = append(args, song.Text)
-		argIndex++
+		if err != nil {
+			return models.BatchItemResult{Status: models.BatchFailed, Error: err.Error()}
+		}
+	}
+
+	id, err := createSong(ctx, sp, song)
+	if err != nil {
+		return models.BatchItemResult{Status: models.BatchFailed, Error: err.Error()}
+	}
+	if err := sp.Commit(ctx); err != nil {
+		return models.BatchItemResult{Status: models.BatchFailed, Error: err.Error()}
+	}
+	return models.BatchItemResult{Status: models.BatchCreated, ID: id}
+}
+
+// Добавление песни вместе с ревизией, источниками полей, записью аудита и,
+// для статуса pending, задачей получения данных
+func createSong(ctx context.Context, tx pgx.Tx, song models.Songs) (int, error) {
+	groupId, err := checkGroupExists(ctx, tx, song.Group)
+	if err != nil {
+		return 0, err
+	}
+
+	if song.EnrichmentStatus == "" {
+		song.EnrichmentStatus = models.EnrichmentEnriched
 	}
 
-	if song.Link != "" {
-		if argIndex > 1 {
-			query += ", "
+	err = tx.QueryRow(ctx, `
+		INSERT INTO songs (name, group_id, release_date, text, link, enrichment_status, lyrics)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`, song.Song, groupId, song.ReleaseDate, song.Text, song.Link, song.EnrichmentStatus, parseLyrics(song.Text)).Scan(&song.ID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to insert song")
+		return 0, err
+	}
+
+	err = saveSignature(ctx, tx, song.ID, song.Text)
+	if err != nil {
+		return 0, err
+	}
+
+	// Песня в статусе pending попадает в очередь фонового получения данных
+	if song.EnrichmentStatus == models.EnrichmentPending {
+		err = enqueueEnrichment(ctx, tx, song.ID)
+		if err != nil {
+			return 0, err
 		}
-		query += "link = $" + strconv.Itoa(argIndex)
-		args = append(args, song.Link)
-		argIndex++
 	}
 
-	query += " WHERE id = $" + strconv.Itoa(argIndex)
-	args = append(args, song.ID)
+	rev, err := insertRevision(ctx, tx, models.Songs{ID: song.ID}, song, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	err = upsertSources(ctx, tx, song.ID, changedSources(rev.Changes, nil, models.SourceUser))
+	if err != nil {
+		return 0, err
+	}
+
+	err = insertAudit(ctx, tx, ActionCreate, EntitySong, song.ID, nil, song)
+	if err != nil {
+		return 0, err
+	}
+
+	return song.ID, nil
+}
+
+// ID существующей песни с теми же нормализованными названием и группой или 0
+func findDuplicate(ctx context.Context, tx pgx.Tx, group, song string) (int, error) {
+	var id int
+	err := tx.QueryRow(ctx, `
+		SELECT s.id
+		FROM songs s
+		INNER JOIN groups g ON s.group_id = g.id
+		WHERE normalize_name(s.name) = normalize_name($1) AND
+		normalize_name(g.name) = normalize_name($2)
+		ORDER BY s.id
+		LIMIT 1
+	`, song, group).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		logrus.WithError(err).Error("Failed to look up duplicate song")
+		return 0, err
+	}
+	return id, nil
+}
 
-	_, err = s.db.Exec(context.Background(), query, args...)
+// Поиск дубликата добавляемой песни: сначала по нормализованным названию и группе, затем,
+// если задан порог, по сходству названий песен той же группы. Группа блокируется до конца транзакции
+func checkDuplicate(ctx context.Context, tx pgx.Tx, song models.Songs, minSimilarity float64) error {
+	err := lockGroups(ctx, tx, song.Group)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to update song", query)
 		return err
 	}
 
-	var currentGroupId int
-	err = row.Scan(&currentGroupId)
+	existingID, err := findDuplicate(ctx, tx, song.Group, song.Song)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to get current group ID")
+		return err
+	}
+	if existingID != 0 {
+		return &models.DuplicateError{ExistingID: existingID, Similarity: 1}
+	}
+	if minSimilarity <= 0 {
+		return nil
 	}
 
-	err = s.checkGroupUsed(currentGroupId)
+	rows, err := tx.Query(ctx, `
+		SELECT s.id, s.name
+		FROM songs s
+		INNER JOIN groups g ON s.group_id = g.id
+		WHERE normalize_name(g.name) = normalize_name($1)
+		ORDER BY s.id
+	`, song.Group)
 	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch group songs")
 		return err
 	}
+	defer rows.Close()
 
-	logrus.WithField("song", song).Debug("Song updated successfully")
+	var best *models.DuplicateError
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			logrus.WithError(err).Error("Failed to scan group song")
+			return err
+		}
+		similarity := lyrics.NameSimilarity(song.Song, name)
+		if similarity >= minSimilarity && (best == nil || similarity > best.Similarity) {
+			best = &models.DuplicateError{ExistingID: id, Similarity: math.Round(similarity*100) / 100}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Failed to iterate group songs")
+		return err
+	}
+	if best != nil {
+		return best
+	}
 	return nil
 }
 
-// Добавление новой песни
-func (s *SongRepo) CreateSong(song models.Songs) error {
-	logrus.WithField("song", song).Debug("Creating song")
-
-	groupId, err := s.checkGroupExists(song.Group)
+// Блокировка добавления песен в группы до конца транзакции, чтобы параллельные запросы
+// не добавили одну песню дважды. Блокировки берутся в одном порядке, чтобы пакеты
+// с общими группами не взаимоблокировались
+func lockGroups(ctx context.Context, tx pgx.Tx, groups ...string) error {
+	_, err := tx.Exec(ctx, `
+		SELECT pg_advisory_xact_lock(k)
+		FROM (SELECT DISTINCT hashtext(normalize_name(g)) AS k FROM unnest($1::TEXT[]) g ORDER BY k) locks
+	`, groups)
 	if err != nil {
+		logrus.WithError(err).Error("Failed to lock groups for duplicate check")
 		return err
 	}
+	return nil
+}
+
+// Источники изменённых полей: из sources, если поле там есть, иначе defaultSource
+func changedSources(changes []models.FieldChange, sources map[string]string, defaultSource string) map[string]string {
+	result := make(map[string]string, len(changes))
+	for _, change := range changes {
+		source, ok := sources[change.Field]
+		if !ok {
+			source = defaultSource
+		}
+		result[change.Field] = source
+	}
+	return result
+}
+
+// Наложение изменений на песню. Пустые поля изменений означают, что значение не меняется
+func applyPatch(song, patch models.Songs) models.Songs {
+	if patch.Song != "" {
+		song.Song = patch.Song
+	}
+	if patch.Group != "" {
+		song.Group = patch.Group
+	}
+	if patch.ReleaseDate != "" {
+		song.ReleaseDate = patch.ReleaseDate
+	}
+	if patch.Text != "" {
+		song.Text = patch.Text
+	}
+	if patch.Link != "" {
+		song.Link = patch.Link
+	}
+	return song
+}
 
-	_, err = s.db.Exec(context.Background(), `
-		INSERT INTO songs (name, group_id, release_date, text, link)
-		VALUES ($1, $2, $3, $4, $5)
-	`, song.Song, groupId, song.ReleaseDate, song.Text, song.Link)
+// Получение песни с блокировкой строки до конца транзакции
+func songForUpdate(ctx context.Context, tx pgx.Tx, id int) (models.Songs, error) {
+	var song models.Songs
+	err := tx.QueryRow(ctx, `
+		SELECT s.id, s.name, g.name, s.release_date, s.text, s.link, s.enrichment_status
+		FROM songs s
+		INNER JOIN groups g ON s.group_id = g.id
+		WHERE s.id = $1
+		FOR UPDATE OF s
+	`, id).Scan(&song.ID, &song.Song, &song.Group, &song.ReleaseDate, &song.Text, &song.Link, &song.EnrichmentStatus)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to insert song")
-		return err
+		logrus.WithError(err).Error("Failed to lock song")
+		return models.Songs{}, notFound(err)
 	}
+	return song, nil
+}
 
-	logrus.WithField("song", song).Debug("Song created successfully")
-	return nil
+// Запись нового состояния песни, ревизии, источников изменённых полей и записи журнала аудита.
+// Источники берутся из sources, для отсутствующих там полей источником считается пользователь
+// (или откат, если он выполняется). Если состояние не изменилось, ревизия не создаётся
+func saveSong(ctx context.Context, tx pgx.Tx, current, updated models.Songs, revertedFrom *int, sources map[string]string) (models.Revision, error) {
+	changes := songChanges(current, updated)
+	if len(changes) == 0 {
+		return models.Revision{}, nil
+	}
+
+	defaultSource := models.SourceUser
+	if revertedFrom != nil {
+		defaultSource = models.SourceRevert
+	}
+	err := upsertSources(ctx, tx, updated.ID, changedSources(changes, sources, defaultSource))
+	if err != nil {
+		return models.Revision{}, err
+	}
+
+	groupId, err := checkGroupExists(ctx, tx, updated.Group)
+	if err != nil {
+		return models.Revision{}, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE songs
+		SET name = $1, group_id = $2, release_date = $3, text = $4, link = $5, lyrics = $6
+		WHERE id = $7
+	`, updated.Song, groupId, updated.ReleaseDate, updated.Text, updated.Link, parseLyrics(updated.Text), updated.ID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to update song")
+		return models.Revision{}, err
+	}
+
+	if updated.Text != current.Text {
+		err = resetStaleLyrics(ctx, tx, updated.ID, updated.Text)
+		if err != nil {
+			return models.Revision{}, err
+		}
+
+		err = saveSignature(ctx, tx, updated.ID, updated.Text)
+		if err != nil {
+			return models.Revision{}, err
+		}
+	}
+
+	if updated.Group != current.Group {
+		err = checkGroupUsedByName(ctx, tx, current.Group)
+		if err != nil {
+			return models.Revision{}, err
+		}
+	}
+
+	action := ActionUpdate
+	if revertedFrom != nil {
+		action = ActionRevert
+	}
+	err = insertAudit(ctx, tx, action, EntitySong, updated.ID, current, updated)
+	if err != nil {
+		return models.Revision{}, err
+	}
+
+	return insertRevision(ctx, tx, current, updated, revertedFrom)
+}
+
+func checkGroupUsedByName(ctx context.Context, tx pgx.Tx, groupName string) error {
+	var groupId int
+	err := tx.QueryRow(ctx, `
+		SELECT id FROM groups WHERE name = $1
+	`, groupName).Scan(&groupId)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get group ID")
+		return err
+	}
+	return checkGroupUsed(ctx, tx, groupId)
 }
 
-func (s *SongRepo) checkGroupUsed(groupId int) error {
-	row := s.db.QueryRow(context.Background(), `
+func checkGroupUsed(ctx context.Context, tx pgx.Tx, groupId int) error {
+	row := tx.QueryRow(ctx, `
 		SELECT COUNT(id) FROM songs
 		WHERE group_id = $1
 	`, groupId)
@@ -248,7 +620,7 @@ func (s *SongRepo) checkGroupUsed(groupId int) error {
 
 	// Во избежание хранения избыточной информации в таблице groups удаляем неиспользуемые строки таблицы
 	if count == 0 {
-		_, err = s.db.Exec(context.Background(), `
+		_, err = tx.Exec(ctx, `
 			DELETE FROM groups
 			WHERE id = $1
 		`, groupId)
@@ -260,8 +632,8 @@ func (s *SongRepo) checkGroupUsed(groupId int) error {
 	return nil
 }
 
-func (s *SongRepo) checkGroupExists(groupName string) (int, error) {
-	row := s.db.QueryRow(context.Background(), `
+func checkGroupExists(ctx context.Context, tx pgx.Tx, groupName string) (int, error) {
+	row := tx.QueryRow(ctx, `
 			INSERT INTO groups (name)
 			VALUES ($1)
 			ON CONFLICT (name)
@@ -272,8 +644,8 @@ func (s *SongRepo) checkGroupExists(groupName string) (int, error) {
 	var groupId int
 	err := row.Scan(&groupId)
 	if err != nil {
-		if err.Error() == "no rows in result set" {
-			err = s.db.QueryRow(context.Background(), `
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = tx.QueryRow(ctx, `
 					SELECT id FROM groups WHERE name = $1
 				`, groupName).Scan(&groupId)
 			if err != nil {
@@ -287,3 +659,11 @@ func (s *SongRepo) checkGroupExists(groupName string) (int, error) {
 	}
 	return groupId, nil
 }
+
+// Преобразует отсутствие строк в models.ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ErrNotFound
+	}
+	return err
+}
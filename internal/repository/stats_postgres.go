@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"Anastasia/songs/internal/models"
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+type StatsRepo struct {
+	db *pgxpool.Pool
+}
+
+// Создаёт новый экземпляр репозитория статистики текстов песен
+func NewStatsRepo(db *pgxpool.Pool) *StatsRepo {
+	return &StatsRepo{
+		db: db,
+	}
+}
+
+// Выборка текста песни с кэшированным подсчётом
+const statsSourceQuery = `
+	SELECT s.id, s.name, g.name, COALESCE(s.text, ''), t.text_hash, t.version, t.tally
+	FROM songs s
+	INNER JOIN groups g ON s.group_id = g.id
+	LEFT JOIN lyrics_stats t ON t.song_id = s.id
+`
+
+// Получение текста песни и его кэшированного подсчёта
+func (s *StatsRepo) SongStatsSource(ctx context.Context, songID int) (models.LyricsStatsSource, error) {
+	logrus.WithField("id", songID).Debug("Fetching song lyrics for stats")
+
+	rows, err := s.db.Query(ctx, statsSourceQuery+`WHERE s.id = $1`, songID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to query song lyrics for stats")
+		return models.LyricsStatsSource{}, err
+	}
+	sources, err := scanStatsSources(rows)
+	if err != nil {
+		return models.LyricsStatsSource{}, err
+	}
+	if len(sources) == 0 {
+		return models.LyricsStatsSource{}, models.ErrNotFound
+	}
+	return sources[0], nil
+}
+
+// Получение текстов всех песен группы и их кэшированных подсчётов
+func (s *StatsRepo) GroupStatsSources(ctx context.Context, group string) ([]models.LyricsStatsSource, error) {
+	logrus.WithField("group", group).Debug("Fetching group lyrics for stats")
+
+	rows, err := s.db.Query(ctx, statsSourceQuery+`WHERE g.name = $1 ORDER BY s.id`, group)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to query group lyrics for stats")
+		return nil, err
+	}
+	sources, err := scanStatsSources(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		return nil, models.ErrNotFound
+	}
+	return sources, nil
+}
+
+func scanStatsSources(rows pgx.Rows) ([]models.LyricsStatsSource, error) {
+	defer rows.Close()
+
+	var sources []models.LyricsStatsSource
+	for rows.Next() {
+		var src models.LyricsStatsSource
+		var hash *string
+		var version *int
+		var data []byte
+		if err := rows.Scan(&src.ID, &src.Song, &src.Group, &src.Text, &hash, &version, &data); err != nil {
+			logrus.WithError(err).Error("Failed to scan lyrics stats row")
+			return nil, err
+		}
+		if hash != nil {
+			tally := models.LyricsTally{Hash: *hash, Version: *version}
+			if err := json.Unmarshal(data, &tally); err != nil {
+				logrus.WithError(err).Warn("Failed to decode cached lyrics tally, ignoring")
+			} else {
+				src.Tally = &tally
+			}
+		}
+		sources = append(sources, src)
+	}
+
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Error occurred while iterating over rows")
+		return nil, err
+	}
+	return sources, nil
+}
+
+// Сохранение подсчёта текста песни в кэш
+func (s *StatsRepo) SaveLyricsTally(ctx context.Context, songID int, tally models.LyricsTally) error {
+	data, err := json.Marshal(tally)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(ctx, `
+		INSERT INTO lyrics_stats (song_id, text_hash, version, tally)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (song_id) DO UPDATE
+		SET text_hash = EXCLUDED.text_hash, version = EXCLUDED.version, tally = EXCLUDED.tally, computed_at = NOW()
+	`, songID, tally.Hash, tally.Version, data)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to save lyrics tally")
+	}
+	return err
+}
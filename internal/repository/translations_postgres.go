@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/reqctx"
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+type TranslationRepo struct {
+	db *pgxpool.Pool
+}
+
+// Создаёт новый экземпляр репозитория переводов текстов песен
+func NewTranslationRepo(db *pgxpool.Pool) *TranslationRepo {
+	return &TranslationRepo{
+		db: db,
+	}
+}
+
+// Получение списка переводов песни (без текста)
+func (t *TranslationRepo) Translations(ctx context.Context, songID int) ([]models.Translation, error) {
+	logrus.WithField("songId", songID).Debug("Fetching song translations")
+
+	var exists bool
+	err := t.db.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM songs WHERE id = $1)
+	`, songID).Scan(&exists)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to check song existence")
+		return nil, err
+	}
+	if !exists {
+		return nil, models.ErrNotFound
+	}
+
+	rows, err := t.db.Query(ctx, `
+		SELECT language, author, updated_at, source_hash
+		FROM song_translations
+		WHERE song_id = $1
+		ORDER BY language
+	`, songID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to query song translations")
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := []models.Translation{}
+	for rows.Next() {
+		var tr models.Translation
+		if err := rows.Scan(&tr.Language, &tr.Author, &tr.UpdatedAt, &tr.SourceHash); err != nil {
+			logrus.WithError(err).Error("Failed to scan translation row")
+			return nil, err
+		}
+		translations = append(translations, tr)
+	}
+
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Error occurred while iterating over rows")
+		return nil, err
+	}
+	return translations, nil
+}
+
+// Получение перевода песни на язык
+func (t *TranslationRepo) Translation(ctx context.Context, songID int, language string) (models.Translation, error) {
+	logrus.WithFields(logrus.Fields{"songId": songID, "language": language}).Debug("Fetching song translation")
+
+	tr := models.Translation{Language: language}
+	err := t.db.QueryRow(ctx, `
+		SELECT text, author, updated_at, source_hash
+		FROM song_translations
+		WHERE song_id = $1 AND language = $2
+	`, songID, language).Scan(&tr.Text, &tr.Author, &tr.UpdatedAt, &tr.SourceHash)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch song translation")
+		return models.Translation{}, notFound(err)
+	}
+	return tr, nil
+}
+
+// Добавление или замена перевода песни
+func (t *TranslationRepo) SaveTranslation(ctx context.Context, songID int, tr models.Translation) (models.Translation, error) {
+	logrus.WithFields(logrus.Fields{"songId": songID, "language": tr.Language}).Debug("Saving song translation")
+
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction")
+		return models.Translation{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := songForUpdate(ctx, tx, songID); err != nil {
+		return models.Translation{}, err
+	}
+	previous, err := lockedTranslation(ctx, tx, songID, tr.Language)
+	if err != nil {
+		return models.Translation{}, err
+	}
+
+	tr.Author = reqctx.User(ctx)
+	err = tx.QueryRow(ctx, `
+		INSERT INTO song_translations (song_id, language, text, source_hash, author)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (song_id, language) DO UPDATE
+		SET text = EXCLUDED.text, source_hash = EXCLUDED.source_hash,
+			author = EXCLUDED.author, updated_at = NOW()
+		RETURNING updated_at
+	`, songID, tr.Language, tr.Text, tr.SourceHash, tr.Author).Scan(&tr.UpdatedAt)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to save song translation")
+		return models.Translation{}, err
+	}
+
+	action, before := ActionCreate, interface{}(nil)
+	if previous != nil {
+		action, before = ActionUpdate, previous
+	}
+	err = insertAudit(ctx, tx, action, EntityTranslation, songID, before, tr)
+	if err != nil {
+		return models.Translation{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction")
+		return models.Translation{}, err
+	}
+	return tr, nil
+}
+
+// Удаление перевода песни
+func (t *TranslationRepo) DeleteTranslation(ctx context.Context, songID int, language string) error {
+	logrus.WithFields(logrus.Fields{"songId": songID, "language": language}).Debug("Deleting song translation")
+
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction")
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	previous, err := lockedTranslation(ctx, tx, songID, language)
+	if err != nil {
+		return err
+	}
+	if previous == nil {
+		return models.ErrNotFound
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM song_translations
+		WHERE song_id = $1 AND language = $2
+	`, songID, language)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to delete song translation")
+		return err
+	}
+
+	err = insertAudit(ctx, tx, ActionDelete, EntityTranslation, songID, previous, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction")
+		return err
+	}
+	return nil
+}
+
+// Текущий перевод с блокировкой строки до конца транзакции; nil, если перевода нет
+func lockedTranslation(ctx context.Context, tx pgx.Tx, songID int, language string) (*models.Translation, error) {
+	tr := models.Translation{Language: language}
+	err := tx.QueryRow(ctx, `
+		SELECT text, author, updated_at
+		FROM song_translations
+		WHERE song_id = $1 AND language = $2
+		FOR UPDATE
+	`, songID, language).Scan(&tr.Text, &tr.Author, &tr.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logrus.WithError(err).Error("Failed to lock song translation")
+		return nil, err
+	}
+	return &tr, nil
+}
@@ -0,0 +1,82 @@
+// Пакет reqctx хранит метаданные запроса (автор изменений, роль, ID запроса) в context.Context
+package reqctx
+
+import "context"
+
+type ctxKey int
+
+const (
+	userKey ctxKey = iota
+	roleKey
+	requestIDKey
+)
+
+// Имя автора по умолчанию, если клиент не представился
+const Anonymous = "anonymous"
+
+// Роли пользователей
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+)
+
+// Разрешения
+const (
+	// Создание песни без запроса данных из внешних источников
+	PermCreateManual = "songs:create-manual"
+)
+
+// Разрешения ролей. Администратору разрешено всё
+var rolePermissions = map[string][]string{
+	RoleEditor: {PermCreateManual},
+}
+
+// Возвращает контекст с именем пользователя, выполняющего запрос
+func WithUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userKey, user)
+}
+
+// Возвращает имя пользователя из контекста
+func User(ctx context.Context) string {
+	user, _ := ctx.Value(userKey).(string)
+	if user == "" {
+		return Anonymous
+	}
+	return user
+}
+
+// Возвращает контекст с ролью пользователя
+func WithRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, roleKey, role)
+}
+
+// Возвращает роль пользователя из контекста
+func Role(ctx context.Context) string {
+	role, _ := ctx.Value(roleKey).(string)
+	return role
+}
+
+// Возвращает контекст с идентификатором запроса
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// Возвращает идентификатор запроса из контекста
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// Проверка, есть ли у пользователя из контекста указанное разрешение
+func Can(ctx context.Context, permission string) bool {
+	role := Role(ctx)
+	if role == RoleAdmin {
+		return true
+	}
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/repository"
+	"context"
+)
+
+type AuditService struct {
+	repo *repository.Repo
+}
+
+// Создаёт новый экземпляр сервиса журнала аудита
+func NewAuditService(repo *repository.Repo) *AuditService {
+	return &AuditService{
+		repo: repo,
+	}
+}
+
+// Получение записей журнала аудита с фильтрацией и пагинацией
+func (s *AuditService) Audit(ctx context.Context, filter models.AuditFilter, page, pageSize int) ([]models.AuditEntry, error) {
+	return s.repo.Audit.Audit(ctx, filter, page, pageSize)
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"Anastasia/songs/internal/config"
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/reqctx"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Параметры пакетного добавления песен
+type BatchOptions struct {
+	CreateOptions
+	// Всё или ничего: при любой ошибке ни одна песня не сохраняется
+	Atomic bool
+}
+
+// Настройки пакетного добавления
+type batchConfig struct {
+	// Количество песен в одной транзакции
+	chunkSize int
+	// Количество транзакций, выполняемых параллельно
+	concurrency int
+}
+
+func batchConfigFromEnv() batchConfig {
+	return batchConfig{
+		chunkSize:   max(config.Int("BATCH_CHUNK_SIZE", 100), 1),
+		concurrency: max(config.Int("BATCH_CONCURRENCY", 4), 1),
+	}
+}
+
+// Пакетное добавление песен. Каждая песня проверяется так же, как при одиночном создании,
+// дубликаты (в БД и внутри пакета) не добавляются. Песни сохраняются порциями,
+// каждая порция — в своей транзакции; в режиме Atomic весь пакет сохраняется одной транзакцией
+func (s *SongService) CreateSongs(ctx context.Context, songs []models.Songs, opts BatchOptions) (models.BatchResult, error) {
+	if opts.SkipEnrichment && !reqctx.Can(ctx, reqctx.PermCreateManual) {
+		return models.BatchResult{}, models.ErrForbidden
+	}
+
+	result := models.BatchResult{
+		Atomic: opts.Atomic,
+		Items:  make([]models.BatchItemResult, len(songs)),
+	}
+
+	// Проверка и поиск повторов внутри пакета
+	var valid []int
+	seen := map[string]int{}
+	for i, song := range songs {
+		result.Items[i].Index = i
+
+		song, err := s.prepareSong(ctx, song, opts.CreateOptions)
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			result.Items[i].Status = models.BatchInvalid
+			result.Items[i].Error = err.Error()
+			result.Items[i].Fields = validationErr.Fields
+			continue
+		}
+		if err != nil {
+			return models.BatchResult{}, err
+		}
+		songs[i] = song
+
+		key := normalizeName(song.Group) + "\x00" + normalizeName(song.Song)
+		if first, ok := seen[key]; ok {
+			result.Items[i].Status = models.BatchDuplicate
+			result.Items[i].DuplicateOf = &first
+			result.Items[i].Error = fmt.Sprintf("duplicates item %d", first)
+			continue
+		}
+		seen[key] = i
+		valid = append(valid, i)
+	}
+
+	if opts.Atomic {
+		s.createAtomic(ctx, songs, valid, opts, &result)
+	} else {
+		s.createChunked(ctx, songs, valid, opts, &result)
+	}
+
+	for _, item := range result.Items {
+		switch item.Status {
+		case models.BatchCreated:
+			result.Created++
+		case models.BatchDuplicate:
+			result.Duplicates++
+		case models.BatchInvalid:
+			result.Invalid++
+		case models.BatchFailed:
+			result.Failed++
+		}
+	}
+	result.Committed = result.Created > 0
+
+	logrus.WithFields(logrus.Fields{
+		"total":      len(songs),
+		"created":    result.Created,
+		"duplicates": result.Duplicates,
+		"invalid":    result.Invalid,
+		"failed":     result.Failed,
+	}).Info("Songs batch processed")
+	return result, nil
+}
+
+func (s *SongService) createAtomic(ctx context.Context, songs []models.Songs, valid []int, opts BatchOptions, result *models.BatchResult) {
+	if len(valid) < len(songs) {
+		for _, i := range valid {
+			result.Items[i].Status = models.BatchRolledBack
+		}
+		return
+	}
+
+	items, err := s.repo.Songs.CreateSongs(ctx, songs, s.duplicateCheck(opts.CreateOptions), true)
+	s.applyChunk(valid, items, err, result)
+}
+
+func (s *SongService) createChunked(ctx context.Context, songs []models.Songs, valid []int, opts BatchOptions, result *models.BatchResult) {
+	sem := make(chan struct{}, s.batch.concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for start := 0; start < len(valid); start += s.batch.chunkSize {
+		indexes := valid[start:min(start+s.batch.chunkSize, len(valid))]
+		chunk := make([]models.Songs, len(indexes))
+		for j, i := range indexes {
+			chunk[j] = songs[i]
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			items, err := s.repo.Songs.CreateSongs(ctx, chunk, s.duplicateCheck(opts.CreateOptions), false)
+
+			mu.Lock()
+			defer mu.Unlock()
+			s.applyChunk(indexes, items, err, result)
+		}()
+	}
+	wg.Wait()
+}
+
+// Перенос результатов порции в общий результат пакета
+func (s *SongService) applyChunk(indexes []int, items []models.BatchItemResult, err error, result *models.BatchResult) {
+	for j, i := range indexes {
+		if err != nil {
+			logrus.WithError(err).Error("Failed to save songs batch chunk")
+			result.Items[i] = models.BatchItemResult{Index: i, Status: models.BatchFailed, Error: err.Error()}
+			continue
+		}
+		items[j].Index = i
+		result.Items[i] = items[j]
+	}
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"Anastasia/songs/internal/models"
+	"context"
+	"testing"
+)
+
+func TestCreateSongsDuplicateCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       BatchOptions
+		want       models.DuplicateCheck
+		created    int
+		duplicates int
+	}{
+		{"chunked", BatchOptions{}, models.DuplicateCheck{MinSimilarity: 0.8}, 2, 1},
+		{"atomic", BatchOptions{Atomic: true}, models.DuplicateCheck{MinSimilarity: 0.8}, 0, 1},
+		{"duplicates allowed", BatchOptions{CreateOptions: CreateOptions{AllowDuplicate: true}}, models.DuplicateCheck{Skip: true, MinSimilarity: 0.8}, 3, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newTestSongService(&FakeEnricher{})
+			s.duplicateSimilarity = 0.8
+			s.batch = batchConfig{chunkSize: 2, concurrency: 2}
+			repo.existing = "Hysteria"
+			songs := []models.Songs{
+				{Group: "Muse", Song: "Uprising"},
+				{Group: "Muse", Song: "Hysteria"},
+				{Group: "Muse", Song: "Madness"},
+			}
+
+			result, err := s.CreateSongs(context.Background(), songs, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Пакет проверяется на дубликаты так же, как одиночное добавление
+			if len(repo.checks) == 0 {
+				t.Fatal("repository was not called")
+			}
+			for _, check := range repo.checks {
+				if check != tt.want {
+					t.Errorf("duplicate check = %+v, want %+v", check, tt.want)
+				}
+			}
+			if result.Created != tt.created || result.Duplicates != tt.duplicates {
+				t.Errorf("result = %+v, want %d created and %d duplicates", result, tt.created, tt.duplicates)
+			}
+			if tt.duplicates > 0 {
+				item := result.Items[1]
+				if item.Index != 1 || item.Status != models.BatchDuplicate || item.ExistingID != 7 || item.Similarity != 0.8 {
+					t.Errorf("duplicate item = %+v", item)
+				}
+			}
+		})
+	}
+}
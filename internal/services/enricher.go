@@ -0,0 +1,136 @@
+package services
+
+import (
+	"Anastasia/songs/internal/enrichment"
+	"Anastasia/songs/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Источник дополнительных данных о песне (дата выхода, текст, ссылка)
+type Enricher interface {
+	Enrich(ctx context.Context, song models.Songs) (models.Enrichment, error)
+}
+
+// Отдельный внешний источник данных. Возвращает известные ему поля песни,
+// остальные поля остаются пустыми
+type Provider interface {
+	Name() string
+	Lookup(ctx context.Context, song models.Songs) (models.Songs, error)
+}
+
+// Реализация Provider поверх метода /info внешнего сервиса
+type InfoProvider struct {
+	name   string
+	client *enrichment.Client
+}
+
+// Создаёт источник данных на основе клиента внешнего сервиса
+func NewInfoProvider(name string, client *enrichment.Client) *InfoProvider {
+	return &InfoProvider{
+		name:   name,
+		client: client,
+	}
+}
+
+func (p *InfoProvider) Name() string {
+	return p.name
+}
+
+// Запрос /info?group=&song=
+func (p *InfoProvider) Lookup(ctx context.Context, song models.Songs) (models.Songs, error) {
+	body, err := p.client.Lookup(ctx, song.Group, song.Song)
+	if err != nil {
+		logrus.WithError(err).WithField("provider", p.name).Error("Failed to get data from external API")
+		return models.Songs{}, err
+	}
+
+	logrus.WithField("responseBody", string(body)).Info("Response body from external API")
+
+	var detail models.Songs
+	if err := json.Unmarshal(body, &detail); err != nil {
+		logrus.WithError(err).Error("Failed to unmarshal song data")
+		return models.Songs{}, err
+	}
+
+	return detail, nil
+}
+
+// Цепочка источников данных. Источники опрашиваются по порядку, значения полей
+// объединяются согласно политике слияния
+type ChainEnricher struct {
+	providers []Provider
+	policy    MergePolicy
+}
+
+// Создаёт цепочку источников данных
+func NewChainEnricher(policy MergePolicy, providers ...Provider) *ChainEnricher {
+	return &ChainEnricher{
+		providers: providers,
+		policy:    policy,
+	}
+}
+
+// Получение данных песни из всех источников. Ошибка возвращается, только если
+// не ответил ни один источник
+func (c *ChainEnricher) Enrich(ctx context.Context, song models.Songs) (models.Enrichment, error) {
+	result := models.Enrichment{Song: song, Sources: map[string]string{}, Warnings: []string{}}
+
+	var errs []error
+	answered := false
+	for _, p := range c.providers {
+		if !c.needsMore(song, result.Sources) {
+			break
+		}
+
+		detail, err := p.Lookup(ctx, song)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+			result.Warnings = append(result.Warnings, fmt.Sprintf("provider %s is unavailable: %v", p.Name(), err))
+			continue
+		}
+		answered = true
+
+		for _, field := range songFields {
+			if _, ok := result.Sources[field]; ok {
+				continue
+			}
+			value := *fieldPtr(&detail, field)
+			current := *fieldPtr(&song, field)
+			if value == "" {
+				continue
+			}
+			if !c.policy.accepts(field, current) {
+				if value != current {
+					result.Warnings = append(result.Warnings,
+						fmt.Sprintf("provider %s suggests %s %q, kept %q", p.Name(), field, value, current))
+				}
+				continue
+			}
+			*fieldPtr(&result.Song, field) = value
+			result.Sources[field] = p.Name()
+		}
+	}
+
+	if !answered && len(errs) > 0 {
+		return models.Enrichment{}, errors.Join(errs...)
+	}
+	return result, nil
+}
+
+// Есть ли поля, которые ещё может заполнить следующий источник
+func (c *ChainEnricher) needsMore(song models.Songs, sources map[string]string) bool {
+	for _, field := range songFields {
+		if _, ok := sources[field]; ok {
+			continue
+		}
+		if c.policy.accepts(field, *fieldPtr(&song, field)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/repository"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type (
+	refreshKey       struct{}
+	readOnlyCacheKey struct{}
+)
+
+// Возвращает контекст, в котором источники запрашиваются в обход кэша
+func withRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, refreshKey{}, true)
+}
+
+func refreshRequested(ctx context.Context) bool {
+	refresh, _ := ctx.Value(refreshKey{}).(bool)
+	return refresh
+}
+
+// Возвращает контекст, в котором кэш только читается (для предпросмотра без записи в БД)
+func withReadOnlyCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readOnlyCacheKey{}, true)
+}
+
+func readOnlyCache(ctx context.Context) bool {
+	readOnly, _ := ctx.Value(readOnlyCacheKey{}).(bool)
+	return readOnly
+}
+
+// Источник данных с кэшированием ответов в БД. Ключ кэша — нормализованные названия группы и песни
+type CachedProvider struct {
+	Provider
+	repo *repository.Repo
+	ttl  time.Duration
+}
+
+// Оборачивает источник кэшем с временем жизни ttl. При ttl <= 0 кэш не используется
+func NewCachedProvider(provider Provider, repo *repository.Repo, ttl time.Duration) Provider {
+	if ttl <= 0 {
+		return provider
+	}
+	return &CachedProvider{
+		Provider: provider,
+		repo:     repo,
+		ttl:      ttl,
+	}
+}
+
+func (c *CachedProvider) Lookup(ctx context.Context, song models.Songs) (models.Songs, error) {
+	groupKey, songKey := normalizeName(song.Group), normalizeName(song.Song)
+	log := logrus.WithFields(logrus.Fields{
+		"provider": c.Name(),
+		"group":    groupKey,
+		"song":     songKey,
+	})
+
+	if !refreshRequested(ctx) {
+		detail, err := c.repo.Enrichment.CachedResponse(ctx, c.Name(), groupKey, songKey)
+		if err == nil {
+			log.Debug("Enrichment cache hit")
+			return detail, nil
+		}
+		if !errors.Is(err, models.ErrNotFound) {
+			log.WithError(err).Warn("Failed to read enrichment cache")
+		}
+	}
+
+	detail, err := c.Provider.Lookup(ctx, song)
+	if err != nil {
+		return models.Songs{}, err
+	}
+
+	if readOnlyCache(ctx) {
+		return detail, nil
+	}
+
+	// Ошибка записи в кэш не должна мешать получению данных
+	err = c.repo.Enrichment.SaveCachedResponse(ctx, c.Name(), groupKey, songKey, detail, c.ttl)
+	if err != nil {
+		log.WithError(err).Warn("Failed to write enrichment cache")
+	}
+	return detail, nil
+}
+
+// Нормализация названия: нижний регистр, без лишних пробелов
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"Anastasia/songs/internal/enrichment"
+	"Anastasia/songs/internal/mockinfo"
+	"Anastasia/songs/internal/models"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testProvider(t *testing.T, name string, opts mockinfo.Options) *InfoProvider {
+	server := mockinfo.NewTestServer(opts)
+	t.Cleanup(server.Close)
+
+	cfg := enrichment.DefaultConfig()
+	cfg.Name = t.Name() + "/" + name
+	cfg.URL = server.URL + "/info"
+	cfg.MaxRetries = 0
+	cfg.Timeout = 100 * time.Millisecond
+	return NewInfoProvider(name, enrichment.New(cfg))
+}
+
+func TestInfoProviderLookup(t *testing.T) {
+	p := testProvider(t, "info", mockinfo.Options{})
+
+	detail, err := p.Lookup(context.Background(), models.Songs{Group: "Русская народная", Song: "Калинка"})
+	if err != nil {
+		t.Fatalf("Lookup() error: %v", err)
+	}
+	if detail.ReleaseDate != "1860" || !strings.Contains(detail.Text, "Калинка, калинка") {
+		t.Errorf("detail = %+v", detail)
+	}
+}
+
+func TestChainEnricher(t *testing.T) {
+	song := models.Songs{Group: "Русская народная", Song: "Калинка"}
+	tests := []struct {
+		name        string
+		providers   map[string]mockinfo.Options
+		order       []string
+		wantErr     bool
+		wantSource  string
+		wantWarning bool
+	}{
+		{
+			name:       "first provider answers",
+			providers:  map[string]mockinfo.Options{"a": {}, "b": {}},
+			order:      []string{"a", "b"},
+			wantSource: "a",
+		},
+		{
+			name:        "falls back to the next provider",
+			providers:   map[string]mockinfo.Options{"down": {ErrorRate: 1}, "b": {}},
+			order:       []string{"down", "b"},
+			wantSource:  "b",
+			wantWarning: true,
+		},
+		{
+			name:        "slow provider times out",
+			providers:   map[string]mockinfo.Options{"slow": {Latency: time.Second}, "b": {}},
+			order:       []string{"slow", "b"},
+			wantSource:  "b",
+			wantWarning: true,
+		},
+		{
+			name:      "all providers fail",
+			providers: map[string]mockinfo.Options{"a": {ErrorRate: 1}, "b": {FailFirst: 1}},
+			order:     []string{"a", "b"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var providers []Provider
+			for _, name := range tt.order {
+				providers = append(providers, testProvider(t, name, tt.providers[name]))
+			}
+			c := NewChainEnricher(DefaultMergePolicy(), providers...)
+
+			result, err := c.Enrich(context.Background(), song)
+			if tt.wantErr {
+				if err == nil || !errors.Is(err, enrichment.ErrUpstream) {
+					t.Fatalf("err = %v, want upstream error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Sources["text"] != tt.wantSource || result.Song.ReleaseDate != "1860" {
+				t.Errorf("result = %+v, want text from %s", result, tt.wantSource)
+			}
+			if (len(result.Warnings) > 0) != tt.wantWarning {
+				t.Errorf("warnings = %v", result.Warnings)
+			}
+		})
+	}
+}
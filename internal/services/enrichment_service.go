@@ -0,0 +1,33 @@
+package services
+
+import (
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/repository"
+	"context"
+)
+
+type EnrichmentService struct {
+	repo *repository.Repo
+}
+
+// Создаёт новый экземпляр сервиса повторного получения данных
+func NewEnrichmentService(repo *repository.Repo) *EnrichmentService {
+	return &EnrichmentService{
+		repo: repo,
+	}
+}
+
+// Запуск повторного получения данных для песен, подходящих под фильтры.
+// Результаты по каждой песне доступны через EnrichmentRun
+func (s *EnrichmentService) StartEnrichmentRun(ctx context.Context, req models.EnrichmentRunRequest) (models.EnrichmentRun, error) {
+	refresh := true
+	if req.Refresh != nil {
+		refresh = *req.Refresh
+	}
+	return s.repo.Enrichment.CreateEnrichmentRun(ctx, req.Filters, refresh)
+}
+
+// Получение запуска с результатами по каждой песне
+func (s *EnrichmentService) EnrichmentRun(ctx context.Context, id int) (models.EnrichmentRun, error) {
+	return s.repo.Enrichment.EnrichmentRun(ctx, id)
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"Anastasia/songs/internal/config"
+	"Anastasia/songs/internal/enrichment"
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/repository"
+	"Anastasia/songs/internal/reqctx"
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Имя, под которым фоновый обработчик записывает изменения в ревизии и журнал аудита
+const enrichmentWorkerUser = "enrichment-worker"
+
+// Настройки фонового получения данных
+type WorkerConfig struct {
+	// Количество параллельных обработчиков
+	Workers int
+	// Пауза между опросами пустой очереди
+	PollInterval time.Duration
+	// Время, на которое захваченная задача скрывается от других обработчиков
+	Lease time.Duration
+	// Максимальное количество попыток, после которого песня получает статус failed
+	MaxAttempts int
+	// Начальная и максимальная задержка перед повторной попыткой
+	RetryBackoff    time.Duration
+	RetryBackoffMax time.Duration
+}
+
+// Чтение настроек фонового получения данных из переменных окружения
+func WorkerConfigFromEnv() WorkerConfig {
+	return WorkerConfig{
+		Workers:         config.Int("ENRICHMENT_WORKERS", 2),
+		PollInterval:    config.Duration("ENRICHMENT_POLL_INTERVAL", time.Second),
+		Lease:           config.Duration("ENRICHMENT_LEASE", 5*time.Minute),
+		MaxAttempts:     config.Int("ENRICHMENT_MAX_ATTEMPTS", 5),
+		RetryBackoff:    config.Duration("ENRICHMENT_RETRY_BACKOFF", 30*time.Second),
+		RetryBackoffMax: config.Duration("ENRICHMENT_RETRY_BACKOFF_MAX", 30*time.Minute),
+	}
+}
+
+// Фоновый обработчик очереди получения данных песен из внешнего сервиса
+type EnrichmentWorker struct {
+	repo     *repository.Repo
+	enricher Enricher
+	cfg      WorkerConfig
+}
+
+// Создаёт новый фоновый обработчик
+func NewEnrichmentWorker(repo *repository.Repo, enricher Enricher, cfg WorkerConfig) *EnrichmentWorker {
+	return &EnrichmentWorker{
+		repo:     repo,
+		enricher: enricher,
+		cfg:      cfg,
+	}
+}
+
+// Запуск обработчиков. Возвращает управление после отмены ctx
+func (w *EnrichmentWorker) Run(ctx context.Context) {
+	logrus.WithField("workers", w.cfg.Workers).Info("Enrichment worker is running...")
+
+	var wg sync.WaitGroup
+	for i := 0; i < w.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (w *EnrichmentWorker) loop(ctx context.Context) {
+	for ctx.Err() == nil {
+		processed, err := w.processNext(ctx)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to process enrichment job")
+		}
+		if processed && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(w.cfg.PollInterval):
+		}
+	}
+}
+
+// Обработка одной задачи. Возвращает false, если очередь пуста
+func (w *EnrichmentWorker) processNext(ctx context.Context) (bool, error) {
+	job, song, err := w.repo.Enrichment.ClaimEnrichmentJob(ctx, w.cfg.Lease)
+	if errors.Is(err, models.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	ctx = reqctx.WithUser(ctx, enrichmentWorkerUser)
+	ctx = reqctx.WithRequestID(ctx, "enrichment-job-"+strconv.Itoa(job.ID))
+
+	log := logrus.WithFields(logrus.Fields{
+		"jobId":   job.ID,
+		"songId":  job.SongID,
+		"attempt": job.Attempts,
+	})
+	log.Info("Enriching song")
+
+	input := song
+	if job.Refresh {
+		ctx = withRefresh(ctx)
+		input, err = w.refreshableSong(ctx, song)
+		if err != nil {
+			return true, err
+		}
+	}
+
+	result, err := w.enricher.Enrich(ctx, input)
+	if err != nil {
+		if ctx.Err() != nil {
+			// Задача вернётся в очередь по истечении lease
+			return true, nil
+		}
+		if !enrichment.Retryable(err) || job.Attempts >= w.cfg.MaxAttempts {
+			log.WithError(err).Warn("Enrichment failed")
+			err = w.repo.Enrichment.FailEnrichmentJob(ctx, job.ID, job.SongID, err.Error())
+			if errors.Is(err, models.ErrNotFound) {
+				log.Info("Song was deleted during enrichment")
+				return true, nil
+			}
+			return true, err
+		}
+		runAt := time.Now().Add(w.backoff(job.Attempts))
+		log.WithError(err).WithField("runAt", runAt).Warn("Enrichment attempt failed, will retry")
+		return true, w.repo.Enrichment.RetryEnrichmentJob(ctx, job.ID, runAt, err.Error())
+	}
+
+	// Сохраняются только поля, изменённые внешним сервисом: пользователь мог успеть
+	// отредактировать песню, пока шёл запрос
+	err = w.repo.Enrichment.CompleteEnrichmentJob(ctx, job.ID, enrichedFields(song, result.Song), result.Sources)
+	if errors.Is(err, models.ErrNotFound) {
+		log.Info("Song was deleted during enrichment")
+		return true, nil
+	}
+	if err != nil {
+		return true, err
+	}
+
+	log.Info("Song enriched successfully")
+	return true, nil
+}
+
+// Песня, в которой очищены поля, ранее полученные из внешних источников, чтобы источники
+// могли обновить их даже при правиле fill. Поля, заданные пользователем, не трогаются
+func (w *EnrichmentWorker) refreshableSong(ctx context.Context, song models.Songs) (models.Songs, error) {
+	sources, err := w.repo.Provenance.Provenance(ctx, song.ID)
+	if err != nil {
+		return models.Songs{}, err
+	}
+
+	for _, source := range sources {
+		if source.Source == models.SourceUser || source.Source == models.SourceRevert {
+			continue
+		}
+		if field := fieldPtrOrNil(&song, source.Field); field != nil {
+			*field = ""
+		}
+	}
+	return song, nil
+}
+
+func (w *EnrichmentWorker) backoff(attempt int) time.Duration {
+	d := w.cfg.RetryBackoff << (attempt - 1)
+	if d <= 0 || d > w.cfg.RetryBackoffMax {
+		d = w.cfg.RetryBackoffMax
+	}
+	return d
+}
+
+// Поля after, отличающиеся от before. Остальные поля остаются пустыми
+func enrichedFields(before, after models.Songs) models.Songs {
+	patch := models.Songs{ID: before.ID}
+	if after.Song != before.Song {
+		patch.Song = after.Song
+	}
+	if after.Group != before.Group {
+		patch.Group = after.Group
+	}
+	if after.ReleaseDate != before.ReleaseDate {
+		patch.ReleaseDate = after.ReleaseDate
+	}
+	if after.Text != before.Text {
+		patch.Text = after.Text
+	}
+	if after.Link != before.Link {
+		patch.Link = after.Link
+	}
+	return patch
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"Anastasia/songs/internal/models"
+	"context"
+	"sync"
+)
+
+// Имя источника, под которым FakeEnricher записывает заполненные поля
+const fakeSource = "fake"
+
+// Подменная реализация Enricher для тестов: заполняет песню данными из Detail
+// или возвращает Err и запоминает все вызовы
+type FakeEnricher struct {
+	Detail models.Songs
+	Err    error
+
+	mu    sync.Mutex
+	calls []models.Songs
+}
+
+func (f *FakeEnricher) Enrich(ctx context.Context, song models.Songs) (models.Enrichment, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, song)
+	f.mu.Unlock()
+
+	if f.Err != nil {
+		return models.Enrichment{}, f.Err
+	}
+
+	result := models.Enrichment{Song: song, Sources: map[string]string{}, Warnings: []string{}}
+	for _, field := range []string{"releaseDate", "text", "link"} {
+		if value := *fieldPtr(&f.Detail, field); value != "" {
+			*fieldPtr(&result.Song, field) = value
+			result.Sources[field] = fakeSource
+		}
+	}
+	return result, nil
+}
+
+// Песни, переданные в Enrich, в порядке вызовов
+func (f *FakeEnricher) Calls() []models.Songs {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]models.Songs(nil), f.calls...)
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"Anastasia/songs/internal/lyrics"
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/repository"
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+type LyricsService struct {
+	repo *repository.Repo
+}
+
+// Создаёт новый экземпляр сервиса текстов песен
+func NewLyricsService(repo *repository.Repo) *LyricsService {
+	return &LyricsService{
+		repo: repo,
+	}
+}
+
+// Получение структурированного текста песни. Если сохранённого разбора нет или он выполнен
+// старой версией, текст разбирается заново и результат сохраняется
+func (s *LyricsService) Lyrics(ctx context.Context, songID int) (models.Lyrics, error) {
+	_, parsed, err := songLyrics(ctx, s.repo, songID)
+	return parsed, err
+}
+
+// Текст песни и его разбор (см. LyricsService.Lyrics)
+func songLyrics(ctx context.Context, repo *repository.Repo, songID int) (string, models.Lyrics, error) {
+	text, parsed, err := repo.Lyrics.Lyrics(ctx, songID)
+	if err != nil {
+		return "", models.Lyrics{}, err
+	}
+	if parsed.Version == lyrics.Version {
+		return text, parsed, nil
+	}
+
+	parsed = lyrics.Parse(text)
+	if err := repo.Lyrics.SaveLyrics(ctx, songID, text, parsed); err != nil {
+		// Разбор будет выполнен заново при следующем обращении
+		logrus.WithError(err).WithField("id", songID).Warn("Failed to store parsed lyrics")
+	}
+	return text, parsed, nil
+}
+
+// Получение фрагмента текста песни с номерами строк
+func (s *LyricsService) LyricsExcerpt(ctx context.Context, songID int, sel models.LyricsSelection) (models.LyricsExcerpt, error) {
+	parsed, err := s.Lyrics(ctx, songID)
+	if err != nil {
+		return models.LyricsExcerpt{}, err
+	}
+	return lyrics.Select(parsed, sel)
+}
+
+// Получение синхронизированного текста песни
+func (s *LyricsService) SyncedLyrics(ctx context.Context, songID int) (models.SyncedLyrics, error) {
+	lrc, err := s.repo.Lyrics.SyncedLyrics(ctx, songID)
+	if err != nil {
+		return models.SyncedLyrics{}, err
+	}
+	return lyrics.ParseLRC(lrc)
+}
+
+// Сохранение синхронизированного текста в формате LRC. Текст проверяется при загрузке,
+// обычный текст песни заменяется полученным из него
+func (s *LyricsService) SaveSyncedLyrics(ctx context.Context, songID int, lrc string) (models.SyncedLyrics, error) {
+	synced, err := lyrics.ParseLRC(lrc)
+	if err != nil {
+		return models.SyncedLyrics{}, err
+	}
+
+	text := lyrics.PlainText(synced)
+	if err := validateText(text); err != nil {
+		return models.SyncedLyrics{}, err
+	}
+
+	err = s.repo.Lyrics.SaveSyncedLyrics(ctx, songID, lrc, text)
+	if err != nil {
+		return models.SyncedLyrics{}, err
+	}
+	return synced, nil
+}
+
+// Удаление синхронизированного текста песни
+func (s *LyricsService) DeleteSyncedLyrics(ctx context.Context, songID int) error {
+	return s.repo.Lyrics.DeleteSyncedLyrics(ctx, songID)
+}
+
+// Получение текста с аккордами, транспонированного и пересчитанного под каподастр по opts
+func (s *LyricsService) Chords(ctx context.Context, songID int, opts models.ChordOptions) (models.ChordSheet, error) {
+	chordpro, err := s.repo.Lyrics.Chords(ctx, songID)
+	if err != nil {
+		return models.ChordSheet{}, err
+	}
+	sheet, err := lyrics.ParseChordPro(chordpro)
+	if err != nil {
+		return models.ChordSheet{}, err
+	}
+	return lyrics.Arrange(sheet, opts)
+}
+
+// Сохранение текста с аккордами в формате ChordPro. Текст проверяется при загрузке,
+// обычный текст песни заменяется полученным из него
+func (s *LyricsService) SaveChords(ctx context.Context, songID int, chordpro string) (models.ChordSheet, error) {
+	sheet, err := lyrics.ParseChordPro(chordpro)
+	if err != nil {
+		return models.ChordSheet{}, err
+	}
+
+	text := lyrics.ChordsText(sheet)
+	if err := validateText(text); err != nil {
+		return models.ChordSheet{}, err
+	}
+
+	err = s.repo.Lyrics.SaveChords(ctx, songID, chordpro, text)
+	if err != nil {
+		return models.ChordSheet{}, err
+	}
+	return sheet, nil
+}
+
+// Удаление текста с аккордами песни
+func (s *LyricsService) DeleteChords(ctx context.Context, songID int) error {
+	return s.repo.Lyrics.DeleteChords(ctx, songID)
+}
+
+// Разбор рифм и слогов текста песни
+func (s *LyricsService) Analysis(ctx context.Context, songID int) (models.LyricsAnalysis, error) {
+	parsed, err := s.Lyrics(ctx, songID)
+	if err != nil {
+		return models.LyricsAnalysis{}, err
+	}
+	return lyrics.Analyze(parsed), nil
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"Anastasia/songs/internal/models"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Правило слияния значения поля песни со значением из внешнего источника
+type MergeRule string
+
+const (
+	// Значение пользователя не меняется
+	RuleUserWins MergeRule = "user"
+	// Непустое значение источника заменяет значение пользователя
+	RuleProviderWins MergeRule = "provider"
+	// Значение источника используется, только если пользователь поле не заполнил
+	RuleFillIfEmpty MergeRule = "fill"
+)
+
+// Поля песни, доступные для слияния, в формате JSON
+var songFields = []string{"group", "song", "releaseDate", "text", "link"}
+
+// Правила слияния по полям песни
+type MergePolicy map[string]MergeRule
+
+// Политика по умолчанию: написание группы и песни остаётся пользовательским,
+// остальные поля заполняются, если пусты
+func DefaultMergePolicy() MergePolicy {
+	return MergePolicy{
+		"group":       RuleUserWins,
+		"song":        RuleUserWins,
+		"releaseDate": RuleFillIfEmpty,
+		"text":        RuleFillIfEmpty,
+		"link":        RuleFillIfEmpty,
+	}
+}
+
+// Разбор политики в формате "поле=правило,поле=правило". Неуказанные поля берутся из DefaultMergePolicy
+func ParseMergePolicy(s string) (MergePolicy, error) {
+	policy := DefaultMergePolicy()
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		field, rule, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid merge rule %q", item)
+		}
+		if _, known := policy[field]; !known {
+			return nil, fmt.Errorf("unknown song field %q", field)
+		}
+		switch r := MergeRule(rule); r {
+		case RuleUserWins, RuleProviderWins, RuleFillIfEmpty:
+			policy[field] = r
+		default:
+			return nil, fmt.Errorf("unknown merge rule %q for field %q", rule, field)
+		}
+	}
+	return policy, nil
+}
+
+// Чтение политики из ENRICHMENT_POLICY. При ошибке используется политика по умолчанию
+func MergePolicyFromEnv() MergePolicy {
+	policy, err := ParseMergePolicy(os.Getenv("ENRICHMENT_POLICY"))
+	if err != nil {
+		logrus.WithError(err).Warn("Invalid enrichment policy, using default")
+		return DefaultMergePolicy()
+	}
+	return policy
+}
+
+// Можно ли заменить текущее значение поля значением из источника
+func (p MergePolicy) accepts(field, current string) bool {
+	switch p[field] {
+	case RuleProviderWins:
+		return true
+	case RuleFillIfEmpty:
+		return current == ""
+	default:
+		return false
+	}
+}
+
+// Указатель на поле песни по его имени в JSON
+func fieldPtr(song *models.Songs, field string) *string {
+	if ptr := fieldPtrOrNil(song, field); ptr != nil {
+		return ptr
+	}
+	panic("unknown song field " + field)
+}
+
+// Указатель на поле песни по его имени в JSON или nil для неизвестного поля
+func fieldPtrOrNil(song *models.Songs, field string) *string {
+	switch field {
+	case "group":
+		return &song.Group
+	case "song":
+		return &song.Song
+	case "releaseDate":
+		return &song.ReleaseDate
+	case "text":
+		return &song.Text
+	case "link":
+		return &song.Link
+	}
+	return nil
+}
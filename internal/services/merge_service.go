@@ -0,0 +1,34 @@
+package services
+
+import (
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/repository"
+	"context"
+)
+
+type MergeService struct {
+	repo *repository.Repo
+}
+
+// Создаёт новый экземпляр сервиса объединения песен
+func NewMergeService(repo *repository.Repo) *MergeService {
+	return &MergeService{
+		repo: repo,
+	}
+}
+
+// Объединение песни-дубликата с основной песней songID (см. repository.MergeRepo.MergeSong)
+func (s *MergeService) MergeSong(ctx context.Context, songID, duplicateID int) (models.SongMerge, error) {
+	switch {
+	case duplicateID <= 0:
+		return models.SongMerge{}, &models.ValidationError{Fields: map[string]string{"duplicateId": "is required"}}
+	case duplicateID == songID:
+		return models.SongMerge{}, &models.ValidationError{Fields: map[string]string{"duplicateId": "must differ from the song id"}}
+	}
+	return s.repo.Merges.MergeSong(ctx, songID, duplicateID)
+}
+
+// ID основной песни, с которой объединена песня oldID, или models.ErrNotFound
+func (s *MergeService) Redirect(ctx context.Context, oldID int) (int, error) {
+	return s.repo.Merges.Redirect(ctx, oldID)
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"Anastasia/songs/internal/models"
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Импорт плейлиста: каждая запись сопоставляется с песней библиотеки по исполнителю и названию,
+// для несопоставленных записей создаются песни-заготовки, остальные данные которых запрашиваются
+// из внешних источников. Записи без исполнителя или названия остаются несопоставленными
+func (s *SongService) ImportPlaylist(ctx context.Context, entries []models.PlaylistEntry, opts CreateOptions) (models.PlaylistImport, error) {
+	songs := make([]models.Songs, len(entries))
+	for i, entry := range entries {
+		songs[i] = models.Songs{Group: entry.Group, Song: entry.Song, ReleaseDate: entry.ReleaseDate}
+	}
+
+	batch, err := s.CreateSongs(ctx, songs, BatchOptions{CreateOptions: opts})
+	if err != nil {
+		return models.PlaylistImport{}, err
+	}
+
+	result := models.PlaylistImport{Entries: entries}
+	for i, item := range batch.Items {
+		entry := &result.Entries[i]
+		switch {
+		case item.Status == models.BatchCreated:
+			entry.Status = models.PlaylistCreated
+			entry.ID = item.ID
+		case item.Status == models.BatchDuplicate && item.DuplicateOf != nil:
+			// Повтор записи плейлиста получает тот же результат, что и первая запись
+			first := result.Entries[*item.DuplicateOf]
+			entry.Status, entry.ID, entry.Error = first.Status, first.ID, first.Error
+			if entry.Status == models.PlaylistCreated {
+				entry.Status = models.PlaylistMatched
+			}
+		case item.Status == models.BatchDuplicate:
+			entry.Status = models.PlaylistMatched
+			entry.ID = item.ExistingID
+		case item.Status == models.BatchInvalid:
+			entry.Status = models.PlaylistUnresolved
+			entry.Error = item.Error
+		default:
+			entry.Status = models.PlaylistFailed
+			entry.Error = item.Error
+		}
+
+		switch entry.Status {
+		case models.PlaylistMatched:
+			result.Matched++
+		case models.PlaylistCreated:
+			result.Created++
+		case models.PlaylistUnresolved:
+			result.Unresolved++
+		default:
+			result.Failed++
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"matched":    result.Matched,
+		"created":    result.Created,
+		"unresolved": result.Unresolved,
+		"failed":     result.Failed,
+	}).Info("Playlist imported")
+	return result, nil
+}
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/repository"
+	"context"
+)
+
+type ProvenanceService struct {
+	repo *repository.Repo
+}
+
+// Создаёт новый экземпляр сервиса источников данных песен
+func NewProvenanceService(repo *repository.Repo) *ProvenanceService {
+	return &ProvenanceService{
+		repo: repo,
+	}
+}
+
+// Получение источников текущих значений полей песни
+func (s *ProvenanceService) Provenance(ctx context.Context, songID int) ([]models.FieldSource, error) {
+	return s.repo.Provenance.Provenance(ctx, songID)
+}
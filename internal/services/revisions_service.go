@@ -0,0 +1,185 @@
+package services
+
+import (
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/repository"
+	"context"
+	"strings"
+)
+
+type RevisionService struct {
+	repo *repository.Repo
+}
+
+// Создаёт новый экземпляр сервиса ревизий
+func NewRevisionService(repo *repository.Repo) *RevisionService {
+	return &RevisionService{
+		repo: repo,
+	}
+}
+
+// Получение истории изменений песни
+func (s *RevisionService) Revisions(ctx context.Context, songID int) ([]models.Revision, error) {
+	return s.repo.Revisions.Revisions(ctx, songID)
+}
+
+// Построчное сравнение текста песни в двух ревизиях
+func (s *RevisionService) LyricsDiff(ctx context.Context, songID, from, to int) (models.LyricsDiff, error) {
+	fromRev, err := s.repo.Revisions.Revision(ctx, songID, from)
+	if err != nil {
+		return models.LyricsDiff{}, err
+	}
+	toRev, err := s.repo.Revisions.Revision(ctx, songID, to)
+	if err != nil {
+		return models.LyricsDiff{}, err
+	}
+
+	return models.LyricsDiff{
+		SongID: songID,
+		From:   from,
+		To:     to,
+		Lines:  diffLines(splitLines(fromRev.Snapshot.Text), splitLines(toRev.Snapshot.Text)),
+	}, nil
+}
+
+// Откат песни к состоянию указанной ревизии
+func (s *RevisionService) RevertSong(ctx context.Context, songID, revision int) (models.Revision, error) {
+	return s.repo.Revisions.RevertSong(ctx, songID, revision)
+}
+
+func splitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// Построчный дифф на основе наибольшей общей подпоследовательности. Подпоследовательность ищется
+// алгоритмом Хиршберга: память линейна по длине текстов, общие начало и конец отбрасываются сразу
+func diffLines(a, b []string) []models.DiffLine {
+	// Строки заменяются номерами, чтобы сравнивать числа, а не строки
+	ids := map[string]int{}
+	intern := func(lines []string) []int {
+		result := make([]int, len(lines))
+		for i, line := range lines {
+			id, ok := ids[line]
+			if !ok {
+				id = len(ids)
+				ids[line] = id
+			}
+			result[i] = id
+		}
+		return result
+	}
+	d := differ{a: a, b: b, x: intern(a), y: intern(b), lines: []models.DiffLine{}}
+	d.diff(0, len(a), 0, len(b))
+	return d.lines
+}
+
+type differ struct {
+	a, b  []string
+	x, y  []int
+	lines []models.DiffLine
+}
+
+func (d *differ) equal(i, j int) {
+	d.lines = append(d.lines, models.DiffLine{Op: "equal", Text: d.a[i], OldLine: i + 1, NewLine: j + 1})
+}
+
+func (d *differ) delete(i int) {
+	d.lines = append(d.lines, models.DiffLine{Op: "delete", Text: d.a[i], OldLine: i + 1})
+}
+
+func (d *differ) insert(j int) {
+	d.lines = append(d.lines, models.DiffLine{Op: "insert", Text: d.b[j], NewLine: j + 1})
+}
+
+// Дифф строк a[i0:i1] и b[j0:j1]
+func (d *differ) diff(i0, i1, j0, j1 int) {
+	for i0 < i1 && j0 < j1 && d.x[i0] == d.y[j0] {
+		d.equal(i0, j0)
+		i0++
+		j0++
+	}
+	suffix := 0
+	for i0 < i1-suffix && j0 < j1-suffix && d.x[i1-1-suffix] == d.y[j1-1-suffix] {
+		suffix++
+	}
+	i1, j1 = i1-suffix, j1-suffix
+
+	switch {
+	case i0 == i1:
+		for j := j0; j < j1; j++ {
+			d.insert(j)
+		}
+	case j0 == j1:
+		for i := i0; i < i1; i++ {
+			d.delete(i)
+		}
+	case i1-i0 == 1:
+		match := -1
+		for j := j0; j < j1 && match < 0; j++ {
+			if d.y[j] == d.x[i0] {
+				match = j
+			}
+		}
+		if match < 0 {
+			d.delete(i0)
+			match = j0 - 1
+		}
+		for j := j0; j < j1; j++ {
+			if j == match {
+				d.equal(i0, j)
+			} else {
+				d.insert(j)
+			}
+		}
+	default:
+		// Середина a делит b так, чтобы сумма НОП левой и правой половин была наибольшей
+		mid := (i0 + i1) / 2
+		forward := lcsLengths(d.x[i0:mid], d.y[j0:j1], false)
+		backward := lcsLengths(d.x[mid:i1], d.y[j0:j1], true)
+		split, best := 0, -1
+		for k := 0; k <= j1-j0; k++ {
+			if n := forward[k] + backward[j1-j0-k]; n > best {
+				split, best = k, n
+			}
+		}
+		d.diff(i0, mid, j0, j0+split)
+		d.diff(mid, i1, j0+split, j1)
+	}
+
+	for k := 0; k < suffix; k++ {
+		d.equal(i1+k, j1+k)
+	}
+}
+
+// Длины НОП x и каждого начала y (row[k] — для y[:k]); с reverse — для концов обеих
+// последовательностей (row[k] — для последних k элементов y)
+func lcsLengths(x, y []int, reverse bool) []int {
+	if reverse {
+		x, y = reversed(x), reversed(y)
+	}
+	prev := make([]int, len(y)+1)
+	cur := make([]int, len(y)+1)
+	for _, xi := range x {
+		for j, yj := range y {
+			if xi == yj {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+func reversed(s []int) []int {
+	r := make([]int, len(s))
+	for i, v := range s {
+		r[len(s)-1-i] = v
+	}
+	return r
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{"equal", "a\nb", "a\nb", "=a =b"},
+		{"insert", "a\nc", "a\nb\nc", "=a +b =c"},
+		{"delete", "a\nb\nc", "a\nc", "=a -b =c"},
+		{"replace", "a\nb\nc", "a\nx\nc", "=a -b +x =c"},
+		{"from empty", "", "a\nb", "+a +b"},
+		{"to empty", "a\nb", "", "-a -b"},
+		{"moved line", "a\nb\nc\nd", "b\nc\nd\na", "-a =b =c =d +a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ops []string
+			for _, line := range diffLines(splitLines(tt.a), splitLines(tt.b)) {
+				ops = append(ops, map[string]string{"equal": "=", "insert": "+", "delete": "-"}[line.Op]+line.Text)
+			}
+			if got := strings.Join(ops, " "); got != tt.want {
+				t.Errorf("diffLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// Дифф восстанавливает оба текста, а число общих строк равно длине НОП
+func TestDiffLinesRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	random := func() []string {
+		lines := make([]string, rnd.Intn(40))
+		for i := range lines {
+			lines[i] = strconv.Itoa(rnd.Intn(5))
+		}
+		return lines
+	}
+	for n := 0; n < 200; n++ {
+		a, b := random(), random()
+		var oldText, newText []string
+		equal := 0
+		for _, line := range diffLines(a, b) {
+			switch line.Op {
+			case "equal":
+				equal++
+				oldText, newText = append(oldText, line.Text), append(newText, line.Text)
+				if line.OldLine != len(oldText) || line.NewLine != len(newText) {
+					t.Fatalf("wrong line numbers %+v", line)
+				}
+			case "delete":
+				oldText = append(oldText, line.Text)
+			case "insert":
+				newText = append(newText, line.Text)
+			}
+		}
+		if strings.Join(oldText, "\n") != strings.Join(a, "\n") || strings.Join(newText, "\n") != strings.Join(b, "\n") {
+			t.Fatalf("diff of %q and %q does not restore the texts", a, b)
+		}
+		if want := naiveLCS(a, b); equal != want {
+			t.Fatalf("diff of %q and %q keeps %d lines, LCS is %d", a, b, equal, want)
+		}
+	}
+}
+
+func naiveLCS(a, b []string) int {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	return lcs[0][0]
+}
+
+// Тексты наибольшей допустимой длины сравниваются без квадратичной памяти
+func TestDiffLinesLarge(t *testing.T) {
+	a := make([]string, maxTextLength/2)
+	b := make([]string, maxTextLength/2)
+	for i := range a {
+		a[i] = "line " + strconv.Itoa(i)
+		b[i] = "line " + strconv.Itoa(i)
+		if i%100 == 0 {
+			b[i] = "changed " + strconv.Itoa(i)
+		}
+	}
+	start := time.Now()
+	lines := diffLines(a, b)
+	if len(lines) != 10100 {
+		t.Errorf("got %d diff lines, want 10100", len(lines))
+	}
+	t.Logf("diff of %d lines took %s", len(a), time.Since(start))
+}
@@ -3,23 +3,101 @@ package services
 import (
 	"Anastasia/songs/internal/models"
 	"Anastasia/songs/internal/repository"
+	"context"
 )
 
 type Songs interface {
-	Songs(filters models.Songs, page, pageSize int) ([]models.Songs, error)
-	SongByID(id int) (string, error)
-	DeleteSong(id int) error
-	UpdateSong(song models.Songs) error
-	CreateSong(song models.Songs) error
+	Songs(ctx context.Context, filters models.Songs, page, pageSize int) ([]models.Songs, error)
+	SongByID(ctx context.Context, id int) (string, error)
+	DeleteSong(ctx context.Context, id int) error
+	UpdateSong(ctx context.Context, song models.Songs) error
+	CreateSong(ctx context.Context, song models.Songs, opts CreateOptions) (models.Songs, error)
+	PreviewSong(ctx context.Context, song models.Songs, opts CreateOptions) (models.Enrichment, error)
+	CreateSongs(ctx context.Context, songs []models.Songs, opts BatchOptions) (models.BatchResult, error)
+	ExportSongs(ctx context.Context, filters models.Songs, fn func(models.Songs) error) error
+	ImportPlaylist(ctx context.Context, entries []models.PlaylistEntry, opts CreateOptions) (models.PlaylistImport, error)
+}
+
+type Revisions interface {
+	Revisions(ctx context.Context, songID int) ([]models.Revision, error)
+	LyricsDiff(ctx context.Context, songID, from, to int) (models.LyricsDiff, error)
+	RevertSong(ctx context.Context, songID, revision int) (models.Revision, error)
+}
+
+type Audit interface {
+	Audit(ctx context.Context, filter models.AuditFilter, page, pageSize int) ([]models.AuditEntry, error)
+}
+
+type Provenance interface {
+	Provenance(ctx context.Context, songID int) ([]models.FieldSource, error)
+}
+
+type Enrichment interface {
+	StartEnrichmentRun(ctx context.Context, req models.EnrichmentRunRequest) (models.EnrichmentRun, error)
+	EnrichmentRun(ctx context.Context, id int) (models.EnrichmentRun, error)
+}
+
+type Lyrics interface {
+	Lyrics(ctx context.Context, songID int) (models.Lyrics, error)
+	LyricsExcerpt(ctx context.Context, songID int, sel models.LyricsSelection) (models.LyricsExcerpt, error)
+	Analysis(ctx context.Context, songID int) (models.LyricsAnalysis, error)
+	SyncedLyrics(ctx context.Context, songID int) (models.SyncedLyrics, error)
+	SaveSyncedLyrics(ctx context.Context, songID int, lrc string) (models.SyncedLyrics, error)
+	DeleteSyncedLyrics(ctx context.Context, songID int) error
+	Chords(ctx context.Context, songID int, opts models.ChordOptions) (models.ChordSheet, error)
+	SaveChords(ctx context.Context, songID int, chordpro string) (models.ChordSheet, error)
+	DeleteChords(ctx context.Context, songID int) error
+}
+
+type Translations interface {
+	Translations(ctx context.Context, songID int) ([]models.Translation, error)
+	Translation(ctx context.Context, songID int, language string) (models.Translation, error)
+	SaveTranslation(ctx context.Context, songID int, language, text string) (models.Translation, error)
+	DeleteTranslation(ctx context.Context, songID int, language string) error
+	Bilingual(ctx context.Context, songID int, languages []string) (models.BilingualLyrics, error)
+}
+
+type Stats interface {
+	SongStats(ctx context.Context, songID, top int) (models.SongLyricsStats, error)
+	GroupStats(ctx context.Context, group string, top int) (models.GroupLyricsStats, error)
+}
+
+type Similarity interface {
+	SimilarLyrics(ctx context.Context, songID int, minSimilarity float64, limit int) ([]models.SimilarSong, error)
+	PossibleDuplicates(ctx context.Context, songID int) ([]models.SimilarSong, error)
+	BackfillSignatures(ctx context.Context) (int, error)
+}
+
+type Merges interface {
+	MergeSong(ctx context.Context, songID, duplicateID int) (models.SongMerge, error)
+	Redirect(ctx context.Context, oldID int) (int, error)
 }
 
 type Service struct {
 	Songs
+	Revisions
+	Audit
+	Provenance
+	Enrichment
+	Lyrics
+	Translations
+	Stats
+	Similarity
+	Merges
 }
 
-func NewService(repo *repository.Repo) *Service {
+func NewService(repo *repository.Repo, enricher Enricher) *Service {
 	service := &Service{
-		Songs: NewSongService(repo),
+		Songs:        NewSongService(repo, enricher),
+		Revisions:    NewRevisionService(repo),
+		Audit:        NewAuditService(repo),
+		Provenance:   NewProvenanceService(repo),
+		Enrichment:   NewEnrichmentService(repo),
+		Lyrics:       NewLyricsService(repo),
+		Translations: NewTranslationService(repo),
+		Stats:        NewStatsService(repo),
+		Similarity:   NewSimilarityService(repo),
+		Merges:       NewMergeService(repo),
 	}
 	return service
 }
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"Anastasia/songs/internal/config"
+	"Anastasia/songs/internal/lyrics"
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/repository"
+	"context"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Наибольшее число возможных дубликатов в ответе на добавление песни
+// и размер пакета при вычислении недостающих сигнатур
+const (
+	maxPossibleDuplicates = 5
+	backfillBatchSize     = 100
+)
+
+type SimilarityService struct {
+	repo *repository.Repo
+	// Наименьшее сходство текстов, при котором добавленная песня считается возможным дубликатом
+	duplicateSimilarity float64
+}
+
+// Создаёт новый экземпляр сервиса поиска похожих текстов песен
+func NewSimilarityService(repo *repository.Repo) *SimilarityService {
+	return &SimilarityService{
+		repo:                repo,
+		duplicateSimilarity: float64(config.Int("DUPLICATE_LYRICS_SIMILARITY", 80)) / 100,
+	}
+}
+
+// Песни с текстом, похожим на текст песни: сходство не меньше minSimilarity, по убыванию сходства.
+// Кандидаты отбираются по корзинам LSH, поэтому пары со сходством ниже 0.5 могут быть пропущены
+func (s *SimilarityService) SimilarLyrics(ctx context.Context, songID int, minSimilarity float64, limit int) ([]models.SimilarSong, error) {
+	signature, text, err := s.repo.Similarity.Signature(ctx, songID)
+	if err != nil {
+		return nil, err
+	}
+	if signature == nil {
+		// Песня добавлена до появления сигнатур: сигнатура вычисляется при первом обращении
+		signature = lyrics.Signature(text)
+		if err := s.repo.Similarity.SaveSignature(ctx, songID, text); err != nil {
+			logrus.WithError(err).WithField("id", songID).Warn("Failed to store lyrics signature")
+		}
+	}
+	if len(signature) == 0 {
+		return []models.SimilarSong{}, nil
+	}
+
+	candidates, err := s.repo.Similarity.Candidates(ctx, songID, lyrics.Buckets(signature))
+	if err != nil {
+		return nil, err
+	}
+
+	similar := []models.SimilarSong{}
+	for _, c := range candidates {
+		similarity := lyrics.Similarity(signature, c.Signature)
+		if similarity >= minSimilarity {
+			similar = append(similar, models.SimilarSong{ID: c.ID, Song: c.Song, Group: c.Group, Similarity: similarity})
+		}
+	}
+	sort.SliceStable(similar, func(i, j int) bool {
+		if similar[i].Similarity != similar[j].Similarity {
+			return similar[i].Similarity > similar[j].Similarity
+		}
+		return similar[i].ID < similar[j].ID
+	})
+	if limit > 0 && len(similar) > limit {
+		similar = similar[:limit]
+	}
+	return similar, nil
+}
+
+// Песни, текст которых почти совпадает с текстом песни (порог DUPLICATE_LYRICS_SIMILARITY)
+func (s *SimilarityService) PossibleDuplicates(ctx context.Context, songID int) ([]models.SimilarSong, error) {
+	return s.SimilarLyrics(ctx, songID, s.duplicateSimilarity, maxPossibleDuplicates)
+}
+
+// Вычисление сигнатур текстов песен, у которых их нет. Возвращает количество обработанных песен
+func (s *SimilarityService) BackfillSignatures(ctx context.Context) (int, error) {
+	done := 0
+	for {
+		songs, err := s.repo.Similarity.UnsignedLyrics(ctx, backfillBatchSize)
+		if err != nil {
+			return done, err
+		}
+		if len(songs) == 0 {
+			return done, nil
+		}
+		for _, song := range songs {
+			if err := s.repo.Similarity.SaveSignature(ctx, song.ID, song.Text); err != nil {
+				return done, err
+			}
+			done++
+		}
+	}
+}
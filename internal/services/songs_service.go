@@ -1,42 +1,137 @@
 package services
 
 import (
+	"Anastasia/songs/internal/config"
 	"Anastasia/songs/internal/models"
 	"Anastasia/songs/internal/repository"
+	"Anastasia/songs/internal/reqctx"
+	"context"
 )
 
 type SongService struct {
-	repo *repository.Repo
+	repo     *repository.Repo
+	enricher Enricher
+	batch    batchConfig
+	// Наименьшее сходство названий песен одной группы, при котором новая песня считается дубликатом;
+	// 0 — дубликатом считается только песня с теми же нормализованными названием и группой
+	duplicateSimilarity float64
 }
 
 // Создаёт новый экземпляр сервиса
-func NewSongService(repo *repository.Repo) *SongService {
+func NewSongService(repo *repository.Repo, enricher Enricher) *SongService {
 	return &SongService{
-		repo: repo,
+		repo:     repo,
+		enricher: enricher,
+		batch:    batchConfigFromEnv(),
+
+		duplicateSimilarity: float64(config.Int("DUPLICATE_SONG_SIMILARITY", 0)) / 100,
 	}
 }
 
 // Получение данных библиотеки с фильтрацией по всем полям и пагинацией
-func (s *SongService) Songs(filters models.Songs, page, pageSize int) ([]models.Songs, error) {
-	return s.repo.Songs.Songs(filters, page, pageSize)
+func (s *SongService) Songs(ctx context.Context, filters models.Songs, page, pageSize int) ([]models.Songs, error) {
+	return s.repo.Songs.Songs(ctx, filters, page, pageSize)
+}
+
+// Потоковая выгрузка всех песен, подходящих под фильтры списка
+func (s *SongService) ExportSongs(ctx context.Context, filters models.Songs, fn func(models.Songs) error) error {
+	return s.repo.Songs.ExportSongs(ctx, filters, fn)
 }
 
 // Получение текста песни с пагинацией по куплетам
-func (s *SongService) SongByID(id int) (string, error) {
-	return s.repo.Songs.SongByID(id)
+func (s *SongService) SongByID(ctx context.Context, id int) (string, error) {
+	return s.repo.Songs.SongByID(ctx, id)
 }
 
 // Удаление песни
-func (s *SongService) DeleteSong(id int) error {
-	return s.repo.Songs.DeleteSong(id)
+func (s *SongService) DeleteSong(ctx context.Context, id int) error {
+	return s.repo.Songs.DeleteSong(ctx, id)
 }
 
 // Изменение данных песни
-func (s *SongService) UpdateSong(song models.Songs) error {
-	return s.repo.Songs.UpdateSong(song)
+func (s *SongService) UpdateSong(ctx context.Context, song models.Songs) error {
+	if err := validateText(song.Text); err != nil {
+		return err
+	}
+	return s.repo.Songs.UpdateSong(ctx, song)
+}
+
+// Параметры создания песни
+type CreateOptions struct {
+	// Сохранить дату выхода, текст и ссылку из запроса, не обращаясь к внешним источникам.
+	// Требует разрешения reqctx.PermCreateManual
+	SkipEnrichment bool
+	// Добавить песню, даже если такая уже есть в библиотеке
+	AllowDuplicate bool
+}
+
+// Добавление новой песни. Данные из внешнего сервиса запрашиваются в фоне (см. EnrichmentWorker).
+// Если такая песня уже есть, возвращает *models.DuplicateError
+func (s *SongService) CreateSong(ctx context.Context, song models.Songs, opts CreateOptions) (models.Songs, error) {
+	song, err := s.prepareSong(ctx, song, opts)
+	if err != nil {
+		return models.Songs{}, err
+	}
+
+	id, err := s.repo.Songs.CreateSong(ctx, song, s.duplicateCheck(opts))
+	if err != nil {
+		return models.Songs{}, err
+	}
+	song.ID = id
+	return song, nil
+}
+
+// Проверка дубликатов для выбранного режима создания, общая для одиночного и пакетного добавления
+func (s *SongService) duplicateCheck(opts CreateOptions) models.DuplicateCheck {
+	return models.DuplicateCheck{
+		Skip:          opts.AllowDuplicate,
+		MinSimilarity: s.duplicateSimilarity,
+	}
+}
+
+// Проверка песни и прав на выбранный режим создания, выбор начального статуса
+func (s *SongService) prepareSong(ctx context.Context, song models.Songs, opts CreateOptions) (models.Songs, error) {
+	if opts.SkipEnrichment && !reqctx.Can(ctx, reqctx.PermCreateManual) {
+		return models.Songs{}, models.ErrForbidden
+	}
+	if err := ValidateSong(song); err != nil {
+		return models.Songs{}, err
+	}
+
+	song.EnrichmentStatus = models.EnrichmentPending
+	if opts.SkipEnrichment {
+		song.EnrichmentStatus = models.EnrichmentManual
+	}
+	return song, nil
 }
 
-// Добавление новой песни
-func (s *SongService) CreateSong(song models.Songs) error {
-	return s.repo.Songs.CreateSong(song)
+// Предпросмотр новой песни: проверка и получение данных так же, как при создании и последующей
+// фоновой обработке, но без записи в БД
+func (s *SongService) PreviewSong(ctx context.Context, song models.Songs, opts CreateOptions) (models.Enrichment, error) {
+	song, err := s.prepareSong(ctx, song, opts)
+	if err != nil {
+		return models.Enrichment{}, err
+	}
+	if opts.SkipEnrichment {
+		return models.Enrichment{Song: song, Sources: map[string]string{}, Warnings: []string{}}, nil
+	}
+
+	result, err := s.enricher.Enrich(withReadOnlyCache(ctx), song)
+	if err != nil {
+		// Создание песни в этом случае тоже пройдёт: данные будут запрошены позже
+		song.EnrichmentStatus = models.EnrichmentPending
+		return models.Enrichment{
+			Song:     song,
+			Sources:  map[string]string{},
+			Warnings: []string{"enrichment is unavailable, the song would stay pending: " + err.Error()},
+		}, nil
+	}
+
+	result.Song.EnrichmentStatus = models.EnrichmentEnriched
+	for _, field := range songFields {
+		if *fieldPtr(&result.Song, field) == "" {
+			result.Warnings = append(result.Warnings, field+" is empty")
+		}
+	}
+	return result, nil
 }
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"Anastasia/songs/internal/enrichment"
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/repository"
+	"Anastasia/songs/internal/reqctx"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Встраивается под другим именем: поле Songs совпало бы с методом Songs
+type songsRepo = repository.Songs
+
+// Подменный репозиторий песен: запоминает добавленные песни; остальные методы не используются
+type fakeSongs struct {
+	songsRepo
+	mu      sync.Mutex
+	created []models.Songs
+	checks  []models.DuplicateCheck
+	err     error
+	// Песни пакета с этим названием считаются уже существующими
+	existing string
+}
+
+func (f *fakeSongs) CreateSong(ctx context.Context, song models.Songs, check models.DuplicateCheck) (int, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	f.created = append(f.created, song)
+	f.checks = append(f.checks, check)
+	return len(f.created), nil
+}
+
+func (f *fakeSongs) CreateSongs(ctx context.Context, songs []models.Songs, check models.DuplicateCheck, atomic bool) ([]models.BatchItemResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.checks = append(f.checks, check)
+	items := make([]models.BatchItemResult, len(songs))
+	ok := true
+	for i, song := range songs {
+		if !check.Skip && song.Song == f.existing {
+			items[i] = models.BatchItemResult{Status: models.BatchDuplicate, ExistingID: 7, Similarity: check.MinSimilarity}
+			ok = false
+		}
+	}
+	for i, song := range songs {
+		switch {
+		case items[i].Status != "":
+		case atomic && !ok:
+			items[i] = models.BatchItemResult{Status: models.BatchRolledBack}
+		default:
+			f.created = append(f.created, song)
+			items[i] = models.BatchItemResult{Status: models.BatchCreated, ID: len(f.created)}
+		}
+	}
+	return items, nil
+}
+
+func newTestSongService(enricher Enricher) (*SongService, *fakeSongs) {
+	songs := &fakeSongs{}
+	return &SongService{repo: &repository.Repo{Songs: songs}, enricher: enricher}, songs
+}
+
+func TestCreateSong(t *testing.T) {
+	valid := models.Songs{Group: "Muse", Song: "Supermassive Black Hole", Text: "Ooh baby", Link: "https://example.com"}
+	tests := []struct {
+		name       string
+		song       models.Songs
+		role       string
+		opts       CreateOptions
+		wantStatus string
+		wantErr    error
+	}{
+		{"pending by default", valid, "", CreateOptions{}, models.EnrichmentPending, nil},
+		{"manual for editor", valid, reqctx.RoleEditor, CreateOptions{SkipEnrichment: true}, models.EnrichmentManual, nil},
+		{"manual for admin", valid, reqctx.RoleAdmin, CreateOptions{SkipEnrichment: true}, models.EnrichmentManual, nil},
+		{"manual forbidden without role", valid, "", CreateOptions{SkipEnrichment: true}, "", models.ErrForbidden},
+		{"missing group", models.Songs{Song: "Hysteria"}, "", CreateOptions{}, "", &models.ValidationError{}},
+		{"invalid release date", models.Songs{Group: "Muse", Song: "Hysteria", ReleaseDate: "yesterday"}, "", CreateOptions{}, "", &models.ValidationError{}},
+		{"invalid link", models.Songs{Group: "Muse", Song: "Hysteria", Link: "ftp://example.com"}, "", CreateOptions{}, "", &models.ValidationError{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enricher := &FakeEnricher{}
+			s, repo := newTestSongService(enricher)
+			ctx := reqctx.WithRole(context.Background(), tt.role)
+
+			song, err := s.CreateSong(ctx, tt.song, tt.opts)
+			var validationErr *models.ValidationError
+			switch {
+			case errors.As(tt.wantErr, &validationErr):
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("err = %v, want validation error", err)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != nil {
+				if len(repo.created) != 0 {
+					t.Errorf("song was saved despite the error")
+				}
+				return
+			}
+			if song.ID != 1 || song.EnrichmentStatus != tt.wantStatus {
+				t.Errorf("song = %+v, want id 1 and status %q", song, tt.wantStatus)
+			}
+			if len(repo.created) != 1 || repo.created[0].EnrichmentStatus != tt.wantStatus {
+				t.Errorf("saved songs = %+v, want one with status %q", repo.created, tt.wantStatus)
+			}
+			// Данные запрашиваются в фоне, а не при создании
+			if calls := enricher.Calls(); len(calls) != 0 {
+				t.Errorf("enricher called %d times during creation", len(calls))
+			}
+		})
+	}
+}
+
+func TestCreateSongDuplicate(t *testing.T) {
+	s, repo := newTestSongService(&FakeEnricher{})
+	s.duplicateSimilarity = 0.9
+	song := models.Songs{Group: "Muse", Song: "Hysteria"}
+
+	_, err := s.CreateSong(context.Background(), song, CreateOptions{AllowDuplicate: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := (models.DuplicateCheck{Skip: true, MinSimilarity: 0.9}); repo.checks[0] != want {
+		t.Errorf("duplicate check = %+v, want %+v", repo.checks[0], want)
+	}
+
+	repo.err = &models.DuplicateError{ExistingID: 7, Similarity: 1}
+	_, err = s.CreateSong(context.Background(), song, CreateOptions{})
+	var duplicateErr *models.DuplicateError
+	if !errors.As(err, &duplicateErr) || duplicateErr.ExistingID != 7 {
+		t.Errorf("err = %v, want duplicate of song 7", err)
+	}
+}
+
+func TestPreviewSong(t *testing.T) {
+	song := models.Songs{Group: "Muse", Song: "Hysteria"}
+	detail := models.Songs{ReleaseDate: "2003", Text: "It's bugging me", Link: "https://example.com/hysteria"}
+	tests := []struct {
+		name         string
+		enricherErr  error
+		opts         CreateOptions
+		wantStatus   string
+		wantText     string
+		wantCalls    int
+		wantWarnings int
+	}{
+		{"enriched", nil, CreateOptions{}, models.EnrichmentEnriched, detail.Text, 1, 0},
+		{"provider unavailable", enrichment.ErrCircuitOpen, CreateOptions{}, models.EnrichmentPending, "", 1, 1},
+		{"provider error", &enrichment.StatusError{Code: 502}, CreateOptions{}, models.EnrichmentPending, "", 1, 1},
+		{"manual", nil, CreateOptions{SkipEnrichment: true}, models.EnrichmentManual, "", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enricher := &FakeEnricher{Detail: detail, Err: tt.enricherErr}
+			s, repo := newTestSongService(enricher)
+			ctx := reqctx.WithRole(context.Background(), reqctx.RoleEditor)
+
+			preview, err := s.PreviewSong(ctx, song, tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if preview.Song.EnrichmentStatus != tt.wantStatus || preview.Song.Text != tt.wantText {
+				t.Errorf("preview song = %+v, want status %q and text %q", preview.Song, tt.wantStatus, tt.wantText)
+			}
+			if len(preview.Warnings) != tt.wantWarnings {
+				t.Errorf("warnings = %v, want %d", preview.Warnings, tt.wantWarnings)
+			}
+			if calls := enricher.Calls(); len(calls) != tt.wantCalls {
+				t.Errorf("enricher called %d times, want %d", len(calls), tt.wantCalls)
+			}
+			if len(repo.created) != 0 {
+				t.Errorf("preview saved the song")
+			}
+		})
+	}
+}
+
+// Подменная очередь получения данных: выдаёт одну задачу и запоминает её исход
+type fakeEnrichmentQueue struct {
+	repository.Enrichment
+	job     models.EnrichmentJob
+	song    models.Songs
+	claimed bool
+	outcome string
+	patch   models.Songs
+	sources map[string]string
+}
+
+func (f *fakeEnrichmentQueue) ClaimEnrichmentJob(ctx context.Context, lease time.Duration) (models.EnrichmentJob, models.Songs, error) {
+	if f.claimed {
+		return models.EnrichmentJob{}, models.Songs{}, models.ErrNotFound
+	}
+	f.claimed = true
+	return f.job, f.song, nil
+}
+
+func (f *fakeEnrichmentQueue) CompleteEnrichmentJob(ctx context.Context, jobID int, patch models.Songs, sources map[string]string) error {
+	f.outcome, f.patch, f.sources = "complete", patch, sources
+	return nil
+}
+
+func (f *fakeEnrichmentQueue) RetryEnrichmentJob(ctx context.Context, jobID int, runAt time.Time, reason string) error {
+	f.outcome = "retry"
+	return nil
+}
+
+func (f *fakeEnrichmentQueue) FailEnrichmentJob(ctx context.Context, jobID, songID int, reason string) error {
+	f.outcome = "fail"
+	return nil
+}
+
+func TestEnrichmentWorker(t *testing.T) {
+	detail := models.Songs{ReleaseDate: "2003", Text: "It's bugging me"}
+	tests := []struct {
+		name        string
+		enricherErr error
+		attempts    int
+		wantOutcome string
+	}{
+		{"enriched", nil, 1, "complete"},
+		{"server error is retried", &enrichment.StatusError{Code: 503}, 1, "retry"},
+		{"last attempt fails", &enrichment.StatusError{Code: 503}, 3, "fail"},
+		{"client error is not retried", &enrichment.StatusError{Code: 404}, 1, "fail"},
+		{"response too large is not retried", enrichment.ErrTooLarge, 1, "fail"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := &fakeEnrichmentQueue{
+				job:  models.EnrichmentJob{ID: 1, SongID: 5, Attempts: tt.attempts},
+				song: models.Songs{ID: 5, Group: "Muse", Song: "Hysteria", EnrichmentStatus: models.EnrichmentPending},
+			}
+			w := NewEnrichmentWorker(&repository.Repo{Enrichment: queue}, &FakeEnricher{Detail: detail, Err: tt.enricherErr},
+				WorkerConfig{MaxAttempts: 3, RetryBackoff: time.Second, RetryBackoffMax: time.Minute})
+
+			processed, err := w.processNext(context.Background())
+			if !processed || err != nil {
+				t.Fatalf("processNext() = %v, %v", processed, err)
+			}
+			if queue.outcome != tt.wantOutcome {
+				t.Errorf("outcome = %q, want %q", queue.outcome, tt.wantOutcome)
+			}
+			if tt.wantOutcome == "complete" {
+				want := models.Songs{ID: 5, ReleaseDate: detail.ReleaseDate, Text: detail.Text}
+				if queue.patch != want || queue.sources["text"] != fakeSource {
+					t.Errorf("patch = %+v, sources = %v", queue.patch, queue.sources)
+				}
+			}
+
+			processed, err = w.processNext(context.Background())
+			if processed || err != nil {
+				t.Errorf("empty queue: processNext() = %v, %v", processed, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"Anastasia/songs/internal/lyrics"
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/repository"
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+type StatsService struct {
+	repo *repository.Repo
+}
+
+// Создаёт новый экземпляр сервиса статистики текстов песен
+func NewStatsService(repo *repository.Repo) *StatsService {
+	return &StatsService{
+		repo: repo,
+	}
+}
+
+// Статистика текста песни; top — количество самых частых слов
+func (s *StatsService) SongStats(ctx context.Context, songID, top int) (models.SongLyricsStats, error) {
+	src, err := s.repo.Stats.SongStatsSource(ctx, songID)
+	if err != nil {
+		return models.SongLyricsStats{}, err
+	}
+	return models.SongLyricsStats{
+		ID:          src.ID,
+		Song:        src.Song,
+		Group:       src.Group,
+		LyricsStats: lyrics.Stats(s.tally(ctx, src), top),
+	}, nil
+}
+
+// Статистика текстов всех песен группы; top — количество самых частых слов
+func (s *StatsService) GroupStats(ctx context.Context, group string, top int) (models.GroupLyricsStats, error) {
+	sources, err := s.repo.Stats.GroupStatsSources(ctx, group)
+	if err != nil {
+		return models.GroupLyricsStats{}, err
+	}
+
+	tallies := make([]models.LyricsTally, len(sources))
+	for i, src := range sources {
+		tallies[i] = s.tally(ctx, src)
+	}
+	return models.GroupLyricsStats{
+		Group:       sources[0].Group,
+		Songs:       len(sources),
+		LyricsStats: lyrics.Stats(lyrics.Merge(tallies...), top),
+	}, nil
+}
+
+// Подсчёт по тексту песни: из кэша, если он выполнен для текущего текста той же версией,
+// иначе подсчёт выполняется заново и сохраняется
+func (s *StatsService) tally(ctx context.Context, src models.LyricsStatsSource) models.LyricsTally {
+	hash := lyrics.Hash(src.Text)
+	if src.Tally != nil && src.Tally.Hash == hash && src.Tally.Version == lyrics.StatsVersion {
+		return *src.Tally
+	}
+
+	tally := lyrics.Tally(lyrics.Parse(src.Text))
+	tally.Hash = hash
+	if err := s.repo.Stats.SaveLyricsTally(ctx, src.ID, tally); err != nil {
+		// Подсчёт будет выполнен заново при следующем обращении
+		logrus.WithError(err).WithField("id", src.ID).Warn("Failed to cache lyrics tally")
+	}
+	return tally
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"Anastasia/songs/internal/lyrics"
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/repository"
+	"context"
+	"errors"
+	"testing"
+)
+
+// Подменный репозиторий статистики: отдаёт заданные тексты и запоминает сохранённые подсчёты
+type fakeStats struct {
+	sources []models.LyricsStatsSource
+	saved   map[int]models.LyricsTally
+	err     error
+}
+
+func (f *fakeStats) SongStatsSource(ctx context.Context, songID int) (models.LyricsStatsSource, error) {
+	return f.sources[0], nil
+}
+
+func (f *fakeStats) GroupStatsSources(ctx context.Context, group string) ([]models.LyricsStatsSource, error) {
+	return f.sources, nil
+}
+
+func (f *fakeStats) SaveLyricsTally(ctx context.Context, songID int, tally models.LyricsTally) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.saved[songID] = tally
+	return nil
+}
+
+func TestSongStatsCache(t *testing.T) {
+	const text = "Sing along\nSing along\nOne time"
+	// Кэшированный подсчёт заведомо отличается от подсчёта текста, чтобы было видно, откуда взят результат
+	cached := func(hash string, version int) *models.LyricsTally {
+		return &models.LyricsTally{Hash: hash, Version: version, Words: map[string]int{"cached": 1}, Lines: 1}
+	}
+	tests := []struct {
+		name     string
+		tally    *models.LyricsTally
+		saveErr  error
+		fromDB   bool
+		resaved  bool
+		topWords int
+	}{
+		{"no cached tally", nil, nil, false, true, 4},
+		{"cached tally of the current text", cached(lyrics.Hash(text), lyrics.StatsVersion), nil, true, false, 1},
+		{"text changed", cached(lyrics.Hash("Other text"), lyrics.StatsVersion), nil, false, true, 4},
+		{"counting rules changed", cached(lyrics.Hash(text), lyrics.StatsVersion-1), nil, false, true, 4},
+		{"cache write fails", nil, errors.New("db is down"), false, false, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := &fakeStats{
+				sources: []models.LyricsStatsSource{{ID: 7, Song: "Song", Group: "Band", Text: text, Tally: tt.tally}},
+				saved:   map[int]models.LyricsTally{},
+				err:     tt.saveErr,
+			}
+			s := NewStatsService(&repository.Repo{Stats: stats})
+
+			got, err := s.SongStats(context.Background(), 7, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.ID != 7 || got.Song != "Song" || got.Group != "Band" {
+				t.Errorf("song = %d %q %q", got.ID, got.Song, got.Group)
+			}
+			if fromDB := got.Lines == 1; fromDB != tt.fromDB {
+				t.Errorf("stats = %+v, cached tally used: %v, want %v", got.LyricsStats, fromDB, tt.fromDB)
+			}
+			if len(got.TopWords) != tt.topWords {
+				t.Errorf("top words = %+v, want %d", got.TopWords, tt.topWords)
+			}
+
+			saved, ok := stats.saved[7]
+			if ok != tt.resaved {
+				t.Fatalf("tally saved: %v, want %v", ok, tt.resaved)
+			}
+			if ok && (saved.Hash != lyrics.Hash(text) || saved.Version != lyrics.StatsVersion || saved.Lines != 3) {
+				t.Errorf("saved tally = %+v", saved)
+			}
+		})
+	}
+}
+
+func TestGroupStats(t *testing.T) {
+	stats := &fakeStats{
+		sources: []models.LyricsStatsSource{
+			{ID: 1, Group: "Band", Text: "La la\nLa la"},
+			{ID: 2, Group: "Band", Text: "La la\nOne"},
+		},
+		saved: map[int]models.LyricsTally{},
+	}
+	s := NewStatsService(&repository.Repo{Stats: stats})
+
+	got, err := s.GroupStats(context.Background(), "band", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Повторы считаются внутри каждой песни: вторая песня не повторяет первую
+	if got.Group != "Band" || got.Songs != 2 || got.Lines != 4 || got.UniqueLines != 3 || got.Words != 7 {
+		t.Errorf("GroupStats = %+v", got)
+	}
+	if len(stats.saved) != 2 {
+		t.Errorf("saved tallies = %+v, want both songs", stats.saved)
+	}
+}
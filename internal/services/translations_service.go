@@ -0,0 +1,172 @@
+package services
+
+import (
+	"Anastasia/songs/internal/lyrics"
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/repository"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Код языка: основной подтег из 2–3 букв и необязательные подтеги региона, письменности и т.д.
+var languageRe = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+type TranslationService struct {
+	repo *repository.Repo
+}
+
+// Создаёт новый экземпляр сервиса переводов текстов песен
+func NewTranslationService(repo *repository.Repo) *TranslationService {
+	return &TranslationService{
+		repo: repo,
+	}
+}
+
+// Получение списка переводов песни
+func (s *TranslationService) Translations(ctx context.Context, songID int) ([]models.Translation, error) {
+	translations, err := s.repo.Translations.Translations(ctx, songID)
+	if err != nil {
+		return nil, err
+	}
+	if len(translations) == 0 {
+		return translations, nil
+	}
+
+	text, _, err := s.repo.Lyrics.Lyrics(ctx, songID)
+	if err != nil {
+		return nil, err
+	}
+	hash := lyrics.Hash(text)
+	for i := range translations {
+		translations[i].Outdated = translations[i].SourceHash != hash
+	}
+	return translations, nil
+}
+
+// Получение перевода песни на язык
+func (s *TranslationService) Translation(ctx context.Context, songID int, language string) (models.Translation, error) {
+	language, err := normalizeLanguage(language)
+	if err != nil {
+		return models.Translation{}, err
+	}
+
+	tr, err := s.repo.Translations.Translation(ctx, songID, language)
+	if err != nil {
+		return models.Translation{}, err
+	}
+	text, _, err := s.repo.Lyrics.Lyrics(ctx, songID)
+	if err != nil {
+		return models.Translation{}, err
+	}
+	tr.Outdated = tr.SourceHash != lyrics.Hash(text)
+	return tr, nil
+}
+
+// Добавление или замена перевода. Перевод должен повторять структуру оригинала:
+// те же части (метки частей можно перевести или оставить) и столько же строк в каждой;
+// длина перевода ограничена так же, как длина текста песни
+func (s *TranslationService) SaveTranslation(ctx context.Context, songID int, language, text string) (models.Translation, error) {
+	language, err := normalizeLanguage(language)
+	if err != nil {
+		return models.Translation{}, err
+	}
+
+	original, parsed, err := songLyrics(ctx, s.repo, songID)
+	if err != nil {
+		return models.Translation{}, err
+	}
+	if strings.TrimSpace(text) == "" {
+		return models.Translation{}, &models.ValidationError{Fields: map[string]string{"text": "is required"}}
+	}
+	if err := validateText(text); err != nil {
+		return models.Translation{}, err
+	}
+	if err := lyrics.Align(parsed, lyrics.Parse(text)); err != nil {
+		return models.Translation{}, err
+	}
+
+	return s.repo.Translations.SaveTranslation(ctx, songID, models.Translation{
+		Language:   language,
+		Text:       lyrics.Normalize(text),
+		SourceHash: lyrics.Hash(original),
+	})
+}
+
+// Удаление перевода песни
+func (s *TranslationService) DeleteTranslation(ctx context.Context, songID int, language string) error {
+	language, err := normalizeLanguage(language)
+	if err != nil {
+		return err
+	}
+	return s.repo.Translations.DeleteTranslation(ctx, songID, language)
+}
+
+// Двуязычный текст: строки оригинала и перевода на первый доступный из languages язык
+// (в порядке предпочтения). Язык en-us подходит к переводу en и наоборот
+func (s *TranslationService) Bilingual(ctx context.Context, songID int, languages []string) (models.BilingualLyrics, error) {
+	available, err := s.repo.Translations.Translations(ctx, songID)
+	if err != nil {
+		return models.BilingualLyrics{}, err
+	}
+
+	language := pickLanguage(languages, available)
+	if language == "" {
+		codes := make([]string, len(available))
+		for i, tr := range available {
+			codes[i] = tr.Language
+		}
+		return models.BilingualLyrics{}, fmt.Errorf("no translation for %s (available: %s): %w",
+			strings.Join(languages, ", "), strings.Join(codes, ", "), models.ErrNotFound)
+	}
+
+	tr, err := s.repo.Translations.Translation(ctx, songID, language)
+	if err != nil {
+		return models.BilingualLyrics{}, err
+	}
+	text, parsed, err := songLyrics(ctx, s.repo, songID)
+	if err != nil {
+		return models.BilingualLyrics{}, err
+	}
+
+	return models.BilingualLyrics{
+		Language: language,
+		Outdated: tr.SourceHash != lyrics.Hash(text),
+		Sections: lyrics.Interleave(parsed, lyrics.Parse(tr.Text)),
+	}, nil
+}
+
+// Выбор языка перевода: сначала точное совпадение, затем перевод на основной язык без региона,
+// затем на любой вариант основного языка
+func pickLanguage(wanted []string, available []models.Translation) string {
+	for _, w := range wanted {
+		w, err := normalizeLanguage(w)
+		if err != nil {
+			continue
+		}
+		primary, _, _ := strings.Cut(w, "-")
+		for _, language := range []string{w, primary} {
+			for _, tr := range available {
+				if tr.Language == language {
+					return tr.Language
+				}
+			}
+		}
+		for _, tr := range available {
+			if p, _, _ := strings.Cut(tr.Language, "-"); p == primary {
+				return tr.Language
+			}
+		}
+	}
+	return ""
+}
+
+// Приведение кода языка к нижнему регистру с дефисами ("en_US" → "en-us")
+func normalizeLanguage(language string) (string, error) {
+	language = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(language), "_", "-"))
+	if !languageRe.MatchString(language) {
+		return "", &models.ValidationError{Fields: map[string]string{"language": "must be a language code like en or en-GB"}}
+	}
+	return language, nil
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"Anastasia/songs/internal/lyrics"
+	"Anastasia/songs/internal/models"
+	"Anastasia/songs/internal/repository"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Встраиваются под другими именами: поля совпали бы с методами Lyrics и Translations
+type (
+	lyricsRepo       = repository.Lyrics
+	translationsRepo = repository.Translations
+)
+
+// Подменный репозиторий текстов: возвращает один и тот же текст для любой песни
+type fakeLyrics struct {
+	lyricsRepo
+	text string
+}
+
+func (f *fakeLyrics) Lyrics(ctx context.Context, songID int) (string, models.Lyrics, error) {
+	return f.text, lyrics.Parse(f.text), nil
+}
+
+// Подменный репозиторий переводов: запоминает сохранённые переводы
+type fakeTranslations struct {
+	translationsRepo
+	saved []models.Translation
+}
+
+func (f *fakeTranslations) SaveTranslation(ctx context.Context, songID int, tr models.Translation) (models.Translation, error) {
+	f.saved = append(f.saved, tr)
+	return tr, nil
+}
+
+func TestSaveTranslation(t *testing.T) {
+	const original = "[Verse]\nOne\nTwo\n\n[Chorus]\nLa"
+	tests := []struct {
+		name     string
+		language string
+		text     string
+		field    string
+		problem  string
+	}{
+		{"valid", "en_US", "[Куплет]\nРаз\nДва\n\n[Припев]\nЛя\n", "", ""},
+		{"invalid language", "english", "Раз\nДва\n\nЛя", "language", "language code"},
+		{"empty text", "ru", " \n", "text", "is required"},
+		{"text too long", "ru", strings.Repeat("я\n", maxTextLength/2) + "\n\nЛя", "text", textTooLong},
+		{"different structure", "ru", "Раз\n\nДва\n\nЛя", "text", "3 sections"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			translations := &fakeTranslations{}
+			s := NewTranslationService(&repository.Repo{Lyrics: &fakeLyrics{text: original}, Translations: translations})
+
+			tr, err := s.SaveTranslation(context.Background(), 1, tt.language, tt.text)
+			if tt.field == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if tr.Language != "en-us" || tr.SourceHash != lyrics.Hash(original) || len(translations.saved) != 1 {
+					t.Errorf("saved %+v", translations.saved)
+				}
+				return
+			}
+
+			var validationErr *models.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("error = %v, want *models.ValidationError", err)
+			}
+			if !strings.Contains(validationErr.Fields[tt.field], tt.problem) {
+				t.Errorf("fields = %v, want %q: %q", validationErr.Fields, tt.field, tt.problem)
+			}
+			if len(translations.saved) != 0 {
+				t.Errorf("invalid translation saved: %+v", translations.saved)
+			}
+		})
+	}
+}
+
+func TestNormalizeLanguage(t *testing.T) {
+	tests := []struct {
+		language string
+		want     string
+		ok       bool
+	}{
+		{"en", "en", true},
+		{" en_US ", "en-us", true},
+		{"zh-Hant-TW", "zh-hant-tw", true},
+		{"deu", "deu", true},
+		{"e", "", false},
+		{"english", "", false},
+		{"en-", "", false},
+		{"en-x", "", false},
+		{"ru/en", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			got, err := normalizeLanguage(tt.language)
+			if got != tt.want || (err == nil) != tt.ok {
+				t.Errorf("normalizeLanguage(%q) = %q, %v, want %q", tt.language, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPickLanguage(t *testing.T) {
+	available := []models.Translation{{Language: "de"}, {Language: "en-gb"}, {Language: "en"}, {Language: "pt-br"}}
+	tests := []struct {
+		name   string
+		wanted []string
+		want   string
+	}{
+		{"exact match", []string{"en-GB"}, "en-gb"},
+		{"exact match wins over region fallback", []string{"en"}, "en"},
+		{"region falls back to the primary language", []string{"en-US"}, "en"},
+		{"region falls back to another region", []string{"pt-PT"}, "pt-br"},
+		{"primary language matches a regional translation", []string{"pt"}, "pt-br"},
+		{"preference order", []string{"fr", "de", "en"}, "de"},
+		{"invalid codes are skipped", []string{"*", "de_AT"}, "de"},
+		{"nothing matches", []string{"fr", "ja"}, ""},
+		{"no preferences", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pickLanguage(tt.wanted, available); got != tt.want {
+				t.Errorf("pickLanguage(%q) = %q, want %q", tt.wanted, got, tt.want)
+			}
+		})
+	}
+}
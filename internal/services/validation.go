@@ -0,0 +1,79 @@
+package services
+
+import (
+	"Anastasia/songs/internal/models"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Максимальная длина строковых полей (VARCHAR(255) в БД)
+const maxFieldLength = 255
+
+// Максимальная длина текста песни: ограничивает время построчного сравнения ревизий
+const maxTextLength = 20000
+
+var textTooLong = fmt.Sprintf("must be at most %d characters", maxTextLength)
+
+// Допустимые форматы даты выхода песни
+var releaseDateLayouts = []string{"02.01.2006", "2006-01-02", "2006"}
+
+// Проверка данных новой песни. Возвращает *models.ValidationError со всеми найденными проблемами
+func ValidateSong(song models.Songs) error {
+	problems := map[string]string{}
+
+	if strings.TrimSpace(song.Group) == "" {
+		problems["group"] = "is required"
+	}
+	if strings.TrimSpace(song.Song) == "" {
+		problems["song"] = "is required"
+	}
+	for field, value := range map[string]string{
+		"group":       song.Group,
+		"song":        song.Song,
+		"releaseDate": song.ReleaseDate,
+		"link":        song.Link,
+	} {
+		if utf8.RuneCountInString(value) > maxFieldLength {
+			problems[field] = "is too long"
+		}
+	}
+	if utf8.RuneCountInString(song.Text) > maxTextLength {
+		problems["text"] = textTooLong
+	}
+	if song.ReleaseDate != "" && !validReleaseDate(song.ReleaseDate) {
+		problems["releaseDate"] = "must be in DD.MM.YYYY, YYYY-MM-DD or YYYY format"
+	}
+	if song.Link != "" && !validLink(song.Link) {
+		problems["link"] = "must be an absolute http(s) URL"
+	}
+
+	if len(problems) > 0 {
+		return &models.ValidationError{Fields: problems}
+	}
+	return nil
+}
+
+// Проверка длины текста песни, заданного при изменении песни или полученного из LRC и ChordPro
+func validateText(text string) error {
+	if utf8.RuneCountInString(text) > maxTextLength {
+		return &models.ValidationError{Fields: map[string]string{"text": textTooLong}}
+	}
+	return nil
+}
+
+func validReleaseDate(date string) bool {
+	for _, layout := range releaseDateLayouts {
+		if _, err := time.Parse(layout, date); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func validLink(link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,12 @@
+CREATE TABLE song_revisions (
+    id SERIAL PRIMARY KEY,
+    song_id INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    changes JSONB NOT NULL,
+    snapshot JSONB NOT NULL,
+    reverted_from INTEGER,
+    UNIQUE (song_id, revision),
+    FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS enrichment_jobs;
+DROP INDEX IF EXISTS idx_enrichment_status;
+ALTER TABLE songs DROP COLUMN IF EXISTS enrichment_status;
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- Существующие песни уже были дополнены данными при создании
+ALTER TABLE songs ADD COLUMN enrichment_status VARCHAR(16) NOT NULL DEFAULT 'enriched';
+
+CREATE INDEX idx_enrichment_status ON songs (enrichment_status);
+
+CREATE TABLE enrichment_jobs (
+    id SERIAL PRIMARY KEY,
+    song_id INTEGER NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    run_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_enrichment_jobs_due ON enrichment_jobs (run_at) WHERE status = 'pending';
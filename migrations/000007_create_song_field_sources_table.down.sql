@@ -0,0 +1 @@
+DROP TABLE IF EXISTS song_field_sources;
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE TABLE song_field_sources (
+    song_id INTEGER NOT NULL,
+    field VARCHAR(32) NOT NULL,
+    source VARCHAR(64) NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (song_id, field),
+    FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+);
@@ -0,0 +1,6 @@
+ALTER TABLE enrichment_jobs
+    DROP COLUMN IF EXISTS run_id,
+    DROP COLUMN IF EXISTS refresh,
+    DROP COLUMN IF EXISTS changed_fields;
+DROP TABLE IF EXISTS enrichment_runs;
+DROP TABLE IF EXISTS enrichment_cache;
\ No newline at end of file
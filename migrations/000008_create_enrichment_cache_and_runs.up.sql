@@ -0,0 +1,24 @@
+CREATE TABLE enrichment_cache (
+    provider VARCHAR(64) NOT NULL,
+    group_key VARCHAR(255) NOT NULL,
+    song_key VARCHAR(255) NOT NULL,
+    response JSONB NOT NULL,
+    fetched_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (provider, group_key, song_key)
+);
+
+CREATE TABLE enrichment_runs (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(255) NOT NULL,
+    filters JSONB NOT NULL,
+    refresh BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+ALTER TABLE enrichment_jobs
+    ADD COLUMN run_id INTEGER REFERENCES enrichment_runs(id) ON DELETE CASCADE,
+    ADD COLUMN refresh BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN changed_fields JSONB NOT NULL DEFAULT '[]';
+
+CREATE INDEX idx_enrichment_jobs_run ON enrichment_jobs (run_id);
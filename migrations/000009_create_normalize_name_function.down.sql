@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_song_normalized_name, idx_group_normalized_name;
+DROP FUNCTION IF EXISTS normalize_name;
\ No newline at end of file
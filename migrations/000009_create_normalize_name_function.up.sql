@@ -0,0 +1,7 @@
+-- Нормализация названий для поиска дубликатов: нижний регистр, без лишних пробелов
+CREATE FUNCTION normalize_name(name TEXT) RETURNS TEXT AS $$
+    SELECT regexp_replace(lower(trim(name)), '\s+', ' ', 'g');
+$$ LANGUAGE sql IMMUTABLE STRICT;
+
+CREATE INDEX idx_song_normalized_name ON songs (normalize_name(name));
+CREATE INDEX idx_group_normalized_name ON groups (normalize_name(name));
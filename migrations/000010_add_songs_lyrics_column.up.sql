@@ -0,0 +1,3 @@
+-- Структурированный текст песни (части и строки), вычисляется при записи из text.
+-- Для существующих песен заполняется при первом обращении
+ALTER TABLE songs ADD COLUMN lyrics JSONB;
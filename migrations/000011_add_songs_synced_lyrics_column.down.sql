@@ -0,0 +1 @@
+ALTER TABLE songs DROP COLUMN synced_lyrics;
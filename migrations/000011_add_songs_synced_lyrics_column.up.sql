@@ -0,0 +1,2 @@
+-- Синхронизированный по времени текст песни в формате LRC; text получается из него
+ALTER TABLE songs ADD COLUMN synced_lyrics TEXT;
@@ -0,0 +1,11 @@
+CREATE TABLE song_translations (
+    song_id INTEGER NOT NULL,
+    language VARCHAR(35) NOT NULL,
+    text TEXT NOT NULL,
+    -- Хэш текста оригинала, к которому выровнен перевод
+    source_hash VARCHAR(64) NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (song_id, language),
+    FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+-- Текст песни с аккордами в формате ChordPro; text получается из него
+ALTER TABLE songs ADD COLUMN chords TEXT;
@@ -0,0 +1,10 @@
+-- Кэш подсчёта слов и строк текста песни для статистики
+CREATE TABLE lyrics_stats (
+    song_id INTEGER PRIMARY KEY,
+    -- Хэш текста и версия подсчёта; при их несовпадении подсчёт выполняется заново
+    text_hash VARCHAR(64) NOT NULL,
+    version INTEGER NOT NULL,
+    tally JSONB NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+);
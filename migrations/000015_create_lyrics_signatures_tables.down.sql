@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS lyrics_lsh_buckets;
+DROP TABLE IF EXISTS lyrics_signatures;
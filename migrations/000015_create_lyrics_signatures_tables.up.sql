@@ -0,0 +1,16 @@
+-- Сигнатуры MinHash текстов песен для поиска похожих текстов
+CREATE TABLE lyrics_signatures (
+    song_id INTEGER PRIMARY KEY,
+    signature BYTEA NOT NULL,
+    FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+);
+
+-- Корзины LSH: песни с общей корзиной — кандидаты в похожие
+CREATE TABLE lyrics_lsh_buckets (
+    song_id INTEGER NOT NULL,
+    bucket BIGINT NOT NULL,
+    PRIMARY KEY (song_id, bucket),
+    FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_lyrics_lsh_buckets_bucket ON lyrics_lsh_buckets (bucket);
@@ -0,0 +1,10 @@
+-- Перенаправления с ID песен, объединённых с основной песней
+CREATE TABLE song_redirects (
+    old_id INTEGER PRIMARY KEY,
+    song_id INTEGER NOT NULL,
+    merged_by VARCHAR(255) NOT NULL,
+    merged_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_song_redirects_song ON song_redirects (song_id);
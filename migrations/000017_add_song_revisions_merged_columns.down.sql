@@ -0,0 +1,2 @@
+ALTER TABLE song_revisions DROP COLUMN merged_revision;
+ALTER TABLE song_revisions DROP COLUMN merged_from;
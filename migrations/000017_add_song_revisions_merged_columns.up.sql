@@ -0,0 +1,4 @@
+-- Ревизии песни-дубликата переносятся в историю основной песни при объединении:
+-- исходная песня и исходный номер ревизии
+ALTER TABLE song_revisions ADD COLUMN merged_from INTEGER;
+ALTER TABLE song_revisions ADD COLUMN merged_revision INTEGER;